@@ -0,0 +1,268 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// AlertsQueue is the name of the TaskQueue that evaluates regression alerts.
+const AlertsQueue = "regression-alerts"
+
+// EvaluateTarget is the handler path for evaluating regression alerts for a run.
+const EvaluateTarget = "/api/alerts/evaluate"
+
+// EvaluationKind is the Datastore kind of the entities recording which runs
+// were evaluated, keyed by run ID.
+const EvaluationKind = "AlertEvaluation"
+
+// alertEvaluation records when the subscriptions were evaluated against a run.
+type alertEvaluation struct {
+	Evaluated time.Time
+}
+
+// errAlreadyEvaluated is returned by reserveEvaluation when the run was
+// already evaluated.
+var errAlreadyEvaluated = errors.New("run was already evaluated")
+
+// ScheduleEvaluation schedules the evaluation of regression subscriptions for
+// a newly created run. Only master runs are evaluated; other runs are ignored.
+func ScheduleEvaluation(aeAPI shared.AppEngineAPI, run shared.TestRun) error {
+	if !run.LabelsSet().Contains(shared.MasterLabel) {
+		return nil
+	}
+	params := url.Values{}
+	params.Set("run_id", strconv.FormatInt(run.ID, 10))
+	_, err := aeAPI.ScheduleTask(AlertsQueue, "", EvaluateTarget, params)
+
+	return err
+}
+
+// evaluateHandler evaluates all regression subscriptions against the diff of
+// a master run with the previous master run of the same product. It is called
+// from the regression-alerts TaskQueue, and notifies at most once per run.
+func evaluateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+	// Evaluations send emails and webhook requests to the subscribers.
+	if r.Header.Get(shared.QueueNameHeader) != AlertsQueue {
+		http.Error(w, "Evaluations can only be requested from the "+AlertsQueue+" queue", http.StatusForbidden)
+
+		return
+	}
+	ctx := r.Context()
+	logger := shared.GetLogger(ctx)
+	runID, err := strconv.ParseInt(r.FormValue("run_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid run_id", http.StatusBadRequest)
+
+		return
+	}
+
+	store := shared.NewAppEngineDatastore(ctx, false)
+	var after shared.TestRun
+	if err := store.Get(store.NewIDKey("TestRun", runID), &after); err != nil {
+		if errors.Is(err, shared.ErrNoSuchEntity) {
+			http.Error(w, fmt.Sprintf("Run %v not found", runID), http.StatusNotFound)
+
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	after.ID = runID
+
+	before, err := loadPreviousMasterRun(store, after)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	} else if before == nil {
+		logger.Infof("No previous master run to compare run %v against", runID)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	regressions, err := loadRegressions(ctx, *before, after)
+	if err != nil {
+		logger.Errorf("Failed to diff runs %v and %v: %s", before.ID, after.ID, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	subs, err := listSubscriptions(store, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	diffURL := shared.NewDiffAPI(ctx).GetDiffURL(*before, after, nil).String()
+	alerts := matchAlerts(*before, after, regressions, diffURL, subs)
+	// Failures above are retried by the queue; once the run is reserved, the
+	// alerts aren't sent again, even if some of them fail.
+	if err := reserveEvaluation(store, runID, time.Now()); errors.Is(err, errAlreadyEvaluated) {
+		logger.Infof("Run %v was already evaluated", runID)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	sent := notifyAll(logger, NewNotifiers(shared.NewAppEngineAPI(ctx), store), alerts)
+
+	_, err = fmt.Fprintf(w, "Sent %d of %d alerts for run %v", sent, len(alerts), runID)
+	if err != nil {
+		logger.Warningf("Failed to write data in api/alerts/evaluate handler: %s", err.Error())
+	}
+}
+
+// reserveEvaluation records that the subscriptions are evaluated against the
+// run, returning errAlreadyEvaluated if they already were.
+func reserveEvaluation(store shared.Datastore, runID int64, now time.Time) error {
+	err := store.Insert(store.NewIDKey(EvaluationKind, runID), &alertEvaluation{Evaluated: now})
+	if errors.Is(err, shared.ErrEntityAlreadyExists) {
+		return errAlreadyEvaluated
+	}
+
+	return err
+}
+
+// loadPreviousMasterRun loads the latest master run of the same product and
+// channel as the given run, which started before it.
+func loadPreviousMasterRun(store shared.Datastore, run shared.TestRun) (*shared.TestRun, error) {
+	spec := shared.ProductSpec{} // nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	spec.BrowserName = run.BrowserName
+	spec.Labels = mapset.NewSetWith(run.Channel(), shared.MasterLabel)
+	limit := 2
+	to := run.TimeStart.Add(time.Second)
+	runsByProduct, err := store.TestRunQuery().LoadTestRuns(
+		shared.ProductSpecs{spec}, nil, nil, nil, &to, &limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, prev := range runsByProduct.AllRuns() {
+		if prev.ID != run.ID && !prev.TimeStart.After(run.TimeStart) {
+			return &prev, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// loadRegressions returns the tests which regressed between the given runs.
+func loadRegressions(ctx context.Context, before, after shared.TestRun) (mapset.Set, error) {
+	client := shared.NewAppEngineAPI(ctx).GetHTTPClientWithTimeout(time.Minute)
+	beforeSummary, err := shared.FetchRunResultsSummary(client, before)
+	if err != nil {
+		return nil, err
+	}
+	afterSummary, err := shared.FetchRunResultsSummary(client, after)
+	if err != nil {
+		return nil, err
+	}
+	filter := shared.DiffFilterParam{Added: true, Deleted: true, Changed: true} // nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	diff := shared.ResultsDiff(shared.GetResultsDiff(beforeSummary, afterSummary, filter, nil, nil))
+
+	return diff.Regressions(), nil
+}
+
+// matchAlerts returns an alert for each subscription which matches the after
+// run's product, and has at least its threshold of regressions under its paths.
+func matchAlerts(
+	before, after shared.TestRun,
+	regressions mapset.Set,
+	diffURL string,
+	subs []RegressionSubscription,
+) []Alert {
+	var alerts []Alert
+	for _, sub := range subs {
+		spec, err := shared.ParseProductSpec(sub.Product)
+		if err != nil || !spec.Matches(after) {
+			continue
+		}
+		var matching []string
+		for _, test := range shared.ToStringSlice(regressions) {
+			if matchesAnyPath(sub.Paths, test) {
+				matching = append(matching, test)
+			}
+		}
+		threshold := sub.Threshold
+		if threshold < 1 {
+			threshold = 1
+		}
+		if len(matching) < threshold {
+			continue
+		}
+		sort.Strings(matching)
+		alerts = append(alerts, Alert{
+			Subscription: sub,
+			Before:       before,
+			After:        after,
+			Regressions:  matching,
+			DiffURL:      diffURL,
+		})
+	}
+
+	return alerts
+}
+
+// matchesAnyPath returns whether the test is one of the paths, or is in one
+// of their directories. A path matches on a directory boundary only, so
+// /css matches /css/a.html, but not /css-grid/a.html.
+func matchesAnyPath(paths []string, test string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	for _, path := range paths {
+		dir := strings.TrimSuffix(path, "/")
+		if test == dir || strings.HasPrefix(test, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notifyAll delivers the alerts through their subscription's notifier,
+// returning the number of alerts which were delivered.
+func notifyAll(logger shared.Logger, notifiers Notifiers, alerts []Alert) int {
+	sent := 0
+	for _, alert := range alerts {
+		notifier, ok := notifiers[alert.Subscription.Notifier]
+		if !ok {
+			logger.Warningf("Notifier %s is not available for subscription %v",
+				alert.Subscription.Notifier, alert.Subscription.ID)
+
+			continue
+		}
+		if err := notifier.Notify(alert); err != nil {
+			logger.Errorf("Failed to notify subscription %v: %s", alert.Subscription.ID, err.Error())
+
+			continue
+		}
+		sent++
+	}
+
+	return sent
+}
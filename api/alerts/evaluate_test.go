@@ -0,0 +1,138 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeNotifier struct {
+	alerts []Alert
+	err    error
+}
+
+func (n *fakeNotifier) Notify(alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+
+	return n.err
+}
+
+func newRun(id int64, browser string, labels ...string) shared.TestRun {
+	run := shared.TestRun{ID: id, Labels: labels}
+	run.BrowserName = browser
+
+	return run
+}
+
+func TestMatchAlerts(t *testing.T) {
+	before := newRun(1, "chrome", "master", "stable")
+	after := newRun(2, "chrome", "master", "stable")
+	regressions := mapset.NewSetWith("/css/grid/a.html", "/css/grid/b.html", "/dom/c.html")
+	subs := []RegressionSubscription{
+		{ID: 1, Product: "chrome", Paths: []string{"/css/grid/"}},
+		{ID: 2, Product: "chrome[stable]", Threshold: 3},
+		{ID: 3, Product: "chrome", Paths: []string{"/css/"}, Threshold: 3},
+		{ID: 4, Product: "firefox"},
+		{ID: 5, Product: "chrome[experimental]"},
+		{ID: 6, Product: "chrome", Paths: []string{"/html/"}},
+	}
+
+	alerts := matchAlerts(before, after, regressions, "https://wpt.fyi/diff", subs)
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, int64(1), alerts[0].Subscription.ID)
+	assert.Equal(t, []string{"/css/grid/a.html", "/css/grid/b.html"}, alerts[0].Regressions)
+	assert.Equal(t, "https://wpt.fyi/diff", alerts[0].DiffURL)
+	assert.Equal(t, before, alerts[0].Before)
+	assert.Equal(t, after, alerts[0].After)
+	assert.Equal(t, int64(2), alerts[1].Subscription.ID)
+	assert.Equal(t, []string{"/css/grid/a.html", "/css/grid/b.html", "/dom/c.html"}, alerts[1].Regressions)
+}
+
+func TestMatchAlerts_NoRegressions(t *testing.T) {
+	run := newRun(1, "chrome", "master")
+	subs := []RegressionSubscription{{ID: 1, Product: "chrome"}}
+	assert.Empty(t, matchAlerts(run, run, mapset.NewSet(), "", subs))
+}
+
+func TestMatchesAnyPath(t *testing.T) {
+	assert.True(t, matchesAnyPath(nil, "/css/a.html"))
+	assert.True(t, matchesAnyPath([]string{"/"}, "/css/a.html"))
+	assert.True(t, matchesAnyPath([]string{"/css"}, "/css/a.html"))
+	assert.True(t, matchesAnyPath([]string{"/css/"}, "/css/grid/a.html"))
+	assert.True(t, matchesAnyPath([]string{"/css/a.html"}, "/css/a.html"))
+	assert.False(t, matchesAnyPath([]string{"/css"}, "/css-grid/a.html"))
+	assert.False(t, matchesAnyPath([]string{"/css/"}, "/css-grid/a.html"))
+	assert.False(t, matchesAnyPath([]string{"/css/a.html"}, "/css/a.html.ini"))
+	assert.False(t, matchesAnyPath([]string{"/dom", "/html"}, "/css/a.html"))
+}
+
+func TestNotifyAll(t *testing.T) {
+	webhook := &fakeNotifier{}
+	failing := &fakeNotifier{err: errors.New("failed")}
+	notifiers := Notifiers{WebhookNotifierName: webhook, EmailNotifierName: failing}
+	alerts := []Alert{
+		{Subscription: RegressionSubscription{ID: 1, Notifier: WebhookNotifierName}},
+		{Subscription: RegressionSubscription{ID: 2, Notifier: EmailNotifierName}},
+		{Subscription: RegressionSubscription{ID: 3, Notifier: "carrier-pigeon"}},
+	}
+
+	sent := notifyAll(shared.NewNilLogger(), notifiers, alerts)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, webhook.alerts, 1)
+	assert.Len(t, failing.alerts, 1)
+}
+
+func TestScheduleEvaluation_NotMaster(t *testing.T) {
+	// No AppEngineAPI calls are expected for non-master runs.
+	assert.Nil(t, ScheduleEvaluation(nil, newRun(1, "chrome", "pr_head")))
+}
+
+func TestEvaluateHandler_NotFromQueue(t *testing.T) {
+	r := httptest.NewRequest("POST", EvaluateTarget, strings.NewReader("run_id=123"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	evaluateHandler(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	r = httptest.NewRequest("POST", EvaluateTarget, strings.NewReader("run_id=123"))
+	r.Header.Set(shared.QueueNameHeader, "other-queue")
+	w = httptest.NewRecorder()
+	evaluateHandler(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReserveEvaluation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	key := &sharedtest.MockKey{TypeName: EvaluationKind, ID: 123}
+	store.EXPECT().NewIDKey(EvaluationKind, int64(123)).AnyTimes().Return(key)
+	now := time.Now()
+	gomock.InOrder(
+		store.EXPECT().Insert(key, &alertEvaluation{Evaluated: now}).Return(nil),
+		store.EXPECT().Insert(key, gomock.Any()).Return(shared.ErrEntityAlreadyExists),
+		store.EXPECT().Insert(key, gomock.Any()).Return(errors.New("unavailable")),
+	)
+
+	assert.Nil(t, reserveEvaluation(store, 123, now))
+	assert.ErrorIs(t, reserveEvaluation(store, 123, now), errAlreadyEvaluated)
+	err := reserveEvaluation(store, 123, now)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, errAlreadyEvaluated))
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+const (
+	// WebhookNotifierName is the name of the notifier which POSTs alerts as JSON.
+	WebhookNotifierName = "webhook"
+	// EmailNotifierName is the name of the notifier which emails alerts.
+	EmailNotifierName = "email"
+)
+
+// Alert is the payload of a regression notification.
+type Alert struct {
+	Subscription RegressionSubscription `json:"subscription"`
+	Before       shared.TestRun         `json:"before"`
+	After        shared.TestRun         `json:"after"`
+	// Regressions are the regressed tests under the subscription's paths.
+	Regressions []string `json:"regressions"`
+	DiffURL     string   `json:"diff_url"`
+}
+
+// Notifier delivers alerts to a subscription's target.
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// Notifiers is a collection of Notifiers, keyed by their name.
+type Notifiers map[string]Notifier
+
+type webhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a Notifier which POSTs the alert, as JSON, to the
+// subscription's target URL.
+func NewWebhookNotifier(client *http.Client) Notifier {
+	return webhookNotifier{client: client}
+}
+
+func (n webhookNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(alert.Subscription.Target, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned HTTP status %d", alert.Subscription.Target, resp.StatusCode)
+	}
+
+	return nil
+}
+
+type emailNotifier struct {
+	server string
+	sender string
+	auth   smtp.Auth
+	send   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier returns a Notifier which emails the alert to the
+// subscription's target address, through the given SMTP server (host:port).
+func NewEmailNotifier(server, sender, password string) Notifier {
+	host := server
+	if i := strings.LastIndex(server, ":"); i >= 0 {
+		host = server[:i]
+	}
+
+	return emailNotifier{
+		server: server,
+		sender: sender,
+		auth:   smtp.PlainAuth("", sender, password, host),
+		send:   smtp.SendMail,
+	}
+}
+
+func (n emailNotifier) Notify(alert Alert) error {
+	return n.send(n.server, n.auth, n.sender, []string{alert.Subscription.Target}, formatAlertEmail(n.sender, alert))
+}
+
+func formatAlertEmail(sender string, alert Alert) []byte {
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", sender)
+	fmt.Fprintf(&body, "To: %s\r\n", alert.Subscription.Target)
+	fmt.Fprintf(&body, "Subject: [wpt.fyi] %d regressions in %s @ %s\r\n",
+		len(alert.Regressions), alert.After.BrowserName, alert.After.Revision)
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&body, "%s regressed %d tests between %s and %s.\r\n\r\n",
+		alert.After.String(), len(alert.Regressions), alert.Before.Revision, alert.After.Revision)
+	for _, test := range alert.Regressions {
+		fmt.Fprintf(&body, "  %s\r\n", test)
+	}
+	fmt.Fprintf(&body, "\r\nSee %s\r\n", alert.DiffURL)
+
+	return []byte(body.String())
+}
+
+// NewNotifiers returns the notifiers available in this AppEngine project. The
+// email notifier is only available if its SMTP secrets are configured.
+func NewNotifiers(aeAPI shared.AppEngineAPI, store shared.Datastore) Notifiers {
+	notifiers := Notifiers{
+		WebhookNotifierName: NewWebhookNotifier(aeAPI.GetHTTPClient()),
+	}
+	server, err := shared.GetSecret(store, "alerts-smtp-server")
+	if err != nil {
+		return notifiers
+	}
+	sender, err := shared.GetSecret(store, "alerts-smtp-sender")
+	if err != nil {
+		return notifiers
+	}
+	password, err := shared.GetSecret(store, "alerts-smtp-password")
+	if err != nil {
+		return notifiers
+	}
+	notifiers[EmailNotifierName] = NewEmailNotifier(server, sender, password)
+
+	return notifiers
+}
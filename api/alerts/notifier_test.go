@@ -0,0 +1,70 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	alert := Alert{
+		Subscription: RegressionSubscription{ID: 1, Target: server.URL},
+		Regressions:  []string{"/a.html"},
+	}
+	assert.Nil(t, NewWebhookNotifier(server.Client()).Notify(alert))
+	assert.Equal(t, alert.Regressions, received.Regressions)
+}
+
+func TestWebhookNotifier_BadStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	alert := Alert{Subscription: RegressionSubscription{Target: server.URL}}
+	assert.NotNil(t, NewWebhookNotifier(server.Client()).Notify(alert))
+}
+
+func TestEmailNotifier(t *testing.T) {
+	var to []string
+	var msg []byte
+	notifier := NewEmailNotifier("smtp.example.com:587", "alerts@wpt.fyi", "password").(emailNotifier)
+	notifier.send = func(addr string, a smtp.Auth, from string, recipients []string, body []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Equal(t, "alerts@wpt.fyi", from)
+		to = recipients
+		msg = body
+
+		return nil
+	}
+
+	alert := Alert{
+		Subscription: RegressionSubscription{Target: "me@example.com"},
+		Before:       newRun(1, "chrome"),
+		After:        newRun(2, "chrome"),
+		Regressions:  []string{"/a.html", "/b.html"},
+		DiffURL:      "https://wpt.fyi/results/?diff",
+	}
+	assert.Nil(t, notifier.Notify(alert))
+	assert.Equal(t, []string{"me@example.com"}, to)
+	assert.Contains(t, string(msg), "Subject: [wpt.fyi] 2 regressions in chrome")
+	assert.Contains(t, string(msg), "  /b.html\r\n")
+	assert.Contains(t, string(msg), "https://wpt.fyi/results/?diff")
+}
@@ -0,0 +1,19 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import "github.com/web-platform-tests/wpt.fyi/shared"
+
+// RegisterRoutes adds the route handlers for regression alerts.
+func RegisterRoutes() {
+	// API endpoints for managing the logged-in user's regression subscriptions.
+	subscriptions := shared.WrapApplicationJSON(apiSubscriptionsHandler)
+	shared.AddRoute("/api/alerts/subscriptions", "api-alerts-subscriptions", subscriptions)
+	shared.AddRoute("/api/alerts/subscriptions/{id:[0-9]+}", "api-alerts-subscription", subscriptions)
+
+	// Endpoint for evaluating subscriptions against a new master run.
+	// When a run is created, we call this endpoint from the regression-alerts TaskQueue.
+	shared.AddRoute(EvaluateTarget, "api-alerts-evaluate", evaluateHandler)
+}
@@ -0,0 +1,210 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// SubscriptionKind is the Datastore kind of RegressionSubscription entities.
+const SubscriptionKind = "RegressionSubscription"
+
+// RegressionSubscription is a user's request to be notified when master runs
+// of a product regress under the given paths.
+type RegressionSubscription struct {
+	ID int64 `json:"id" datastore:"-"`
+	// User is the GitHub handle of the user who owns the subscription.
+	User string `json:"user"`
+	// Product is the product spec of the runs to watch, e.g. chrome[experimental].
+	Product string `json:"product"`
+	// Paths are the path prefixes to watch; all tests are watched if empty.
+	Paths []string `json:"paths,omitempty"`
+	// Threshold is the minimum number of regressed tests to notify about.
+	Threshold int `json:"threshold"`
+	// Notifier is the name of the Notifier to use, e.g. "webhook" or "email".
+	Notifier string `json:"notifier"`
+	// Target is where the notifier should deliver to, e.g. a URL or address.
+	Target  string    `json:"target"`
+	Created time.Time `json:"created"`
+	// Deleted subscriptions are kept, but no longer listed or evaluated.
+	Deleted bool `json:"-"`
+}
+
+// ErrInvalidSubscription is returned when a subscription fails validation.
+var ErrInvalidSubscription = errors.New("invalid subscription")
+
+// Validate checks that the subscription can be evaluated and delivered.
+func (s RegressionSubscription) Validate() error {
+	if _, err := shared.ParseProductSpec(s.Product); err != nil {
+		return fmt.Errorf("%w: invalid product: %s", ErrInvalidSubscription, err.Error())
+	}
+	if s.Threshold < 0 {
+		return fmt.Errorf("%w: threshold must not be negative", ErrInvalidSubscription)
+	}
+	for _, path := range s.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("%w: path %q must start with /", ErrInvalidSubscription, path)
+		}
+	}
+	switch s.Notifier {
+	case WebhookNotifierName:
+		u, err := url.Parse(s.Target)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("%w: webhook target must be an https URL", ErrInvalidSubscription)
+		}
+	case EmailNotifierName:
+		if !strings.Contains(s.Target, "@") {
+			return fmt.Errorf("%w: email target must be an email address", ErrInvalidSubscription)
+		}
+	default:
+		return fmt.Errorf("%w: unknown notifier %q", ErrInvalidSubscription, s.Notifier)
+	}
+
+	return nil
+}
+
+// apiSubscriptionsHandler lists (GET) and creates (POST) the logged-in user's
+// regression subscriptions, and deletes them (DELETE) by ID.
+func apiSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := shared.NewAppEngineDatastore(ctx, false)
+	user, _ := shared.GetUserFromCookie(ctx, store, r)
+	if user == nil {
+		http.Error(w, "User is not logged in", http.StatusUnauthorized)
+
+		return
+	}
+	handleSubscriptions(store, user, w, r)
+}
+
+func handleSubscriptions(store shared.Datastore, user *shared.User, w http.ResponseWriter, r *http.Request) {
+	logger := shared.GetLogger(store.Context())
+
+	var res interface{}
+	var err error
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+		res, err = listSubscriptions(store, user.GitHubHandle)
+	case http.MethodPost:
+		var sub *RegressionSubscription
+		sub, err = parseSubscription(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+		sub.User = user.GitHubHandle
+		sub.Created = time.Now()
+		var key shared.Key
+		key, err = store.Put(store.NewIncompleteKey(SubscriptionKind), sub)
+		if err == nil {
+			sub.ID = key.IntID()
+			status = http.StatusCreated
+			logger.Infof("Created regression subscription %v for %s", sub.ID, sub.User)
+		}
+		res = sub
+	case http.MethodDelete:
+		id, parseErr := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if parseErr != nil {
+			http.Error(w, "Invalid subscription id", http.StatusBadRequest)
+
+			return
+		}
+		err = deleteSubscription(store, user.GitHubHandle, id)
+		if errors.Is(err, shared.ErrNoSuchEntity) {
+			http.Error(w, "Subscription not found", http.StatusNotFound)
+
+			return
+		}
+		status = http.StatusNoContent
+	default:
+		http.Error(w, "Only GET, POST and DELETE are supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if err != nil {
+		logger.Errorf("Failed to handle regression subscriptions: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(status)
+	if res == nil {
+		return
+	}
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		logger.Errorf("Failed to marshal regression subscriptions: %s", err.Error())
+
+		return
+	}
+	if _, err = w.Write(marshalled); err != nil {
+		logger.Warningf("Failed to write data in api/alerts handler: %s", err.Error())
+	}
+}
+
+func parseSubscription(body io.Reader) (*RegressionSubscription, error) {
+	var sub RegressionSubscription
+	if err := json.NewDecoder(body).Decode(&sub); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func listSubscriptions(store shared.Datastore, user string) ([]RegressionSubscription, error) {
+	var subs []RegressionSubscription
+	query := store.NewQuery(SubscriptionKind)
+	if user != "" {
+		query = query.Filter("User =", user)
+	}
+	keys, err := store.GetAll(query, &subs)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]RegressionSubscription, 0, len(subs))
+	for i := range subs {
+		if subs[i].Deleted {
+			continue
+		}
+		subs[i].ID = keys[i].IntID()
+		active = append(active, subs[i])
+	}
+
+	return active, nil
+}
+
+// deleteSubscription marks the given subscription as deleted, if it belongs to
+// the given user.
+func deleteSubscription(store shared.Datastore, user string, id int64) error {
+	key := store.NewIDKey(SubscriptionKind, id)
+	var sub RegressionSubscription
+
+	return store.Update(key, &sub, func(obj interface{}) error {
+		s := obj.(*RegressionSubscription)
+		if s.User == "" || s.User != user || s.Deleted {
+			return shared.ErrNoSuchEntity
+		}
+		s.Deleted = true
+
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package alerts
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRegressionSubscription_Validate(t *testing.T) {
+	valid := RegressionSubscription{
+		Product:  "chrome[experimental]",
+		Paths:    []string{"/css/"},
+		Notifier: WebhookNotifierName,
+		Target:   "https://example.com/hook",
+	}
+	assert.Nil(t, valid.Validate())
+
+	email := valid
+	email.Notifier = EmailNotifierName
+	email.Target = "me@example.com"
+	assert.Nil(t, email.Validate())
+
+	for name, mutate := range map[string]func(s *RegressionSubscription){
+		"bad product":      func(s *RegressionSubscription) { s.Product = "not a browser" },
+		"negative":         func(s *RegressionSubscription) { s.Threshold = -1 },
+		"relative path":    func(s *RegressionSubscription) { s.Paths = []string{"css"} },
+		"http webhook":     func(s *RegressionSubscription) { s.Target = "http://example.com/hook" },
+		"unknown notifier": func(s *RegressionSubscription) { s.Notifier = "sms" },
+		"bad email": func(s *RegressionSubscription) {
+			s.Notifier = EmailNotifierName
+			s.Target = "example.com"
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sub := valid
+			mutate(&sub)
+			assert.True(t, errors.Is(sub.Validate(), ErrInvalidSubscription))
+		})
+	}
+}
+
+func TestHandleSubscriptions_Create(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	store.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	key := &sharedtest.MockKey{TypeName: SubscriptionKind, ID: 123}
+	store.EXPECT().NewIncompleteKey(SubscriptionKind).Return(key)
+	store.EXPECT().Put(key, gomock.Any()).DoAndReturn(func(_ shared.Key, src interface{}) (shared.Key, error) {
+		sub := src.(*RegressionSubscription)
+		assert.Equal(t, "octocat", sub.User)
+		assert.Equal(t, "chrome", sub.Product)
+
+		return key, nil
+	})
+
+	body := `{"product":"chrome","paths":["/css/"],"threshold":2,"notifier":"webhook","target":"https://example.com/"}`
+	r := httptest.NewRequest("POST", "/api/alerts/subscriptions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handleSubscriptions(store, &shared.User{GitHubHandle: "octocat"}, w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":123`)
+}
+
+func TestHandleSubscriptions_CreateInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	store.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+
+	body := `{"product":"chrome","notifier":"sms","target":"12345"}`
+	r := httptest.NewRequest("POST", "/api/alerts/subscriptions", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handleSubscriptions(store, &shared.User{GitHubHandle: "octocat"}, w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
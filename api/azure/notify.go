@@ -5,50 +5,18 @@
 package azure
 
 import (
-	"fmt"
 	"net/http"
-	"strconv"
 
-	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
 func notifyHandler(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id := vars["id"]
-	var buildID int64
-	var err error
-	if buildID, err = strconv.ParseInt(id, 0, 0); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid build id: %s", id), http.StatusBadRequest)
-
-		return
-	}
-
 	ctx := r.Context()
-	aeAPI := shared.NewAppEngineAPI(ctx)
-	azureAPI := NewAPI(ctx)
-	log := shared.GetLogger(ctx)
-
-	processed, err := processBuild(
-		aeAPI,
-		azureAPI,
-		shared.WPTRepoOwner,
-		shared.WPTRepoName,
-		"",                      // No sender info.
-		r.FormValue("artifact"), // artifact=foo will only process foo.
-		buildID)
-
-	if err != nil {
-		log.Errorf("%v", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
-	}
-	if processed {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, "Azure build artifacts retrieved successfully")
-	} else {
-		w.WriteHeader(http.StatusNoContent)
-		fmt.Fprintln(w, "Notification of build artifacts was ignored")
+	p := provider{
+		aeAPI:    shared.NewAppEngineAPI(ctx),
+		azureAPI: NewAPI(ctx),
 	}
+	ci.HandleNotification(receiver.NewAPI(ctx), p, w, r)
 }
@@ -10,10 +10,12 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 
 	mapset "github.com/deckarep/golang-set"
+	"github.com/gorilla/mux"
 
-	uc "github.com/web-platform-tests/wpt.fyi/api/receiver/client"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
@@ -29,96 +31,118 @@ var (
 	epochBranchesRegex = regexp.MustCompile("^refs/heads/epochs/.*")
 )
 
-func processBuild(
-	aeAPI shared.AppEngineAPI,
-	azureAPI API,
-	owner,
-	repo,
-	sender,
-	artifactName string,
-	buildID int64,
-) (bool, error) {
-	build, err := azureAPI.GetBuild(owner, repo, buildID)
+// provider is the ci.CIProvider of Azure Pipelines builds.
+type provider struct {
+	aeAPI    shared.AppEngineAPI
+	azureAPI API
+}
+
+// buildEvent is the Azure-specific information of a ci.Build.
+type buildEvent struct {
+	build   *Build
+	buildID int64
+	// artifactName is the only artifact to upload, if not empty.
+	artifactName string
+}
+
+func (p provider) Uploader() string {
+	return uploaderName
+}
+
+// ParseEvent parses the notification that the web-platform-tests/wpt build
+// with the ID in the URL has finished. An artifact=foo param limits the
+// artifacts which are uploaded to foo.
+func (p provider) ParseEvent(r *http.Request) (*ci.Build, error) {
+	id := mux.Vars(r)["id"]
+	buildID, err := strconv.ParseInt(id, 0, 0)
+	if err != nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "invalid build id: %s", id)
+	}
+	owner, repo := shared.WPTRepoOwner, shared.WPTRepoName
+	build, err := p.azureAPI.GetBuild(owner, repo, buildID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	if build == nil {
-		return false, fmt.Errorf("cannot get build %s/%s/%d", owner, repo, buildID)
+		return nil, fmt.Errorf("cannot get build %s/%s/%d", owner, repo, buildID)
 	}
-	sha := build.TriggerInfo.SourceSHA
 
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &ci.Build{
+		ID:    id,
+		Owner: owner,
+		Repo:  repo,
+		SHA:   build.TriggerInfo.SourceSHA,
+		Event: buildEvent{build: build, buildID: buildID, artifactName: r.FormValue("artifact")},
+	}, nil
+}
+
+func (p provider) ListArtifacts(b *ci.Build) ([]ci.Artifact, error) {
+	event := b.Event.(buildEvent)
 	// https://docs.microsoft.com/en-us/rest/api/azure/devops/build/artifacts/get?view=azure-devops-rest-4.1
-	artifactsURL := azureAPI.GetAzureArtifactsURL(owner, repo, buildID)
+	artifactsURL := p.azureAPI.GetAzureArtifactsURL(b.Owner, b.Repo, event.buildID)
 
-	log := shared.GetLogger(aeAPI.Context())
+	log := shared.GetLogger(p.aeAPI.Context())
 	log.Infof("Fetching %s", artifactsURL)
 
-	client := aeAPI.GetHTTPClient()
-	req, err := http.NewRequestWithContext(aeAPI.Context(), http.MethodGet, artifactsURL, nil)
+	client := p.aeAPI.GetHTTPClient()
+	req, err := http.NewRequestWithContext(p.aeAPI.Context(), http.MethodGet, artifactsURL, nil)
 	if err != nil {
-		return false, fmt.Errorf("failed to create get for %s/%s/%d: %w", owner, repo, buildID, err)
+		return nil, fmt.Errorf("failed to create get for %s/%s/%d: %w", b.Owner, b.Repo, event.buildID, err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return false, fmt.Errorf("failed to fetch artifacts for %s/%s/%d: %w", owner, repo, buildID, err)
+		return nil, fmt.Errorf("failed to fetch artifacts for %s/%s/%d: %w", b.Owner, b.Repo, event.buildID, err)
 	}
+	defer resp.Body.Close()
 
 	var artifacts BuildArtifacts
 	if body, err := io.ReadAll(resp.Body); err != nil {
-		return false, fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	} else if err = json.Unmarshal(body, &artifacts); err != nil {
-		return false, fmt.Errorf("failed to unmarshal JSON: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 
-	uploadedAny := false
-	errors := make(chan (error), artifacts.Count)
+	var uploads []ci.Artifact
 	for _, artifact := range artifacts.Value {
-		if artifactName != "" && artifactName != artifact.Name {
-			log.Infof("Skipping artifact %s (looking for %s)", artifact.Name, artifactName)
+		if event.artifactName != "" && event.artifactName != artifact.Name {
+			log.Infof("Skipping artifact %s (looking for %s)", artifact.Name, event.artifactName)
 
 			continue
 		}
-		log.Infof("Uploading %s for %s/%s build %v...", artifact.Name, owner, repo, buildID)
-
-		labels := mapset.NewSet()
-		if sender != "" {
-			labels.Add(shared.GetUserLabel(sender))
-		}
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		uploads = append(uploads, ci.Artifact{
+			Name:     artifact.Name,
+			Archives: []string{artifact.Resource.DownloadURL},
+		})
+	}
 
-		if masterRegex.MatchString(artifact.Name) {
-			if build.IsMasterBranch() || epochBranchesRegex.MatchString(build.SourceBranch) {
-				labels.Add(shared.MasterLabel)
-			}
-		} else if prHeadRegex.MatchString(artifact.Name) {
-			labels.Add(shared.PRHeadLabel)
-		} else if prBaseRegex.MatchString(artifact.Name) {
-			labels.Add(shared.PRBaseLabel)
-		}
+	return uploads, nil
+}
 
-		uploader, err := aeAPI.GetUploader(uploaderName)
-		if err != nil {
-			return false, fmt.Errorf("failed to get uploader creds from Datastore: %w", err)
-		}
+func (p provider) ProductAndLabels(b *ci.Build, artifact ci.Artifact) (shared.ProductAtRevision, []string) {
+	build := b.Event.(buildEvent).build
+	labels := mapset.NewSet()
+	if b.Sender != "" {
+		labels.Add(shared.GetUserLabel(b.Sender))
+	}
 
-		uploadClient := uc.NewClient(aeAPI)
-		err = uploadClient.CreateRun(
-			sha,
-			uploader.Username,
-			uploader.Password,
-			nil,
-			nil,
-			[]string{artifact.Resource.DownloadURL},
-			shared.ToStringSlice(labels))
-		if err != nil {
-			errors <- fmt.Errorf("failed to create run: %w", err)
-		} else {
-			uploadedAny = true
+	if masterRegex.MatchString(artifact.Name) {
+		if build.IsMasterBranch() || epochBranchesRegex.MatchString(build.SourceBranch) {
+			labels.Add(shared.MasterLabel)
 		}
+	} else if prHeadRegex.MatchString(artifact.Name) {
+		labels.Add(shared.PRHeadLabel)
+	} else if prBaseRegex.MatchString(artifact.Name) {
+		labels.Add(shared.PRBaseLabel)
 	}
-	close(errors)
-	for err := range errors {
-		return uploadedAny, err
-	}
 
-	return uploadedAny, nil
+	// The product is only known from the results.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.ProductAtRevision{}, shared.ToStringSlice(labels)
+}
+
+func (p provider) ReportStatus(w http.ResponseWriter, _ *ci.Build, _ []shared.PendingTestRun) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "Azure build artifacts retrieved successfully")
 }
@@ -8,9 +8,16 @@ package azure
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
 )
 
 const artifactsJSON = `{
@@ -83,3 +90,62 @@ func TestEpochBranchesRegex(t *testing.T) {
 
 	assert.False(t, epochBranchesRegex.MatchString("refs/heads/weekly"))
 }
+
+// artifactsAPI is an API whose artifacts are at the given URL.
+type artifactsAPI struct {
+	API
+	url string
+}
+
+func (a artifactsAPI) GetAzureArtifactsURL(_, _ string, _ int64) string {
+	return a.url
+}
+
+func TestProviderListArtifacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(artifactsJSON))
+	}))
+	defer server.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	aeAPI.EXPECT().GetHTTPClient().AnyTimes().Return(server.Client())
+	p := provider{aeAPI: aeAPI, azureAPI: artifactsAPI{url: server.URL}}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	build := &ci.Build{
+		ID:    "4",
+		Owner: "web-platform-tests",
+		Repo:  "wpt",
+		Event: buildEvent{build: &Build{SourceBranch: "refs/heads/master"}, buildID: 4},
+	}
+	artifacts, err := p.ListArtifacts(build)
+	assert.Nil(t, err)
+	assert.Len(t, artifacts, 2)
+	assert.Equal(t, "results-without-patch", artifacts[0].Name)
+	assert.Equal(t, "results", artifacts[1].Name)
+	assert.Len(t, artifacts[1].Archives, 1)
+
+	_, labels := p.ProductAndLabels(build, artifacts[1])
+	assert.Equal(t, []string{shared.MasterLabel}, labels)
+
+	build.Event = buildEvent{build: &Build{SourceBranch: "refs/heads/master"}, buildID: 4, artifactName: "results"}
+	artifacts, err = p.ListArtifacts(build)
+	assert.Nil(t, err)
+	assert.Len(t, artifacts, 1)
+}
+
+func TestProviderProductAndLabels(t *testing.T) {
+	p := provider{aeAPI: nil, azureAPI: nil}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	build := &ci.Build{Sender: "person", Event: buildEvent{build: &Build{SourceBranch: "refs/heads/feature"}}}
+
+	_, labels := p.ProductAndLabels(build, ci.Artifact{Name: "safari-results"}) // nolint:exhaustruct
+	assert.Equal(t, []string{"user:person"}, labels)
+	_, labels = p.ProductAndLabels(build, ci.Artifact{Name: "safari-affected-tests"}) // nolint:exhaustruct
+	assert.ElementsMatch(t, []string{"user:person", shared.PRHeadLabel}, labels)
+	_, labels = p.ProductAndLabels(build, ci.Artifact{Name: "safari-affected-tests-without-changes"}) // nolint:exhaustruct
+	assert.ElementsMatch(t, []string{"user:person", shared.PRBaseLabel}, labels)
+}
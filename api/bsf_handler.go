@@ -48,6 +48,20 @@ func (b BSFHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filters, err := shared.ParseTestRunFilterParams(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	// Compute BSF for arbitrary products from their aligned runs; otherwise,
+	// fall back to the precomputed CSV data for the default products.
+	if len(filters.Products) > 0 {
+		b.serveComputedBSF(w, r, filters)
+
+		return
+	}
+
 	isExperimental := false
 	val, _ := shared.ParseBooleanParam(q, "experimental")
 	if val != nil {
@@ -83,3 +97,80 @@ func (b BSFHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Warningf("Failed to write data: %s", err.Error())
 	}
 }
+
+// serveComputedBSF computes BSF data for the aligned runs of the filtered
+// products, by default for the latest aligned revision only. With
+// ?breakdown=true, the BSF of a single revision is broken down by directory.
+func (b BSFHandler) serveComputedBSF(w http.ResponseWriter, r *http.Request, filters shared.TestRunFilter) {
+	ctx := r.Context()
+	logger := shared.GetLogger(ctx)
+	q := r.URL.Query()
+	if len(filters.Products) < 2 {
+		http.Error(w, "At least two products are needed to compute BSF", http.StatusBadRequest)
+
+		return
+	}
+	breakdown, err := shared.ParseBooleanParam(q, "breakdown")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	includeTests, err := shared.ParseBooleanParam(q, "tests")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	isBreakdown := breakdown != nil && *breakdown
+	if isBreakdown {
+		if len(filters.SHAs) > 1 {
+			http.Error(w, "A breakdown is only available for a single revision", http.StatusBadRequest)
+
+			return
+		}
+		one := 1
+		filters.MaxCount = &one
+		filters.From = nil
+	}
+	aligned := true
+	filters.Aligned = &aligned
+
+	store := shared.NewAppEngineDatastore(ctx, false)
+	runsByProduct, err := LoadTestRunsForFilters(store, filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	alignedRuns := shared.GroupAlignedRuns(runsByProduct)
+	if len(alignedRuns) == 0 {
+		http.Error(w, "No aligned runs found for the given products", http.StatusNotFound)
+
+		return
+	}
+
+	var res interface{}
+	if isBreakdown {
+		res, err = shared.FetchAlignedRunsBSFBreakdown(
+			ctx, filters.Products, alignedRuns[0], q.Get("path"), includeTests != nil && *includeTests)
+	} else {
+		res, err = shared.FetchAlignedRunsBSF(ctx, filters.Products, alignedRuns)
+	}
+	if err != nil {
+		logger.Errorf("Failed to compute BSF: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	if _, err = w.Write(marshalled); err != nil {
+		logger.Warningf("Failed to write data: %s", err.Error())
+	}
+}
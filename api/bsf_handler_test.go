@@ -129,3 +129,27 @@ func TestBSFHandler_Success_ExperimentalIncludeThirdParty(t *testing.T) {
 	assert.Equal(t, 1, len(bsfData.Data))
 	assert.Equal(t, dataRow, bsfData.Data[0])
 }
+
+func TestBSFHandler_ComputedNeedsTwoProducts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	r := httptest.NewRequest("GET", "/api/bsf?products=chrome", nil)
+	w := httptest.NewRecorder()
+	mockBSFFetcher := sharedtest.NewMockFetchBSF(mockCtrl)
+	// The precomputed CSVs are not used when products are given.
+	mockBSFFetcher.EXPECT().Fetch(gomock.Any(), gomock.Any()).Times(0)
+
+	BSFHandler{mockBSFFetcher}.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBSFHandler_BreakdownSingleRevision(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	r := httptest.NewRequest("GET", "/api/bsf?products=chrome,firefox&breakdown&sha=1234567890&sha=0987654321", nil)
+	w := httptest.NewRecorder()
+	BSFHandler{sharedtest.NewMockFetchBSF(mockCtrl)}.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
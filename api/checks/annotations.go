@@ -0,0 +1,316 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/metrics"
+)
+
+// maxAnnotationsPerRequest is the maximum number of annotations GitHub accepts
+// in a single request to create or update a check run.
+const maxAnnotationsPerRequest = 50
+
+// maxAnnotatedRegressions is the maximum number of regressed tests which are
+// annotated, since the results of each are fetched.
+const maxAnnotatedRegressions = 200
+
+// maxAnnotatedFlakyRegressions is the maximum number of flaky regressed tests
+// which are annotated; their notices are less useful than the failures of the
+// other regressions, and shouldn't crowd them out of the PR's diff.
+const maxAnnotatedFlakyRegressions = 50
+
+// maxConcurrentFetches is the maximum number of regressed tests whose results
+// are fetched at the same time.
+const maxConcurrentFetches = 10
+
+// maxAnnotatedSubtests is the maximum number of regressed subtests listed in
+// the message of an annotation; all of them are listed in its raw details.
+const maxAnnotatedSubtests = 10
+
+// nolint:gochecknoglobals // TODO: Fix gochecknoglobals lint error
+var (
+	// Multi-global tests, e.g. /foo.any.worker.html, are generated from .js files.
+	anyTestRegex      = regexp.MustCompile(`\.any(\.[a-z-]+)*\.html$`)
+	workerTestRegex   = regexp.MustCompile(`\.(worker|window|extension)\.html$`)
+	passStatusesRegex = regexp.MustCompile(`^(OK|PASS)$`)
+)
+
+// testFilePath returns the path of the file of a test in the wpt repo, e.g.
+// dom/nodes/foo.any.js for /dom/nodes/foo.any.worker.html?variant.
+func testFilePath(test string) string {
+	path := strings.TrimPrefix(test, "/")
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	path = anyTestRegex.ReplaceAllString(path, ".any.js")
+
+	return workerTestRegex.ReplaceAllString(path, ".$1.js")
+}
+
+// getRegressionAnnotations returns annotations of the files of the given
+// regressed tests (up to limit of them), describing how their results, and
+// those of their subtests, changed between the runs.
+func getRegressionAnnotations(
+	aeAPI shared.AppEngineAPI,
+	diff shared.RunDiff,
+	baseRun,
+	headRun shared.TestRun,
+	tests []string,
+	level string,
+	limit int,
+) []*github.CheckRunAnnotation {
+	if len(tests) > limit {
+		tests = tests[:limit]
+	}
+	regressedSubtests := make([][]string, len(tests))
+	if baseRun.ResultsURL != "" && headRun.ResultsURL != "" {
+		regressedSubtests = fetchRegressedSubtests(aeAPI, baseRun, headRun, tests)
+	}
+
+	annotations := make([]*github.CheckRunAnnotation, 0, len(tests))
+	for i, test := range tests {
+		subtests := regressedSubtests[i]
+		lines := []string{"Newly failing."}
+		before, after := diff.BeforeSummary[test], diff.AfterSummary[test]
+		if len(before) == 2 && len(after) == 2 {
+			lines[0] = fmt.Sprintf("%d / %d passing, was %d / %d.", after[0], after[1], before[0], before[1])
+		}
+		for i, subtest := range subtests {
+			if i == maxAnnotatedSubtests {
+				lines = append(lines, fmt.Sprintf("And %d more...", len(subtests)-i))
+
+				break
+			}
+			lines = append(lines, subtest)
+		}
+		message := strings.Join(lines, "\n")
+
+		path := testFilePath(test)
+		line := 1
+		title := "Regression in " + test
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+		annotation := github.CheckRunAnnotation{
+			Path:            &path,
+			StartLine:       &line,
+			EndLine:         &line,
+			AnnotationLevel: &level,
+			Title:           &title,
+			Message:         &message,
+		}
+		if len(subtests) > maxAnnotatedSubtests {
+			details := strings.Join(subtests, "\n")
+			annotation.RawDetails = &details
+		}
+		annotations = append(annotations, &annotation)
+	}
+
+	return annotations
+}
+
+// fetchRegressedSubtests fetches the results of the given tests in both runs,
+// at most maxConcurrentFetches tests at a time, and returns the regressed
+// subtests of each (see getRegressedSubtests). The subtests of tests whose
+// results can't be fetched are nil.
+func fetchRegressedSubtests(
+	aeAPI shared.AppEngineAPI,
+	baseRun,
+	headRun shared.TestRun,
+	tests []string,
+) [][]string {
+	ctx := aeAPI.Context()
+	log := shared.GetLogger(ctx)
+	client := aeAPI.GetHTTPClient()
+	regressed := make([][]string, len(tests))
+	semaphore := make(chan struct{}, maxConcurrentFetches)
+	var wg sync.WaitGroup
+	for i, test := range tests {
+		wg.Add(1)
+
+		go func(i int, test string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			subtests, err := getRegressedSubtests(ctx, client, baseRun, headRun, test)
+			if err != nil {
+				log.Warningf("Failed to load the results of %s: %s", test, err.Error())
+
+				return
+			}
+			regressed[i] = subtests
+		}(i, test)
+	}
+	wg.Wait()
+
+	return regressed
+}
+
+// getRegressedSubtests returns descriptions of the statuses of the test, and
+// its subtests, which passed in the base run but no longer pass in the head
+// run, e.g. "Subtest name: PASS -> FAIL".
+func getRegressedSubtests(
+	ctx context.Context,
+	client *http.Client,
+	baseRun,
+	headRun shared.TestRun,
+	test string,
+) ([]string, error) {
+	before, err := fetchTestResults(ctx, client, baseRun, test)
+	if err != nil {
+		return nil, err
+	}
+	after, err := fetchTestResults(ctx, client, headRun, test)
+	if err != nil {
+		return nil, err
+	}
+
+	var regressed []string
+	if passStatusesRegex.MatchString(before.Status) && before.Status != after.Status {
+		regressed = append(regressed, fmt.Sprintf("Harness status: %s -> %s", before.Status, after.Status))
+	}
+	afterStatuses := make(map[string]string, len(after.Subtests))
+	for _, subtest := range after.Subtests {
+		afterStatuses[subtest.Name] = subtest.Status
+	}
+	for _, subtest := range before.Subtests {
+		if !passStatusesRegex.MatchString(subtest.Status) {
+			continue
+		}
+		afterStatus, ok := afterStatuses[subtest.Name]
+		if !ok {
+			afterStatus = "MISSING"
+		}
+		if afterStatus != subtest.Status {
+			regressed = append(regressed, fmt.Sprintf("%s: %s -> %s", subtest.Name, subtest.Status, afterStatus))
+		}
+	}
+
+	return regressed, nil
+}
+
+func fetchTestResults(
+	ctx context.Context,
+	client *http.Client,
+	run shared.TestRun,
+	test string,
+) (*metrics.TestResults, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, shared.GetResultsURL(run, test), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %d", req.URL.String(), resp.StatusCode)
+	}
+	var results metrics.TestResults
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// batchAnnotations splits annotations into batches which can each be sent in
+// a single request.
+func batchAnnotations(annotations []*github.CheckRunAnnotation) [][]*github.CheckRunAnnotation {
+	var batches [][]*github.CheckRunAnnotation
+	for len(annotations) > maxAnnotationsPerRequest {
+		batches = append(batches, annotations[:maxAnnotationsPerRequest])
+		annotations = annotations[maxAnnotationsPerRequest:]
+	}
+	if len(annotations) > 0 {
+		batches = append(batches, annotations)
+	}
+
+	return batches
+}
+
+// addCheckRunAnnotations adds the given batches of annotations to a check run,
+// one update (with the given name, title and summary, which GitHub requires)
+// per batch.
+func addCheckRunAnnotations(
+	ctx context.Context,
+	client *github.Client,
+	suite shared.CheckSuite,
+	runID int64,
+	name,
+	title,
+	summary string,
+	batches [][]*github.CheckRunAnnotation,
+) error {
+	for _, batch := range batches {
+		// nolint:exhaustruct // WONTFIX: Name only required.
+		opts := github.UpdateCheckRunOptions{
+			Name: name,
+			Output: &github.CheckRunOutput{
+				Title:       &title,
+				Summary:     &summary,
+				Annotations: batch,
+			},
+		}
+		if _, _, err := client.Checks.UpdateCheckRun(ctx, suite.Owner, suite.Repo, runID, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// filterExistingAnnotations returns the given annotations which the check run
+// doesn't already have, since GitHub appends the annotations of updates.
+func filterExistingAnnotations(
+	ctx context.Context,
+	client *github.Client,
+	suite shared.CheckSuite,
+	runID int64,
+	annotations []*github.CheckRunAnnotation,
+) ([]*github.CheckRunAnnotation, error) {
+	if len(annotations) == 0 {
+		return nil, nil
+	}
+	key := func(a *github.CheckRunAnnotation) string {
+		return a.GetPath() + "\n" + a.GetTitle() + "\n" + a.GetMessage()
+	}
+	existing := make(map[string]bool)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+	options := github.ListOptions{PerPage: 100}
+	// As in getExistingCheckRuns, don't do more than 10 iterations.
+	for i := 0; i < 10; i++ {
+		result, response, err := client.Checks.ListCheckRunAnnotations(ctx, suite.Owner, suite.Repo, runID, &options)
+		if err != nil {
+			return nil, err
+		}
+		for _, annotation := range result {
+			existing[key(annotation)] = true
+		}
+		if response.NextPage == 0 {
+			break
+		}
+		options.Page = response.NextPage
+	}
+
+	var added []*github.CheckRunAnnotation
+	for _, annotation := range annotations {
+		if !existing[key(annotation)] {
+			added = append(added, annotation)
+		}
+	}
+
+	return added, nil
+}
@@ -0,0 +1,132 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestTestFilePath(t *testing.T) {
+	for test, path := range map[string]string{
+		"/dom/nodes/foo.html":                         "dom/nodes/foo.html",
+		"/dom/nodes/foo.html?a=b":                     "dom/nodes/foo.html",
+		"/dom/nodes/foo.any.html":                     "dom/nodes/foo.any.js",
+		"/dom/nodes/foo.any.worker.html?1-10":         "dom/nodes/foo.any.js",
+		"/dom/nodes/foo.https.any.serviceworker.html": "dom/nodes/foo.https.any.js",
+		"/dom/nodes/foo.worker.html":                  "dom/nodes/foo.worker.js",
+		"/dom/nodes/foo.window.html#x":                "dom/nodes/foo.window.js",
+		"/webdriver/tests/foo/bar.py":                 "webdriver/tests/foo/bar.py",
+	} {
+		assert.Equal(t, path, testFilePath(test), test)
+	}
+}
+
+func TestGetRegressionAnnotations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/0000000000/chrome-pr_base//foo.any.worker.html":
+			fmt.Fprint(w, `{"test": "/foo.any.worker.html", "status": "OK", "subtests": [
+				{"name": "a", "status": "PASS"}, {"name": "b", "status": "PASS"}, {"name": "c", "status": "FAIL"}]}`)
+		case "/1111111111/chrome-pr_head//foo.any.worker.html":
+			fmt.Fprint(w, `{"test": "/foo.any.worker.html", "status": "OK", "subtests": [
+				{"name": "a", "status": "PASS"}, {"name": "b", "status": "TIMEOUT"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().GetHTTPClient().Return(server.Client())
+
+	before, after := getBeforeAndAfterRuns()
+	before.Revision = "0000000000"
+	before.ResultsURL = server.URL + "/0000000000/chrome-pr_base-summary_v2.json.gz"
+	after.Revision = "1111111111"
+	after.ResultsURL = server.URL + "/1111111111/chrome-pr_head-summary_v2.json.gz"
+	diff := shared.RunDiff{
+		BeforeSummary: shared.ResultsSummary{"/foo.any.worker.html": {2, 3}},
+		AfterSummary:  shared.ResultsSummary{"/foo.any.worker.html": {1, 2}},
+	}
+
+	annotations := getRegressionAnnotations(
+		aeAPI, diff, before, after, []string{"/foo.any.worker.html", "/missing.html"}, "failure", maxAnnotatedRegressions)
+	assert.Len(t, annotations, 2)
+	assert.Equal(t, "foo.any.js", annotations[0].GetPath())
+	assert.Equal(t, 1, annotations[0].GetStartLine())
+	assert.Equal(t, "failure", annotations[0].GetAnnotationLevel())
+	assert.Equal(t, "Regression in /foo.any.worker.html", annotations[0].GetTitle())
+	assert.Equal(t, "1 / 2 passing, was 2 / 3.\nb: PASS -> TIMEOUT", annotations[0].GetMessage())
+	// The results of /missing.html can't be fetched.
+	assert.Equal(t, "missing.html", annotations[1].GetPath())
+	assert.Equal(t, "Newly failing.", annotations[1].GetMessage())
+}
+
+func TestGetRegressionAnnotations_limits(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		fmt.Fprint(w, `{"status": "OK", "subtests": []}`)
+	}))
+	defer server.Close()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().GetHTTPClient().AnyTimes().Return(server.Client())
+
+	before, after := getBeforeAndAfterRuns()
+	before.ResultsURL = server.URL + "/before-summary_v2.json.gz"
+	after.ResultsURL = server.URL + "/after-summary_v2.json.gz"
+	tests := make([]string, 100)
+	for i := range tests {
+		tests[i] = fmt.Sprintf("/test%d.html", i)
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	annotations := getRegressionAnnotations(
+		aeAPI, shared.RunDiff{}, before, after, tests, "notice", maxAnnotatedFlakyRegressions)
+	assert.Len(t, annotations, maxAnnotatedFlakyRegressions)
+	assert.Equal(t, "test49.html", annotations[49].GetPath())
+	assert.LessOrEqual(t, maxInFlight, int32(maxConcurrentFetches))
+}
+
+func TestBatchAnnotations(t *testing.T) {
+	assert.Empty(t, batchAnnotations(nil))
+
+	annotations := make([]*github.CheckRunAnnotation, 120)
+	batches := batchAnnotations(annotations)
+	assert.Len(t, batches, 3)
+	assert.Len(t, batches[0], maxAnnotationsPerRequest)
+	assert.Len(t, batches[1], maxAnnotationsPerRequest)
+	assert.Len(t, batches[2], 20)
+}
@@ -32,11 +32,14 @@ type API interface {
 	shared.AppEngineAPI
 
 	ScheduleResultsProcessing(sha string, browser shared.ProductSpec) error
+	ScheduleRetry(sha string, browser shared.ProductSpec) error
 	GetSuitesForSHA(sha string) ([]shared.CheckSuite, error)
+	GetCheckRunsForSHA(sha string) ([]*github.CheckRun, error)
 	IgnoreFailure(sender, owner, repo string, run *github.CheckRun, installation *github.Installation) error
 	CancelRun(sender, owner, repo string, run *github.CheckRun, installation *github.Installation) error
-	CreateWPTCheckSuite(appID, installationID int64, sha string, prNumbers ...int) (bool, error)
+	CreateCheckSuite(repo shared.CheckRepo, sha string, prNumbers ...int) (bool, error)
 	GetWPTRepoAppInstallationIDs() (appID, installationID int64)
+	GetCheckRepo(owner, repo string) (*shared.CheckRepo, error)
 }
 
 type checksAPIImpl struct {
@@ -71,6 +74,23 @@ func (s checksAPIImpl) ScheduleResultsProcessing(sha string, product shared.Prod
 	return err
 }
 
+// ScheduleRetry adds a URL for callback to TaskQueue for the given sha and
+// product, which will request a re-run of just the regressed tests.
+func (s checksAPIImpl) ScheduleRetry(sha string, product shared.ProductSpec) error {
+	log := shared.GetLogger(s.Context())
+	target := fmt.Sprintf("/api/checks/%s/retry", sha)
+	q := url.Values{}
+	q.Set("product", product.String())
+	_, err := s.ScheduleTask(s.queue, "", target, q)
+	if err != nil {
+		log.Warningf("Failed to queue retry of %s @ %s: %s", product.String(), sha[:7], err.Error())
+	} else {
+		log.Infof("Added retry of %s @ %s to checks processing queue", product.String(), sha[:7])
+	}
+
+	return err
+}
+
 // GetSuitesForSHA gets all existing check suites for the given Head SHA.
 func (s checksAPIImpl) GetSuitesForSHA(sha string) ([]shared.CheckSuite, error) {
 	var suites []shared.CheckSuite
@@ -80,6 +100,30 @@ func (s checksAPIImpl) GetSuitesForSHA(sha string) ([]shared.CheckSuite, error)
 	return suites, err
 }
 
+// GetCheckRunsForSHA returns the check runs created by wpt.fyi in the check
+// suites for the given SHA.
+func (s checksAPIImpl) GetCheckRunsForSHA(sha string) ([]*github.CheckRun, error) {
+	suites, err := s.GetSuitesForSHA(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []*github.CheckRun
+	for _, suite := range suites {
+		suiteRuns, err := getExistingCheckRuns(s.Context(), suite)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range suiteRuns {
+			if run.GetApp().GetID() == suite.AppID {
+				runs = append(runs, run)
+			}
+		}
+	}
+
+	return runs, nil
+}
+
 // IgnoreFailure updates the given CheckRun's outcome to success, even if it failed.
 func (s checksAPIImpl) IgnoreFailure(
 	sender,
@@ -159,13 +203,14 @@ func (s checksAPIImpl) CancelRun(
 	return err
 }
 
-// CreateWPTCheckSuite creates a check_suite on the main wpt repo for the given
-// SHA. This is needed when a PR comes from a different fork of the repo.
-func (s checksAPIImpl) CreateWPTCheckSuite(appID, installationID int64, sha string, prNumbers ...int) (bool, error) {
+// CreateCheckSuite creates a check_suite on the given repo for the given SHA,
+// with the app of the repo. This is needed when a PR comes from a different
+// fork of the repo.
+func (s checksAPIImpl) CreateCheckSuite(repo shared.CheckRepo, sha string, prNumbers ...int) (bool, error) {
 	log := shared.GetLogger(s.Context())
-	log.Debugf("Creating check_suite for web-platform-tests/wpt @ %s", sha)
+	log.Debugf("Creating check_suite for %s/%s @ %s", repo.Owner, repo.Repo, sha)
 
-	client, err := getGitHubClient(s.Context(), appID, installationID)
+	client, err := getGitHubClient(s.Context(), repo.AppID, repo.InstallationID)
 	if err != nil {
 		return false, err
 	}
@@ -174,7 +219,7 @@ func (s checksAPIImpl) CreateWPTCheckSuite(appID, installationID int64, sha stri
 	opts := github.CreateCheckSuiteOptions{
 		HeadSHA: sha,
 	}
-	suite, _, err := client.Checks.CreateCheckSuite(s.Context(), shared.WPTRepoOwner, shared.WPTRepoName, opts)
+	suite, _, err := client.Checks.CreateCheckSuite(s.Context(), repo.Owner, repo.Repo, opts)
 	if err != nil {
 		log.Errorf("Failed to create GitHub check suite: %s", err.Error())
 	} else if suite != nil {
@@ -182,10 +227,10 @@ func (s checksAPIImpl) CreateWPTCheckSuite(appID, installationID int64, sha stri
 		_, err = getOrCreateCheckSuite(
 			s.Context(),
 			sha,
-			shared.WPTRepoOwner,
-			shared.WPTRepoName,
-			appID,
-			installationID,
+			repo.Owner,
+			repo.Repo,
+			repo.AppID,
+			repo.InstallationID,
 			prNumbers...,
 		)
 		if err != nil {
@@ -204,3 +249,23 @@ func (s checksAPIImpl) GetWPTRepoAppInstallationIDs() (appID, installationID int
 	// Default to staging
 	return wptfyiStagingCheckAppID, wptRepoStagingInstallationID
 }
+
+// GetCheckRepo returns the config of the checks of the given repo: the
+// built-in config for wpt, or the CheckRepo entity of any other repo, which
+// is nil if the repo isn't configured.
+func (s checksAPIImpl) GetCheckRepo(owner, repo string) (*shared.CheckRepo, error) {
+	if owner == shared.WPTRepoOwner && repo == shared.WPTRepoName {
+		appID, installationID := s.GetWPTRepoAppInstallationIDs()
+
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		return &shared.CheckRepo{
+			Owner:          owner,
+			Repo:           repo,
+			RepoID:         wptRepoID,
+			AppID:          appID,
+			InstallationID: installationID,
+		}, nil
+	}
+
+	return LoadCheckRepo(shared.NewAppEngineDatastore(s.Context(), false), owner, repo)
+}
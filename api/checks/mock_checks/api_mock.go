@@ -73,24 +73,69 @@ func (mr *MockAPIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAPI)(nil).Context))
 }
 
-// CreateWPTCheckSuite mocks base method.
-func (m *MockAPI) CreateWPTCheckSuite(appID, installationID int64, sha string, prNumbers ...int) (bool, error) {
+// CreateCheckSuite mocks base method.
+func (m *MockAPI) CreateCheckSuite(repo shared.CheckRepo, sha string, prNumbers ...int) (bool, error) {
 	m.ctrl.T.Helper()
-	varargs := []any{appID, installationID, sha}
+	varargs := []any{repo, sha}
 	for _, a := range prNumbers {
 		varargs = append(varargs, a)
 	}
-	ret := m.ctrl.Call(m, "CreateWPTCheckSuite", varargs...)
+	ret := m.ctrl.Call(m, "CreateCheckSuite", varargs...)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateWPTCheckSuite indicates an expected call of CreateWPTCheckSuite.
-func (mr *MockAPIMockRecorder) CreateWPTCheckSuite(appID, installationID, sha any, prNumbers ...any) *gomock.Call {
+// CreateCheckSuite indicates an expected call of CreateCheckSuite.
+func (mr *MockAPIMockRecorder) CreateCheckSuite(repo, sha any, prNumbers ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]any{appID, installationID, sha}, prNumbers...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWPTCheckSuite", reflect.TypeOf((*MockAPI)(nil).CreateWPTCheckSuite), varargs...)
+	varargs := append([]any{repo, sha}, prNumbers...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCheckSuite", reflect.TypeOf((*MockAPI)(nil).CreateCheckSuite), varargs...)
+}
+
+// GetAPIToken mocks base method.
+func (m *MockAPI) GetAPIToken(token string) (*shared.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", token)
+	ret0, _ := ret[0].(*shared.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockAPIMockRecorder) GetAPIToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockAPI)(nil).GetAPIToken), token)
+}
+
+// GetCheckRepo mocks base method.
+func (m *MockAPI) GetCheckRepo(owner, repo string) (*shared.CheckRepo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckRepo", owner, repo)
+	ret0, _ := ret[0].(*shared.CheckRepo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCheckRepo indicates an expected call of GetCheckRepo.
+func (mr *MockAPIMockRecorder) GetCheckRepo(owner, repo any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckRepo", reflect.TypeOf((*MockAPI)(nil).GetCheckRepo), owner, repo)
+}
+
+// GetCheckRunsForSHA mocks base method.
+func (m *MockAPI) GetCheckRunsForSHA(sha string) ([]*github.CheckRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCheckRunsForSHA", sha)
+	ret0, _ := ret[0].([]*github.CheckRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCheckRunsForSHA indicates an expected call of GetCheckRunsForSHA.
+func (mr *MockAPIMockRecorder) GetCheckRunsForSHA(sha any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCheckRunsForSHA", reflect.TypeOf((*MockAPI)(nil).GetCheckRunsForSHA), sha)
 }
 
 // GetGitHubClient mocks base method.
@@ -321,6 +366,20 @@ func (mr *MockAPIMockRecorder) ScheduleResultsProcessing(sha, browser any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleResultsProcessing", reflect.TypeOf((*MockAPI)(nil).ScheduleResultsProcessing), sha, browser)
 }
 
+// ScheduleRetry mocks base method.
+func (m *MockAPI) ScheduleRetry(sha string, browser shared.ProductSpec) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRetry", sha, browser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleRetry indicates an expected call of ScheduleRetry.
+func (mr *MockAPIMockRecorder) ScheduleRetry(sha, browser any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRetry", reflect.TypeOf((*MockAPI)(nil).ScheduleRetry), sha, browser)
+}
+
 // ScheduleTask mocks base method.
 func (m *MockAPI) ScheduleTask(queueName, taskName, target string, params url.Values) (string, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,161 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/web-platform-tests/wpt.fyi/api/checks/summaries"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"gopkg.in/yaml.v3"
+)
+
+// metaFileName is the name of the files describing the directories of the wpt
+// repo, including their suggested reviewers.
+const metaFileName = "META.yml"
+
+type metaFile struct {
+	SuggestedReviewers []string `yaml:"suggested_reviewers"`
+}
+
+// ownersLoader loads the owners of the directories of a wpt repo at a SHA,
+// i.e. the suggested_reviewers of their META.yml files, caching them by
+// directory.
+type ownersLoader struct {
+	ctx    context.Context // nolint:containedctx // TODO: Fix containedctx lint error
+	client *github.Client
+	owner  string
+	repo   string
+	sha    string
+	owners map[string][]string
+}
+
+func newOwnersLoader(ctx context.Context, client *github.Client, owner, repo, sha string) *ownersLoader {
+	return &ownersLoader{
+		ctx:    ctx,
+		client: client,
+		owner:  owner,
+		repo:   repo,
+		sha:    sha,
+		owners: make(map[string][]string),
+	}
+}
+
+// getTestOwners returns the suggested reviewers of the nearest META.yml file,
+// which has any, in the directory of the test or its ancestors.
+func (l *ownersLoader) getTestOwners(test string) ([]string, error) {
+	dir := path.Dir(testFilePath(test))
+	for {
+		owners, err := l.getDirOwners(dir)
+		if err != nil || len(owners) > 0 || dir == "." {
+			return owners, err
+		}
+		dir = path.Dir(dir)
+	}
+}
+
+func (l *ownersLoader) getDirOwners(dir string) ([]string, error) {
+	if owners, ok := l.owners[dir]; ok {
+		return owners, nil
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	opts := github.RepositoryContentGetOptions{Ref: l.sha}
+	file, _, _, err := l.client.Repositories.GetContents(
+		l.ctx, l.owner, l.repo, path.Join(dir, metaFileName), &opts)
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound {
+		// Most directories don't have a META.yml file.
+		l.owners[dir] = nil
+
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, err
+	}
+	var meta metaFile
+	if err := yaml.Unmarshal([]byte(content), &meta); err != nil {
+		return nil, err
+	}
+	l.owners[dir] = meta.SuggestedReviewers
+
+	return meta.SuggestedReviewers, nil
+}
+
+// getRegressionsByOwners groups the regressed tests by the owners of their
+// directories in the repo at the given SHA, or returns nil if none of them
+// have owners.
+func getRegressionsByOwners(
+	aeAPI shared.AppEngineAPI,
+	checkRepo shared.CheckRepo,
+	sha string,
+	tests []string,
+) ([]summaries.OwnedRegressions, error) {
+	client, err := getRepoGitHubClient(aeAPI, checkRepo)
+	if err != nil {
+		return nil, err
+	}
+	grouped, err := groupTestsByOwners(newOwnersLoader(aeAPI.Context(), client, checkRepo.Owner, checkRepo.Repo, sha), tests)
+	if err != nil || len(grouped) == 0 || len(grouped[0].Owners) == 0 {
+		return nil, err
+	}
+
+	return grouped, nil
+}
+
+// getRepoGitHubClient returns a client of the installation of the repo's app,
+// which can read private repos, or the bot's client if the repo has none.
+func getRepoGitHubClient(aeAPI shared.AppEngineAPI, checkRepo shared.CheckRepo) (*github.Client, error) {
+	if checkRepo.InstallationID != 0 {
+		return getGitHubClient(aeAPI.Context(), checkRepo.AppID, checkRepo.InstallationID)
+	}
+
+	return aeAPI.GetGitHubClient()
+}
+
+// groupTestsByOwners groups the tests by their owners, sorted by owners, with
+// the tests without owners last.
+func groupTestsByOwners(loader *ownersLoader, tests []string) ([]summaries.OwnedRegressions, error) {
+	groups := make(map[string]*summaries.OwnedRegressions)
+	for _, test := range tests {
+		owners, err := loader.getTestOwners(test)
+		if err != nil {
+			return nil, err
+		}
+		owners = append([]string{}, owners...)
+		sort.Strings(owners)
+		key := strings.Join(owners, ",")
+		if _, ok := groups[key]; !ok {
+			groups[key] = &summaries.OwnedRegressions{Owners: owners, Tests: nil}
+		}
+		groups[key].Tests = append(groups[key].Tests, test)
+	}
+
+	keys, _ := shared.MapStringKeys(groups)
+	sort.Slice(keys, func(i, j int) bool {
+		// The empty key, i.e. the tests without owners, goes last.
+		if keys[i] == "" || keys[j] == "" {
+			return keys[j] == ""
+		}
+
+		return keys[i] < keys[j]
+	})
+	grouped := make([]summaries.OwnedRegressions, 0, len(keys))
+	for _, key := range keys {
+		group := groups[key]
+		sort.Strings(group.Tests)
+		grouped = append(grouped, *group)
+	}
+
+	return grouped, nil
+}
@@ -0,0 +1,114 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/checks/summaries"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+// newMetaFilesClient returns a GitHub client for a server which has the given
+// META.yml files of the wpt repo, and the number of requests it received.
+func newMetaFilesClient(t *testing.T, sha string, files map[string]string) (*github.Client, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, sha, r.URL.Query().Get("ref"))
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+		encoded := base64.StdEncoding.EncodeToString([]byte(content))
+		_, _ = w.Write([]byte(`{"type": "file", "encoding": "base64", "content": "` + encoded + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	baseURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&baseURL, nil))
+	assert.Nil(t, err)
+
+	return client, &requests
+}
+
+func TestGetTestOwners(t *testing.T) {
+	sha := "0123456789012345678901234567890123456789"
+	client, requests := newMetaFilesClient(t, sha, map[string]string{
+		"/repos/web-platform-tests/wpt/contents/css/META.yml":      "spec: https://drafts.csswg.org/\nsuggested_reviewers:\n  - alice\n  - bob\n",
+		"/repos/web-platform-tests/wpt/contents/css/grid/META.yml": "spec: https://drafts.csswg.org/css-grid/\n",
+	})
+	loader := newOwnersLoader(context.Background(), client, shared.WPTRepoOwner, shared.WPTRepoName, sha)
+
+	// css/grid/META.yml has no suggested reviewers, so css/META.yml is used.
+	owners, err := loader.getTestOwners("/css/grid/foo.any.worker.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, owners)
+	assert.Equal(t, 2, *requests)
+
+	// Owners are cached by directory.
+	owners, err = loader.getTestOwners("/css/grid/bar.html")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, owners)
+	assert.Equal(t, 2, *requests)
+
+	// dom/nodes/META.yml, dom/META.yml and META.yml don't exist.
+	owners, err = loader.getTestOwners("/dom/nodes/baz.html")
+	assert.Nil(t, err)
+	assert.Empty(t, owners)
+	assert.Equal(t, 5, *requests)
+}
+
+func TestGroupTestsByOwners(t *testing.T) {
+	sha := "0123456789012345678901234567890123456789"
+	client, _ := newMetaFilesClient(t, sha, map[string]string{
+		"/repos/web-platform-tests/wpt/contents/css/META.yml":  "suggested_reviewers:\n  - bob\n  - alice\n",
+		"/repos/web-platform-tests/wpt/contents/html/META.yml": "suggested_reviewers:\n  - alice\n",
+	})
+	loader := newOwnersLoader(context.Background(), client, shared.WPTRepoOwner, shared.WPTRepoName, sha)
+
+	grouped, err := groupTestsByOwners(loader, []string{"/dom/a.html", "/css/b.html", "/html/c.html", "/css/a.html"})
+	assert.Nil(t, err)
+	assert.Equal(t, []summaries.OwnedRegressions{
+		{Owners: []string{"alice"}, Tests: []string{"/html/c.html"}},
+		{Owners: []string{"alice", "bob"}, Tests: []string{"/css/a.html", "/css/b.html"}},
+		{Owners: []string{}, Tests: []string{"/dom/a.html"}},
+	}, grouped)
+}
+
+func TestGetRegressionsByOwners(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha := "0123456789012345678901234567890123456789"
+	client, _ := newMetaFilesClient(t, sha, map[string]string{
+		"/repos/web-platform-tests/wpt/contents/css/META.yml": "suggested_reviewers:\n  - alice\n",
+	})
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().GetGitHubClient().AnyTimes().Return(client, nil)
+
+	checkRepo := shared.CheckRepo{Owner: shared.WPTRepoOwner, Repo: shared.WPTRepoName} // nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	grouped, err := getRegressionsByOwners(aeAPI, checkRepo, sha, []string{"/css/a.html", "/dom/b.html"})
+	assert.Nil(t, err)
+	assert.Len(t, grouped, 2)
+
+	// None of the tests have owners.
+	grouped, err = getRegressionsByOwners(aeAPI, checkRepo, sha, []string{"/dom/b.html"})
+	assert.Nil(t, err)
+	assert.Nil(t, grouped)
+}
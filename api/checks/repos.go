@@ -0,0 +1,87 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"errors"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// CheckRepoKind is the Datastore kind of the entities which configure the
+// checks of GitHub repos other than wpt, e.g. downstream browser forks of
+// wpt, keyed by the full name of the repo (owner/name).
+const CheckRepoKind = "CheckRepo"
+
+// LoadCheckRepo loads the CheckRepo entity of the given repo, or returns nil
+// if the repo isn't configured.
+func LoadCheckRepo(store shared.Datastore, owner, repo string) (*shared.CheckRepo, error) {
+	var checkRepo shared.CheckRepo
+	err := store.Get(store.NewNameKey(CheckRepoKind, owner+"/"+repo), &checkRepo)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &checkRepo, nil
+}
+
+// getSuiteCheckRepo returns the config of the checks of the repo of the suite.
+// The forks of wpt contributors aren't configured, and are checked as wpt.
+func getSuiteCheckRepo(api API, suite shared.CheckSuite) (*shared.CheckRepo, error) {
+	checkRepo, err := api.GetCheckRepo(suite.Owner, suite.Repo)
+	if err != nil || checkRepo != nil {
+		return checkRepo, err
+	}
+
+	return api.GetCheckRepo(shared.WPTRepoOwner, shared.WPTRepoName)
+}
+
+// checksRun returns whether the checks of the repo check the run, i.e. it's a
+// run of the repo, and of one of its checked products.
+func checksRun(checkRepo shared.CheckRepo, run shared.TestRun) bool {
+	return checkRepo.RepoLabel() == run.RepoLabel() && checkRepo.ChecksRun(run)
+}
+
+// getRunMasterLabel returns the label of the master runs of the repo of the
+// run.
+func getRunMasterLabel(run shared.TestRun) string {
+	if run.RepoLabel() != "" {
+		return shared.RepoMasterLabel
+	}
+
+	return shared.MasterLabel
+}
+
+// withRunRepoLabel returns a set of the given labels and the label of the
+// repo of the run, if any.
+func withRunRepoLabel(run shared.TestRun, labels ...string) mapset.Set { // nolint:ireturn // TODO: Fix ireturn lint error
+	set := mapset.NewSet()
+	for _, label := range labels {
+		set.Add(label)
+	}
+	if repoLabel := run.RepoLabel(); repoLabel != "" {
+		set.Add(repoLabel)
+	}
+
+	return set
+}
+
+// withRepoLabel returns the product spec with the label of the repo, if it
+// isn't wpt, so that only the runs of the repo match it.
+func withRepoLabel(checkRepo *shared.CheckRepo, spec shared.ProductSpec) shared.ProductSpec {
+	if checkRepo == nil || checkRepo.RepoLabel() == "" {
+		return spec
+	}
+	labels := mapset.NewSet(checkRepo.RepoLabel())
+	if spec.Labels != nil {
+		labels = labels.Union(spec.Labels)
+	}
+	spec.Labels = labels
+
+	return spec
+}
@@ -0,0 +1,97 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+func getForkCheckRepo() shared.CheckRepo {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.CheckRepo{
+		Owner:        "browser",
+		Repo:         "wpt-fork",
+		AppID:        123,
+		Products:     []string{"chrome[experimental]"},
+		MasterBranch: "main",
+	}
+}
+
+func TestCheckRepo_Labels(t *testing.T) {
+	wpt := getWPTCheckRepo()
+	assert.True(t, wpt.IsWPT())
+	assert.Equal(t, "", wpt.RepoLabel())
+	assert.Equal(t, shared.MasterLabel, wpt.MasterLabel())
+	assert.Equal(t, "master", wpt.GetMasterBranch())
+
+	fork := getForkCheckRepo()
+	assert.False(t, fork.IsWPT())
+	assert.Equal(t, "repo:browser/wpt-fork", fork.RepoLabel())
+	assert.Equal(t, shared.RepoMasterLabel, fork.MasterLabel())
+	assert.Equal(t, "main", fork.GetMasterBranch())
+}
+
+func TestChecksRun(t *testing.T) {
+	wpt := getWPTCheckRepo()
+	fork := getForkCheckRepo()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.TestRun{
+		ProductAtRevision: shared.ProductAtRevision{
+			Product: shared.Product{BrowserName: "chrome"},
+		},
+		Labels: []string{shared.PRHeadLabel, shared.ExperimentalLabel},
+	}
+	assert.True(t, checksRun(wpt, run))
+	assert.False(t, checksRun(fork, run))
+
+	run.Labels = append(run.Labels, fork.RepoLabel())
+	assert.False(t, checksRun(wpt, run))
+	assert.True(t, checksRun(fork, run))
+
+	// Only the configured products of the fork are checked.
+	run.BrowserName = "firefox"
+	assert.False(t, checksRun(fork, run))
+}
+
+func TestGetRunMasterLabel(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.TestRun{Labels: []string{shared.MasterLabel}}
+	assert.Equal(t, shared.MasterLabel, getRunMasterLabel(run))
+	assert.Equal(t, []interface{}{shared.PRBaseLabel}, withRunRepoLabel(run, shared.PRBaseLabel).ToSlice())
+
+	run.Labels = []string{shared.RepoMasterLabel, "repo:browser/wpt-fork"}
+	assert.Equal(t, shared.RepoMasterLabel, getRunMasterLabel(run))
+	labels := withRunRepoLabel(run, shared.PRBaseLabel)
+	assert.Equal(t, 2, labels.Cardinality())
+	assert.True(t, labels.Contains(shared.PRBaseLabel, "repo:browser/wpt-fork"))
+}
+
+func TestIsCheckApp(t *testing.T) {
+	fork := getForkCheckRepo()
+	assert.True(t, isCheckApp(nil, wptfyiCheckAppID))
+	assert.True(t, isCheckApp(&fork, wptfyiStagingCheckAppID))
+	assert.True(t, isCheckApp(&fork, 123))
+	assert.False(t, isCheckApp(&fork, 456))
+	assert.False(t, isCheckApp(nil, 123))
+}
+
+func TestWithRepoLabel(t *testing.T) {
+	wpt := getWPTCheckRepo()
+	fork := getForkCheckRepo()
+	spec := shared.ParseProductSpecUnsafe("chrome[experimental]")
+
+	assert.Equal(t, spec, withRepoLabel(nil, spec))
+	assert.Equal(t, spec, withRepoLabel(&wpt, spec))
+	assert.Equal(t, "chrome[experimental,repo:browser/wpt-fork]", withRepoLabel(&fork, spec).String())
+	// The labels of the spec aren't modified.
+	assert.Equal(t, "chrome[experimental]", spec.String())
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// RetryEventType is the event_type of the repository_dispatch events sent to
+// the checked repo (e.g. wpt) to re-run the regressed tests of a PR.
+const RetryEventType = "wpt-fyi-retry"
+
+// maxRetriedTests is the maximum number of regressed tests which are re-run
+// by a single retry.
+const maxRetriedTests = 100
+
+// minRetryInterval is the minimum time between the retries of a product at a
+// SHA, which is about as long as re-running the tests takes; retries requested
+// sooner are dropped.
+const minRetryInterval = 30 * time.Minute
+
+// checkRetry records when the retry of a product at a SHA (see retryKeyName)
+// was last requested.
+type checkRetry struct {
+	Requested time.Time
+}
+
+// retryKeyName returns the name of the CheckRetry key of a product at a SHA.
+func retryKeyName(sha, product string) string {
+	return sha + "/" + product
+}
+
+// retryPayload is the client_payload of a retry repository_dispatch event.
+// The workflow handling it is expected to run the tests of the product at the
+// SHA, and upload the results with the given label.
+type retryPayload struct {
+	SHA       string   `json:"sha"`
+	Product   string   `json:"product"`
+	Browser   string   `json:"browser"`
+	Channel   string   `json:"channel"`
+	Label     string   `json:"label"`
+	PRNumbers []int    `json:"pr_numbers"`
+	Tests     []string `json:"tests"`
+}
+
+// retryCheckHandler handles /api/checks/[commit]/retry POST requests, by
+// requesting a re-run of the regressed tests of the product at the commit.
+func retryCheckHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := shared.GetLogger(ctx)
+
+	// Retries dispatch workflows in the checked repo, so they can only be
+	// requested through the retry actions of check runs.
+	if r.Header.Get(shared.QueueNameHeader) != CheckProcessingQueue {
+		http.Error(w, "Retries can only be requested from the "+CheckProcessingQueue+" queue", http.StatusForbidden)
+
+		return
+	}
+
+	sha, err := shared.ParseSHA(mux.Vars(r)["commit"])
+	if err != nil {
+		log.Warningf("Failed to parse commit: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		log.Warningf("Failed to parse form: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	filter, err := shared.ParseTestRunFilterParams(r.Form)
+	if err != nil {
+		log.Warningf("Failed to parse params: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	} else if len(filter.Products) != 1 {
+		msg := "product param is missing"
+		log.Warningf("%s", msg)
+		http.Error(w, msg, http.StatusBadRequest)
+
+		return
+	}
+
+	filter.SHAs = shared.SHAs{sha}
+	headRun, baseRun, err := loadRunsToCompare(ctx, filter)
+	if err != nil {
+		msg := "Could not find runs to compare: " + err.Error()
+		log.Warningf("%s", msg)
+		http.Error(w, msg, http.StatusNotFound)
+
+		return
+	} else if !headRun.LabelsSet().Contains(shared.PRHeadLabel) {
+		msg := fmt.Sprintf("Test run %d isn't a pr_head run", headRun.ID)
+		log.Warningf("%s", msg)
+		http.Error(w, msg, http.StatusBadRequest)
+
+		return
+	}
+
+	tests, err := getRetryTests(shared.NewDiffAPI(ctx), *baseRun, *headRun)
+	if err != nil {
+		log.Errorf("Failed to load regressions of %s: %s", filter.Products[0].String(), err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	} else if len(tests) < 1 {
+		_, err = w.Write([]byte("No regressions to retry"))
+		if err != nil {
+			log.Warningf("Failed to write data in api/checks/retry handler: %s", err.Error())
+		}
+
+		return
+	}
+
+	api := NewAPI(ctx)
+	suites, err := api.GetSuitesForSHA(headRun.FullRevisionHash)
+	if err != nil {
+		log.Warningf("Failed to load CheckSuites for %s: %s", sha, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	payload := retryPayload{
+		SHA:     headRun.FullRevisionHash,
+		Product: filter.Products[0].String(),
+		Browser: headRun.BrowserName,
+		Channel: headRun.Channel(),
+		Label:   shared.PRRetryLabel,
+		Tests:   tests,
+	}
+	// The tests are re-run in the repo whose suites check the run.
+	var checkRepo *shared.CheckRepo
+	for _, suite := range suites {
+		suiteRepo, err := getSuiteCheckRepo(api, suite)
+		if err != nil {
+			log.Errorf("Failed to load the config of %s/%s: %s", suite.Owner, suite.Repo, err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+
+			return
+		} else if checksRun(*suiteRepo, *headRun) {
+			checkRepo = suiteRepo
+			payload.PRNumbers = append(payload.PRNumbers, suite.PRNumbers...)
+		}
+	}
+	if checkRepo == nil {
+		msg := fmt.Sprintf("No check suites for run %d", headRun.ID)
+		log.Warningf("%s", msg)
+		http.Error(w, msg, http.StatusNotFound)
+
+		return
+	}
+
+	// Only dispatch once, from the app of the repo, even if the apps of other
+	// environments also have check suites for the SHA.
+	client, err := getGitHubClient(ctx, checkRepo.AppID, checkRepo.InstallationID)
+	if err != nil {
+		log.Errorf("Failed to get GitHub client: %s", err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	store := shared.NewAppEngineDatastore(ctx, false)
+	previous, err := reserveRetry(store, payload.SHA, payload.Product, time.Now())
+	var tooSoon retryTooSoonError
+	if errors.As(err, &tooSoon) {
+		// The request isn't retried by the queue.
+		log.Infof("Dropping retry of %s @ %s: %s", payload.Product, shared.CropString(sha, 7), err.Error())
+		_, err = w.Write([]byte(err.Error()))
+		if err != nil {
+			log.Warningf("Failed to write data in api/checks/retry handler: %s", err.Error())
+		}
+
+		return
+	} else if err != nil {
+		log.Errorf("Failed to reserve retry of %s @ %s: %s", payload.Product, shared.CropString(sha, 7), err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	if err := dispatchRetry(ctx, client, *checkRepo, payload); err != nil {
+		log.Errorf("Failed to dispatch retry of %s @ %s: %s", payload.Product, shared.CropString(sha, 7), err.Error())
+		// Let the queue retry the request.
+		if err := releaseRetry(store, payload.SHA, payload.Product, previous); err != nil {
+			log.Errorf("Failed to release retry of %s @ %s: %s", payload.Product, shared.CropString(sha, 7), err.Error())
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	log.Infof("Dispatched retry of %d tests for %s @ %s", len(tests), payload.Product, shared.CropString(sha, 7))
+	_, err = fmt.Fprintf(w, "Retry of %d test(s) requested", len(tests))
+	if err != nil {
+		log.Warningf("Failed to write data in api/checks/retry handler: %s", err.Error())
+	}
+}
+
+// retryTooSoonError is returned by reserveRetry when the last retry of the
+// product at the SHA was requested less than minRetryInterval ago.
+type retryTooSoonError struct {
+	requested time.Time
+}
+
+func (e retryTooSoonError) Error() string {
+	return fmt.Sprintf("Retry was already requested at %s", e.requested.UTC().Format(time.RFC3339))
+}
+
+// reserveRetry records that a retry of the product at the SHA is requested at
+// the given time, unless the last one was requested less than
+// minRetryInterval before, in which case it returns a retryTooSoonError. It
+// returns when the previous retry was requested, if ever.
+func reserveRetry(store shared.Datastore, sha, product string, now time.Time) (time.Time, error) {
+	var previous time.Time
+	var retry checkRetry
+	err := store.Update(store.NewNameKey("CheckRetry", retryKeyName(sha, product)), &retry, func(obj interface{}) error {
+		retry := obj.(*checkRetry)
+		previous = retry.Requested
+		if now.Sub(retry.Requested) < minRetryInterval {
+			return retryTooSoonError{requested: retry.Requested}
+		}
+		retry.Requested = now
+
+		return nil
+	})
+
+	return previous, err
+}
+
+// releaseRetry restores the time the retry of the product at the SHA was
+// requested at, before reserveRetry, when the reserved retry failed.
+func releaseRetry(store shared.Datastore, sha, product string, previous time.Time) error {
+	var retry checkRetry
+	return store.Update(store.NewNameKey("CheckRetry", retryKeyName(sha, product)), &retry, func(obj interface{}) error {
+		obj.(*checkRetry).Requested = previous
+
+		return nil
+	})
+}
+
+// getRetryTests returns the tests which regressed between the runs, sorted,
+// and truncated to at most maxRetriedTests.
+func getRetryTests(diffAPI shared.DiffAPI, baseRun, headRun shared.TestRun) ([]string, error) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+	diff, err := diffAPI.GetRunsDiff(baseRun, headRun, shared.DiffFilterParam{Changed: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	tests := shared.ToStringSlice(diff.Differences.Regressions())
+	sort.Strings(tests)
+	if len(tests) > maxRetriedTests {
+		tests = tests[:maxRetriedTests]
+	}
+
+	return tests, nil
+}
+
+// dispatchRetry sends a repository_dispatch event to the repo, which triggers
+// the workflow re-running the tests of the payload.
+func dispatchRetry(ctx context.Context, client *github.Client, repo shared.CheckRepo, payload retryPayload) error {
+	marshalled, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	clientPayload := json.RawMessage(marshalled)
+	opts := github.DispatchRequestOptions{
+		EventType:     RetryEventType,
+		ClientPayload: &clientPayload,
+	}
+	_, _, err = client.Repositories.Dispatch(ctx, repo.Owner, repo.Repo, opts)
+
+	return err
+}
@@ -0,0 +1,49 @@
+//go:build medium
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestReserveRetry(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	sha := strings.Repeat("abcdef012345", 4)
+	now := time.Now().UTC().Truncate(time.Second)
+	previous, err := reserveRetry(store, sha, "chrome[experimental]", now)
+	assert.Nil(t, err)
+	assert.True(t, previous.IsZero())
+
+	// Retries of the same product at the SHA are rate limited.
+	var tooSoon retryTooSoonError
+	_, err = reserveRetry(store, sha, "chrome[experimental]", now.Add(time.Minute))
+	assert.ErrorAs(t, err, &tooSoon)
+	// Those of other products aren't.
+	_, err = reserveRetry(store, sha, "firefox[experimental]", now.Add(time.Minute))
+	assert.Nil(t, err)
+
+	previous, err = reserveRetry(store, sha, "chrome[experimental]", now.Add(minRetryInterval))
+	assert.Nil(t, err)
+	assert.True(t, now.Equal(previous))
+
+	// A failed retry is released, so that it can be retried straight away.
+	assert.Nil(t, releaseRetry(store, sha, "chrome[experimental]", previous))
+	_, err = reserveRetry(store, sha, "chrome[experimental]", now.Add(minRetryInterval))
+	assert.Nil(t, err)
+	_, err = reserveRetry(store, sha, "chrome[experimental]", now.Add(minRetryInterval+time.Minute))
+	assert.ErrorAs(t, err, &tooSoon)
+}
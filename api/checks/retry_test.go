@@ -0,0 +1,89 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestGetRetryTests(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	before, after := getBeforeAndAfterRuns()
+	differences := shared.ResultsDiff{
+		"/fixed.html": shared.TestDiff{1, 0, 0},
+		"/b.html":     shared.TestDiff{0, 1, 0},
+		"/a.html":     shared.TestDiff{0, 0, -1},
+	}
+	for i := 0; i < maxRetriedTests; i++ {
+		differences[fmt.Sprintf("/more/%03d.html", i)] = shared.TestDiff{0, 1, 0}
+	}
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), nil).Return(
+		shared.RunDiff{Differences: differences}, nil)
+
+	tests, err := getRetryTests(diffAPI, before, after)
+	assert.Nil(t, err)
+	assert.Len(t, tests, maxRetriedTests)
+	assert.Equal(t, []string{"/a.html", "/b.html", "/more/000.html"}, tests[:3])
+	assert.NotContains(t, tests, "/fixed.html")
+}
+
+func TestRetryCheckHandler_notFromQueue(t *testing.T) {
+	for _, queue := range []string{"", "results-arrival"} {
+		r := httptest.NewRequest(http.MethodPost, "/api/checks/0123456789/retry?product=chrome", nil)
+		if queue != "" {
+			r.Header.Set(shared.QueueNameHeader, queue)
+		}
+		w := httptest.NewRecorder()
+		retryCheckHandler(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	}
+}
+
+func TestDispatchRetry(t *testing.T) {
+	var event struct {
+		EventType     string       `json:"event_type"`
+		ClientPayload retryPayload `json:"client_payload"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/browser/wpt-fork/dispatches", r.URL.Path)
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&event))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	baseURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&baseURL, nil))
+	assert.Nil(t, err)
+
+	repo := shared.CheckRepo{Owner: "browser", Repo: "wpt-fork"} // nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	payload := retryPayload{
+		SHA:       "0123456789012345678901234567890123456789",
+		Product:   "chrome[experimental]",
+		Browser:   "chrome",
+		Channel:   shared.ExperimentalLabel,
+		Label:     shared.PRRetryLabel,
+		PRNumbers: []int{123},
+		Tests:     []string{"/a.html", "/b.html"},
+	}
+	assert.Nil(t, dispatchRetry(context.Background(), client, repo, payload))
+	assert.Equal(t, RetryEventType, event.EventType)
+	assert.Equal(t, payload, event.ClientPayload)
+}
@@ -14,4 +14,9 @@ func RegisterRoutes() {
 	// Endpoint for computing outcome and updating any checks for the given commit.
 	// When scheduling updates, we call this endpoint from the check-processing TaskQueue.
 	shared.AddRoute("/api/checks/{commit}", "checks-updater", updateCheckHandler)
+
+	// Endpoint for requesting a re-run of the regressed tests for the given commit.
+	// We call this endpoint from the check-processing TaskQueue, when the retry
+	// action of a check run is requested; it only accepts requests from there.
+	shared.AddRoute("/api/checks/{commit}/retry", "checks-retry", retryCheckHandler).Methods("POST")
 }
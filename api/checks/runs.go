@@ -70,8 +70,9 @@ func updateCheckRunSummary(ctx context.Context, summary summaries.Summary, suite
 			Status:     &state.Status,
 			Conclusion: state.Conclusion,
 			Output: &github.CheckRunOutput{
-				Title:   &title,
-				Summary: &summaryStr,
+				Title:       &title,
+				Summary:     &summaryStr,
+				Annotations: state.Annotations,
 			},
 			Actions: actions,
 		}
@@ -152,6 +153,18 @@ func updateExistingCheckRunSummary(
 		return false, err
 	}
 
+	client, err := getGitHubClient(ctx, suite.AppID, suite.InstallationID)
+	if err != nil {
+		return false, err
+	}
+
+	annotations, err := filterExistingAnnotations(ctx, client, suite, run.GetID(), state.Annotations)
+	if err != nil {
+		log.Warningf("Failed to load existing annotations of run %v: %s", run.GetID(), err.Error())
+		annotations = state.Annotations
+	}
+	batches := batchAnnotations(annotations)
+
 	detailsURLStr := state.DetailsURL.String()
 	title := state.Title()
 	// nolint:exhaustruct // WONTFIX: Name, HeadSHA only required.
@@ -169,10 +182,8 @@ func updateExistingCheckRunSummary(
 	if state.Conclusion != nil {
 		opts.CompletedAt = &github.Timestamp{Time: time.Now()}
 	}
-
-	client, err := getGitHubClient(ctx, suite.AppID, suite.InstallationID)
-	if err != nil {
-		return false, err
+	if len(batches) > 0 {
+		opts.Output.Annotations = batches[0]
 	}
 
 	_, _, err = client.Checks.UpdateCheckRun(ctx, suite.Owner, suite.Repo, run.GetID(), opts)
@@ -181,6 +192,12 @@ func updateExistingCheckRunSummary(
 
 		return false, err
 	}
+	if len(batches) > 1 {
+		err = addCheckRunAnnotations(ctx, client, suite, run.GetID(), opts.Name, title, summaryStr, batches[1:])
+		if err != nil {
+			log.Warningf("Failed to annotate run %v: %s", run.GetID(), err.Error())
+		}
+	}
 
-	return true, err
+	return true, nil
 }
@@ -17,6 +17,16 @@ func RecomputeAction() *github.CheckRunAction {
 	}
 }
 
+// RetryAction is an action that can be taken to re-run
+// only the regressed tests, to find out which are flaky.
+func RetryAction() *github.CheckRunAction {
+	return &github.CheckRunAction{
+		Identifier:  "retry",
+		Label:       "Retry regressions",
+		Description: "Re-run only the regressed tests",
+	}
+}
+
 // IgnoreAction is an action that can be taken to ignore a fail
 // outcome, marking it as passing.
 func IgnoreAction() *github.CheckRunAction {
@@ -17,6 +17,7 @@ import (
 func TestActionCharacterLimits(t *testing.T) {
 	actions := []*github.CheckRunAction{
 		RecomputeAction(),
+		RetryAction(),
 		IgnoreAction(),
 		CancelAction(),
 	}
@@ -66,6 +66,8 @@ type CheckState struct {
 	Conclusion *string
 	Actions    []github.CheckRunAction
 	PRNumbers  []int
+	// Annotations of the files of the tests, e.g. regressions. (Optional.)
+	Annotations []*github.CheckRunAnnotation
 }
 
 // Name returns the check run's name, based on the product.
@@ -77,6 +77,18 @@ func TestGetSummary_Completed(t *testing.T) {
 	assert.Contains(t, s, escapeMD(testName))
 	assert.Contains(t, s, "https://foo.com/runs/?pr=123")
 	assert.Contains(t, s, "https://foo.com/results/?pr=123")
+
+	// With another master branch
+	assert.Contains(t, s, "Test | `master` |")
+	foo.MasterBranch = "main"
+	s, err = foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "Test | `main` |")
+	assert.Contains(t, s, "vs latest main")
+	assert.NotContains(t, s, "`master`")
 }
 
 func TestGetSummary_Pending(t *testing.T) {
@@ -155,6 +167,115 @@ func TestGetSummary_Regressed(t *testing.T) {
 	assert.Contains(t, s, "https://foo.com/results/?pr=123")
 }
 
+func TestGetSummary_Regressed_Flaky(t *testing.T) {
+	master := shared.TestRun{}
+	master.BrowserName = "chrome"
+	master.Revision = "abcdef0123"
+	master.FullRevisionHash = strings.Repeat(master.Revision, 4)
+	pr := shared.TestRun{}
+	pr.BrowserName = "chrome"
+	pr.Revision = "0123456789"
+	pr.FullRevisionHash = strings.Repeat(pr.Revision, 4)
+	foo := Regressed{}
+	foo.BaseRun = master
+	foo.HeadRun = pr
+	foo.HostName = "foo.com"
+	foo.HostURL = "https://foo.com/"
+	foo.DiffURL = "https://foo.com/?products=chrome@0000000000,chrome@0123456789&diff"
+	foo.HistoryRuns = 10
+	foo.FlakyRegressions = BeforeAndAfter{
+		"/flaky.html": TestBeforeAndAfter{
+			PassingBefore: 3,
+			TotalBefore:   3,
+			PassingAfter:  2,
+			TotalAfter:    3,
+		},
+	}
+	foo.MoreFlaky = 2
+
+	s, err := foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "they are all known to be flaky")
+	assert.NotContains(t, s, "### Regressions")
+	assert.Contains(t, s, "<details>")
+	assert.Contains(t, s, "the last 10 runs against `master`")
+	assert.Contains(t, s, "/flaky.html | 3 / 3 | 2 / 3")
+	assert.Contains(t, s, "And 2 others...")
+
+	foo.Regressions = BeforeAndAfter{
+		"/real.html": TestBeforeAndAfter{
+			PassingBefore: 1,
+			TotalBefore:   1,
+			PassingAfter:  0,
+			TotalAfter:    1,
+		},
+	}
+	s, err = foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "Uh-oh")
+	assert.Contains(t, s, "### Regressions")
+	assert.Contains(t, s, "/real.html | 1 / 1 | 0 / 1")
+	assert.Contains(t, s, "/flaky.html | 3 / 3 | 2 / 3")
+	assert.NotContains(t, s, "retry")
+
+	retry := pr
+	retry.Labels = []string{shared.PRRetryLabel}
+	foo.RetryRun = &retry
+	s, err = foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "`0123456` | chrome@0123456789\n`0123456` (retry) | "+retry.String())
+	assert.Contains(t, s, "or didn't regress when they were retried")
+}
+
+func TestGetSummary_Regressed_ByOwners(t *testing.T) {
+	pr := shared.TestRun{}
+	pr.BrowserName = "chrome"
+	pr.Revision = "0123456789"
+	pr.FullRevisionHash = strings.Repeat(pr.Revision, 4)
+	foo := Regressed{}
+	foo.HeadRun = pr
+	foo.HostName = "foo.com"
+	foo.HostURL = "https://foo.com/"
+	foo.DiffURL = "https://foo.com/?products=chrome@0000000000,chrome@0123456789&diff"
+	foo.Regressions = BeforeAndAfter{
+		"/css/a.html": TestBeforeAndAfter{PassingBefore: 1, TotalBefore: 1, PassingAfter: 0, TotalAfter: 1},
+		"/css/b.html": TestBeforeAndAfter{PassingBefore: 2, TotalBefore: 2, PassingAfter: 1, TotalAfter: 2},
+		"/dom/c.html": TestBeforeAndAfter{PassingBefore: 3, TotalBefore: 3, PassingAfter: 0, TotalAfter: 3},
+	}
+	foo.ByOwners = []OwnedRegressions{
+		{Owners: []string{"alice", "bob"}, Tests: []string{"/css/a.html", "/css/b.html"}},
+		{Owners: nil, Tests: []string{"/dom/c.html"}},
+	}
+
+	s, err := foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "#### Owned by `alice`, `bob`\n\nTest | `master` | `0123456`\n--- | --- | ---\n"+
+		"/css/a.html | 1 / 1 | 0 / 1\n/css/b.html | 2 / 2 | 1 / 2\n")
+	assert.Contains(t, s, "#### Without suggested reviewers\n\nTest | `master` | `0123456`\n--- | --- | ---\n"+
+		"/dom/c.html | 3 / 3 | 0 / 3\n")
+	assert.NotContains(t, s, "@alice")
+
+	foo.MentionOwners = true
+	s, err = foo.GetSummary()
+	printOutput(s)
+	if err != nil {
+		assert.FailNow(t, err.Error())
+	}
+	assert.Contains(t, s, "#### Owned by @alice, @bob\n")
+}
+
 func printOutput(s string) {
 	if *renderOutputToConsole {
 		log.Printf("MD output:\n-----------\n%s", s)
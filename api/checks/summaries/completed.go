@@ -17,6 +17,16 @@ type ResultsComparison struct {
 	MasterDiffURL string
 	DiffURL       string // URL for the diff-view of the results
 	HostURL       string // Host environment URL, e.g. "https://wpt.fyi"
+	MasterBranch  string // Branch of the repo the PR is compared to, if not master
+}
+
+// GetMasterBranch returns the branch of the repo the PR is compared to.
+func (r ResultsComparison) GetMasterBranch() string {
+	if r.MasterBranch == "" {
+		return "master"
+	}
+
+	return r.MasterBranch
 }
 
 // Completed is the struct for completed.md.
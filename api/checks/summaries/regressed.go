@@ -38,13 +38,32 @@ type TestBeforeAndAfter struct {
 	TotalAfter    int
 }
 
+// OwnedRegressions are the regressed tests of the directories with the same
+// owners, i.e. suggested reviewers in their META.yml files.
+type OwnedRegressions struct {
+	Owners []string
+	Tests  []string
+}
+
 // Regressed is the struct for regressed.md.
 type Regressed struct {
 	CheckState
 	ResultsComparison
 
+	// Regressions are the likely real regressions.
 	Regressions BeforeAndAfter
 	More        int
+	// ByOwners groups the Regressions by their owners, if they were loaded,
+	// which are @-mentioned if MentionOwners is set.
+	ByOwners      []OwnedRegressions
+	MentionOwners bool
+	// FlakyRegressions are the regressions of tests whose results changed
+	// between the HistoryRuns most recent master runs, or which didn't
+	// reproduce in the RetryRun, if any.
+	FlakyRegressions BeforeAndAfter
+	MoreFlaky        int
+	HistoryRuns      int
+	RetryRun         *shared.TestRun
 }
 
 // GetCheckState returns the info needed to update a check.
@@ -61,6 +80,7 @@ func (r Regressed) GetSummary() (string, error) {
 func (r Regressed) GetActions() []*github.CheckRunAction {
 	return []*github.CheckRunAction{
 		RecomputeAction(),
+		RetryAction(),
 		IgnoreAction(),
 	}
 }
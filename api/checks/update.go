@@ -26,6 +26,12 @@ const CheckProcessingQueue = "check-processing"
 
 const failChecksOnRegressionFeature = "failChecksOnRegression"
 
+const mentionOwnersFeature = "checksMentionOwners"
+
+// masterHistoryRuns is the number of recent master runs consulted to find the
+// regressed tests which are flaky, or unstable, on master.
+const masterHistoryRuns = 10
+
 // updateCheckHandler handles /api/checks/[commit] POST requests.
 func updateCheckHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -72,10 +78,21 @@ func updateCheckHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	historyRuns, err := loadMasterHistory(ctx, filter, headRun)
+	if err != nil {
+		log.Warningf("Failed to load master history for %s: %s", filter.Products[0].String(), err.Error())
+	}
+	// The history is diffed at most once, for all of the suites.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	history := &masterHistory{runs: historyRuns}
+
+	retryRun := loadRetryRun(ctx, filter, headRun)
+
 	sha = headRun.FullRevisionHash
 	aeAPI := shared.NewAppEngineAPI(ctx)
 	diffAPI := shared.NewDiffAPI(ctx)
-	suites, err := NewAPI(ctx).GetSuitesForSHA(sha)
+	api := NewAPI(ctx)
+	suites, err := api.GetSuitesForSHA(sha)
 	if err != nil {
 		log.Warningf("Failed to load CheckSuites for %s: %s", sha, err.Error())
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -87,8 +104,19 @@ func updateCheckHandler(w http.ResponseWriter, r *http.Request) {
 
 	updatedAny := false
 	for _, suite := range suites {
+		checkRepo, repoErr := getSuiteCheckRepo(api, suite)
+		if repoErr != nil {
+			log.Errorf("Failed to load the config of %s/%s: %s", suite.Owner, suite.Repo, repoErr.Error())
+			err = repoErr
+
+			continue
+		} else if !checksRun(*checkRepo, *headRun) {
+			log.Debugf("Run %d isn't checked in %s/%s", headRun.ID, suite.Owner, suite.Repo)
+
+			continue
+		}
 		var summaryData summaries.Summary
-		summaryData, err = getDiffSummary(aeAPI, diffAPI, suite, *baseRun, *headRun)
+		summaryData, err = getDiffSummary(aeAPI, diffAPI, suite, *checkRepo, *baseRun, *headRun, history, retryRun)
 		if errors.Is(err, shared.ErrRunNotInSearchCache) {
 			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 
@@ -145,28 +173,40 @@ func loadRunsToCompare(ctx context.Context, filter shared.TestRunFilter) (
 			shared.CropString(filter.SHAs.FirstOrLatest(), 7))
 	}
 
+	// The corresponding runs are of the same repo as the run.
 	labels := run.LabelsSet()
-	if labels.Contains(shared.MasterLabel) {
+	if labels.Contains(shared.PRRetryLabel) {
+		// The retry run only has the regressed tests, so it's compared separately.
+		headRun, err = loadPRRun(ctx, filter, *run, shared.PRHeadLabel)
+		if err == nil {
+			baseRun, err = loadPRRun(ctx, filter, *run, shared.PRBaseLabel)
+		}
+	} else if labels.Contains(getRunMasterLabel(*run)) {
 		headRun = run
 		baseRun, err = loadMasterRunBefore(ctx, filter, headRun)
 	} else if labels.Contains(shared.PRBaseLabel) {
 		baseRun = run
-		headRun, err = loadPRRun(ctx, filter, shared.PRHeadLabel)
+		headRun, err = loadPRRun(ctx, filter, *run, shared.PRHeadLabel)
 	} else if labels.Contains(shared.PRHeadLabel) {
 		headRun = run
-		baseRun, err = loadPRRun(ctx, filter, shared.PRBaseLabel)
+		baseRun, err = loadPRRun(ctx, filter, *run, shared.PRBaseLabel)
 	} else {
-		return nil, nil, fmt.Errorf("test run %d doesn't have pr_base, pr_head or master label", run.ID)
+		return nil, nil, fmt.Errorf("test run %d doesn't have pr_base, pr_head, pr_retry or master label", run.ID)
 	}
 
 	return headRun, baseRun, err
 }
 
-func loadPRRun(ctx context.Context, filter shared.TestRunFilter, extraLabel string) (*shared.TestRun, error) {
-	// Find the corresponding pr_base or pr_head run.
+func loadPRRun(
+	ctx context.Context,
+	filter shared.TestRunFilter,
+	repoRun shared.TestRun,
+	extraLabel string,
+) (*shared.TestRun, error) {
+	// Find the corresponding pr_base or pr_head run, of the repo of the run.
 	one := 1
 	store := shared.NewAppEngineDatastore(ctx, false)
-	labels := mapset.NewSetWith(extraLabel)
+	labels := withRunRepoLabel(repoRun, extraLabel)
 	runs, err := store.TestRunQuery().LoadTestRuns(
 		filter.Products,
 		labels,
@@ -197,7 +237,7 @@ func loadMasterRunBefore(
 	store := shared.NewAppEngineDatastore(ctx, false)
 	one := 1
 	to := headRun.TimeStart.Add(-time.Millisecond)
-	labels := mapset.NewSetWith(headRun.Channel(), shared.MasterLabel)
+	labels := withRunRepoLabel(*headRun, headRun.Channel(), getRunMasterLabel(*headRun))
 	runs, err := store.TestRunQuery().LoadTestRuns(filter.Products, labels, nil, nil, &to, &one, nil)
 	baseRun := runs.First()
 	if err != nil {
@@ -211,13 +251,135 @@ func loadMasterRunBefore(
 	return baseRun, err
 }
 
+// loadMasterHistory loads the most recent master runs of the product and
+// channel of the head run, before it, in chronological order.
+func loadMasterHistory(
+	ctx context.Context,
+	filter shared.TestRunFilter,
+	headRun *shared.TestRun,
+) (shared.TestRuns, error) {
+	store := shared.NewAppEngineDatastore(ctx, false)
+	limit := masterHistoryRuns
+	to := headRun.TimeStart.Add(-time.Millisecond)
+	labels := withRunRepoLabel(*headRun, headRun.Channel(), getRunMasterLabel(*headRun))
+	runs, err := store.TestRunQuery().LoadTestRuns(filter.Products, labels, nil, nil, &to, &limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	history := runs.AllRuns()
+	sort.Sort(history)
+
+	return history, nil
+}
+
+// loadRetryRun loads the latest pr_retry run of the PR head, if there is one
+// which is more recent than the head run.
+func loadRetryRun(ctx context.Context, filter shared.TestRunFilter, headRun *shared.TestRun) *shared.TestRun {
+	if !headRun.LabelsSet().Contains(shared.PRHeadLabel) {
+		return nil
+	}
+	retryRun, err := loadPRRun(ctx, filter, *headRun, shared.PRRetryLabel)
+	if err != nil || !retryRun.TimeStart.After(headRun.TimeStart) {
+		return nil
+	}
+
+	return retryRun
+}
+
+// masterHistory is the most recent master runs before a head run (see
+// loadMasterHistory), and the tests whose results changed between any two
+// consecutive runs of them, which are only diffed once they are needed, and
+// then only once for all of the check suites updated for the head run.
+type masterHistory struct {
+	runs    shared.TestRuns
+	changed mapset.Set
+}
+
+// len returns the number of runs of the history, which may be nil.
+func (h *masterHistory) len() int {
+	if h == nil {
+		return 0
+	}
+
+	return len(h.runs)
+}
+
+// getFlakyTests returns the given tests whose results changed between any two
+// consecutive runs of the master history, i.e. which are flaky, or unstable,
+// on master.
+func (h *masterHistory) getFlakyTests(
+	aeAPI shared.AppEngineAPI,
+	diffAPI shared.DiffAPI,
+	tests mapset.Set,
+) mapset.Set {
+	if h == nil {
+		return mapset.NewSet()
+	}
+	if h.changed == nil {
+		h.changed = getChangedTests(aeAPI, diffAPI, h.runs)
+	}
+
+	return h.changed.Intersect(tests)
+}
+
+// getChangedTests returns the tests whose results changed between any two
+// consecutive runs.
+func getChangedTests(aeAPI shared.AppEngineAPI, diffAPI shared.DiffAPI, runs shared.TestRuns) mapset.Set {
+	log := shared.GetLogger(aeAPI.Context())
+	changed := mapset.NewSet()
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+	changedFilter := shared.DiffFilterParam{Changed: true}
+	for i := 1; i < len(runs); i++ {
+		diff, err := diffAPI.GetRunsDiff(runs[i-1], runs[i], changedFilter, nil)
+		if err != nil {
+			// Older runs may no longer be in the searchcache.
+			log.Warningf("Failed to diff master runs %d and %d: %s", runs[i-1].ID, runs[i].ID, err.Error())
+
+			continue
+		}
+		for test := range diff.Differences {
+			changed.Add(test)
+		}
+	}
+
+	return changed
+}
+
+// getUnreproducedRegressions returns the given regressed tests which were
+// re-run in the retry run, but didn't regress there.
+func getUnreproducedRegressions(
+	diffAPI shared.DiffAPI,
+	baseRun,
+	retryRun shared.TestRun,
+	regressions mapset.Set,
+) (mapset.Set, error) { // nolint:ireturn // TODO: Fix ireturn lint error
+	// Only the regressed tests are re-run; ignore all the "deleted" tests.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+	retryFilter := shared.DiffFilterParam{Changed: true, Unchanged: true}
+	diff, err := diffAPI.GetRunsDiff(baseRun, retryRun, retryFilter, regressions)
+	if err != nil {
+		return nil, err
+	}
+	retried := mapset.NewSet()
+	for test := range diff.Differences {
+		if regressions.Contains(test) {
+			retried.Add(test)
+		}
+	}
+
+	return retried.Difference(diff.Differences.Regressions()), nil
+}
+
 // nolint:ireturn // TODO: Fix ireturn lint error
 func getDiffSummary(
 	aeAPI shared.AppEngineAPI,
 	diffAPI shared.DiffAPI,
 	suite shared.CheckSuite,
+	checkRepo shared.CheckRepo,
 	baseRun,
 	headRun shared.TestRun,
+	history *masterHistory,
+	retryRun *shared.TestRun,
 ) (summaries.Summary, error) { // nolint:ireturn // TODO: Fix ireturn lint error
 	// nolint:exhaustruct // TODO: Fix exhauststruct lint error
 	diffFilter := shared.DiffFilterParam{Added: true, Changed: true, Deleted: true}
@@ -246,7 +408,10 @@ func getDiffSummary(
 		HeadSHA:    headRun.FullRevisionHash,
 		DetailsURL: diffURL,
 		Status:     "completed",
-		PRNumbers:  suite.PRNumbers,
+	}
+	// The PRs of other repos aren't known to wpt.fyi.
+	if checkRepo.IsWPT() {
+		checkState.PRNumbers = suite.PRNumbers
 	}
 
 	var regressions mapset.Set
@@ -276,12 +441,13 @@ func getDiffSummary(
 
 	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
 	resultsComparison := summaries.ResultsComparison{
-		BaseRun: baseRun,
-		HeadRun: headRun,
-		HostURL: fmt.Sprintf("https://%s/", host),
-		DiffURL: diffURL.String(),
+		BaseRun:      baseRun,
+		HeadRun:      headRun,
+		HostURL:      fmt.Sprintf("https://%s/", host),
+		DiffURL:      diffURL.String(),
+		MasterBranch: checkRepo.GetMasterBranch(),
 	}
-	if headRun.LabelsSet().Contains(shared.PRHeadLabel) {
+	if headRun.LabelsSet().Contains(shared.PRHeadLabel) && checkRepo.IsWPT() {
 		// Deletions are meaningless and abundant comparing to master; ignore them.
 		// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
 		masterDiffFilter := shared.DiffFilterParam{Added: true, Changed: true, Unchanged: true}
@@ -316,8 +482,23 @@ func getDiffSummary(
 			CheckState:        checkState,
 			ResultsComparison: resultsComparison,
 			Regressions:       make(summaries.BeforeAndAfter),
+			FlakyRegressions:  make(summaries.BeforeAndAfter),
+			HistoryRuns:       history.len(),
 		}
-		tests := shared.ToStringSlice(regressions)
+		// Only regressions which aren't known to be flaky on master can fail
+		// the check.
+		flaky := history.getFlakyTests(aeAPI, diffAPI, regressions)
+		// Likewise, those which didn't regress again when they were retried.
+		if retryRun != nil {
+			unreproduced, err := getUnreproducedRegressions(diffAPI, baseRun, *retryRun, regressions)
+			if err != nil {
+				shared.GetLogger(aeAPI.Context()).Warningf("Failed to diff retry run %d: %s", retryRun.ID, err.Error())
+			} else {
+				flaky = flaky.Union(unreproduced)
+				data.RetryRun = retryRun
+			}
+		}
+		tests := shared.ToStringSlice(regressions.Difference(flaky))
 		sort.Strings(tests)
 		for _, path := range tests {
 			if len(data.Regressions) <= 10 {
@@ -326,10 +507,36 @@ func getDiffSummary(
 				data.More++
 			}
 		}
-		if checksCanBeNonNeutral {
+		if len(data.Regressions) > 0 {
+			regressed, _ := shared.MapStringKeys(data.Regressions)
+			byOwners, err := getRegressionsByOwners(aeAPI, checkRepo, headRun.FullRevisionHash, regressed)
+			if err != nil {
+				shared.GetLogger(aeAPI.Context()).Warningf("Failed to load owners of regressions: %s", err.Error())
+			} else if byOwners != nil {
+				data.ByOwners = byOwners
+				data.MentionOwners = aeAPI.IsFeatureEnabled(mentionOwnersFeature)
+			}
+		}
+		flakyTests := shared.ToStringSlice(flaky)
+		sort.Strings(flakyTests)
+		for _, path := range flakyTests {
+			if len(data.FlakyRegressions) <= 10 {
+				data.FlakyRegressions.Add(path, diff.BeforeSummary[path], diff.AfterSummary[path])
+			} else {
+				data.MoreFlaky++
+			}
+		}
+		annotationLevel := "warning"
+		if checksCanBeNonNeutral && len(tests) > 0 {
 			actionRequired := "action_required"
 			data.Conclusion = &actionRequired
+			annotationLevel = "failure"
 		}
+		data.Annotations = append(
+			getRegressionAnnotations(aeAPI, diff, baseRun, headRun, tests, annotationLevel, maxAnnotatedRegressions),
+			getRegressionAnnotations(
+				aeAPI, diff, baseRun, headRun, flakyTests, "notice", maxAnnotatedFlakyRegressions)...,
+		)
 		summary = data
 	}
 
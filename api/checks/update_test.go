@@ -4,8 +4,10 @@ package checks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"testing"
 
@@ -29,6 +31,7 @@ func TestGetDiffSummary_Regressed(t *testing.T) {
 	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
 	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(false)
 	aeAPI.EXPECT().GetHostname()
+	aeAPI.EXPECT().GetGitHubClient().Return(nil, errors.New("no GitHub client"))
 	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
 	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
 	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
@@ -39,11 +42,176 @@ func TestGetDiffSummary_Regressed(t *testing.T) {
 		PRNumbers: []int{123},
 	}
 
-	summary, err := getDiffSummary(aeAPI, diffAPI, suite, before, after)
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, nil, nil)
 	assert.Nil(t, err)
 	_, ok := summary.(summaries.Regressed)
 	assert.True(t, ok)
 	assert.Equal(t, suite.PRNumbers, summary.GetCheckState().PRNumbers)
+	annotations := summary.GetCheckState().Annotations
+	if assert.Len(t, annotations, 1) {
+		assert.Equal(t, "foo.html", annotations[0].GetPath())
+		assert.Equal(t, "warning", annotations[0].GetAnnotationLevel())
+	}
+}
+
+func TestGetDiffSummary_RegressedOwners(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	before, after := getBeforeAndAfterRuns()
+	runDiff := shared.RunDiff{
+		Differences: shared.ResultsDiff{"/css/foo.html": shared.TestDiff{0, 1, 0}},
+	}
+	client, _ := newMetaFilesClient(t, after.FullRevisionHash, map[string]string{
+		"/repos/web-platform-tests/wpt/contents/css/META.yml": "suggested_reviewers:\n  - alice\n",
+	})
+
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(false)
+	aeAPI.EXPECT().IsFeatureEnabled(mentionOwnersFeature).Return(true)
+	aeAPI.EXPECT().GetHostname()
+	aeAPI.EXPECT().GetGitHubClient().Return(client, nil)
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
+	diffURL, _ := url.Parse("https://wpt.fyi/results?diff")
+	diffAPI.EXPECT().GetDiffURL(before, after, gomock.Any()).Return(diffURL)
+	diffAPI.EXPECT().GetMasterDiffURL(after, sharedtest.SameDiffFilter("ACU")).Return(diffURL)
+	suite := shared.CheckSuite{
+		PRNumbers: []int{123},
+	}
+
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, nil, nil)
+	assert.Nil(t, err)
+	regressed, ok := summary.(summaries.Regressed)
+	assert.True(t, ok)
+	assert.Equal(t, []summaries.OwnedRegressions{
+		{Owners: []string{"alice"}, Tests: []string{"/css/foo.html"}},
+	}, regressed.ByOwners)
+	assert.True(t, regressed.MentionOwners)
+}
+
+func TestGetDiffSummary_RegressedFlaky(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	before, after := getBeforeAndAfterRuns()
+	runDiff := shared.RunDiff{
+		Differences: shared.ResultsDiff{
+			"/flaky.html": shared.TestDiff{0, 1, 0},
+			"/real.html":  shared.TestDiff{0, 1, 0},
+		},
+	}
+	historyRuns := shared.TestRuns{{ID: 1}, {ID: 2}, {ID: 3}}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	history := &masterHistory{runs: historyRuns}
+
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(true)
+	aeAPI.EXPECT().GetHostname()
+	aeAPI.EXPECT().GetGitHubClient().Return(nil, errors.New("no GitHub client"))
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(historyRuns[0], historyRuns[1], sharedtest.SameDiffFilter("C"), nil).Return(
+		shared.RunDiff{Differences: shared.ResultsDiff{
+			"/flaky.html":     shared.TestDiff{1, 0, 0},
+			"/unrelated.html": shared.TestDiff{1, 0, 0},
+		}}, nil)
+	diffAPI.EXPECT().GetRunsDiff(historyRuns[1], historyRuns[2], sharedtest.SameDiffFilter("C"), nil).Return(
+		shared.RunDiff{}, shared.ErrRunNotInSearchCache)
+	diffURL, _ := url.Parse("https://wpt.fyi/results?diff")
+	diffAPI.EXPECT().GetDiffURL(before, after, gomock.Any()).Return(diffURL)
+	diffAPI.EXPECT().GetMasterDiffURL(after, sharedtest.SameDiffFilter("ACU")).Return(diffURL)
+	suite := shared.CheckSuite{
+		PRNumbers: []int{123},
+	}
+
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, history, nil)
+	assert.Nil(t, err)
+	regressed, ok := summary.(summaries.Regressed)
+	assert.True(t, ok)
+	tests, _ := shared.MapStringKeys(regressed.Regressions)
+	assert.Equal(t, []string{"/real.html"}, tests)
+	tests, _ = shared.MapStringKeys(regressed.FlakyRegressions)
+	assert.Equal(t, []string{"/flaky.html"}, tests)
+	assert.Equal(t, 3, regressed.HistoryRuns)
+	assert.Equal(t, "action_required", *regressed.Conclusion)
+	if assert.Len(t, regressed.Annotations, 2) {
+		assert.Equal(t, "failure", regressed.Annotations[0].GetAnnotationLevel())
+		assert.Equal(t, "flaky.html", regressed.Annotations[1].GetPath())
+		assert.Equal(t, "notice", regressed.Annotations[1].GetAnnotationLevel())
+	}
+
+	// Only flaky regressions don't fail the check. The history, which is
+	// shared by the suites of a check update, isn't diffed again.
+	runDiff.Differences = shared.ResultsDiff{"/flaky.html": shared.TestDiff{0, 1, 0}}
+	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(true)
+	aeAPI.EXPECT().GetHostname()
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetDiffURL(before, after, gomock.Any()).Return(diffURL)
+	diffAPI.EXPECT().GetMasterDiffURL(after, sharedtest.SameDiffFilter("ACU")).Return(diffURL)
+
+	summary, err = getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, history, nil)
+	assert.Nil(t, err)
+	regressed = summary.(summaries.Regressed)
+	assert.Empty(t, regressed.Regressions)
+	assert.Len(t, regressed.FlakyRegressions, 1)
+	assert.Equal(t, "neutral", *regressed.Conclusion)
+}
+
+func TestGetDiffSummary_RegressedRetried(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	before, after := getBeforeAndAfterRuns()
+	retry := after
+	retry.ID = 3
+	retry.Labels = []string{shared.PRRetryLabel}
+	runDiff := shared.RunDiff{
+		Differences: shared.ResultsDiff{
+			"/flaky.html":   shared.TestDiff{0, 1, 0},
+			"/real.html":    shared.TestDiff{0, 1, 0},
+			"/skipped.html": shared.TestDiff{0, 1, 0},
+		},
+	}
+	regressions := shared.NewSetFromStringSlice([]string{"/flaky.html", "/real.html", "/skipped.html"})
+
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(true)
+	aeAPI.EXPECT().GetHostname()
+	aeAPI.EXPECT().GetGitHubClient().Return(nil, errors.New("no GitHub client"))
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
+	// /skipped.html wasn't retried, so it's absent from the diff.
+	diffAPI.EXPECT().GetRunsDiff(before, retry, sharedtest.SameDiffFilter("CU"), regressions).Return(
+		shared.RunDiff{Differences: shared.ResultsDiff{
+			"/flaky.html": shared.TestDiff{0, 0, 0},
+			"/real.html":  shared.TestDiff{0, 1, 0},
+		}}, nil)
+	diffURL, _ := url.Parse("https://wpt.fyi/results?diff")
+	diffAPI.EXPECT().GetDiffURL(before, after, gomock.Any()).Return(diffURL)
+	diffAPI.EXPECT().GetMasterDiffURL(after, sharedtest.SameDiffFilter("ACU")).Return(diffURL)
+	suite := shared.CheckSuite{
+		PRNumbers: []int{123},
+	}
+
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, nil, &retry)
+	assert.Nil(t, err)
+	regressed, ok := summary.(summaries.Regressed)
+	assert.True(t, ok)
+	tests, _ := shared.MapStringKeys(regressed.Regressions)
+	sort.Strings(tests)
+	assert.Equal(t, []string{"/real.html", "/skipped.html"}, tests)
+	tests, _ = shared.MapStringKeys(regressed.FlakyRegressions)
+	assert.Equal(t, []string{"/flaky.html"}, tests)
+	assert.Equal(t, &retry, regressed.RetryRun)
+	assert.Equal(t, "action_required", *regressed.Conclusion)
 }
 
 func TestGetDiffSummary_Completed(t *testing.T) {
@@ -69,13 +237,57 @@ func TestGetDiffSummary_Completed(t *testing.T) {
 		PRNumbers: []int{123},
 	}
 
-	summary, err := getDiffSummary(aeAPI, diffAPI, suite, before, after)
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, getWPTCheckRepo(), before, after, nil, nil)
 	assert.Nil(t, err)
 	_, ok := summary.(summaries.Completed)
 	assert.True(t, ok)
 	assert.Equal(t, suite.PRNumbers, summary.GetCheckState().PRNumbers)
 }
 
+func TestGetDiffSummary_Fork(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	before, after := getBeforeAndAfterRuns()
+	before.Labels = append(before.Labels, shared.GetRepoLabel("browser", "wpt-fork"))
+	after.Labels = append(after.Labels, shared.GetRepoLabel("browser", "wpt-fork"))
+	runDiff := shared.RunDiff{
+		Differences: shared.ResultsDiff{"/foo.html": shared.TestDiff{1, 0, 1}},
+	}
+
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(context.Background())
+	aeAPI.EXPECT().IsFeatureEnabled(failChecksOnRegressionFeature).Return(false)
+	aeAPI.EXPECT().GetHostname()
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("ADC"), gomock.Any()).Return(runDiff, nil)
+	diffAPI.EXPECT().GetRunsDiff(before, after, sharedtest.SameDiffFilter("C"), gomock.Any()).Return(runDiff, nil)
+	diffURL, _ := url.Parse("https://wpt.fyi/results?diff")
+	diffAPI.EXPECT().GetDiffURL(before, after, gomock.Any()).Return(diffURL)
+	suite := shared.CheckSuite{
+		Owner:     "browser",
+		Repo:      "wpt-fork",
+		PRNumbers: []int{123},
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	checkRepo := shared.CheckRepo{Owner: "browser", Repo: "wpt-fork", MasterBranch: "main"}
+
+	// The PRs of the fork aren't wpt PRs, and its master runs aren't shown
+	// on wpt.fyi, so neither are linked.
+	summary, err := getDiffSummary(aeAPI, diffAPI, suite, checkRepo, before, after, nil, nil)
+	assert.Nil(t, err)
+	completed, ok := summary.(summaries.Completed)
+	assert.True(t, ok)
+	assert.Empty(t, completed.PRNumbers)
+	assert.Empty(t, completed.MasterDiffURL)
+	assert.Equal(t, "main", completed.MasterBranch)
+}
+
+func getWPTCheckRepo() shared.CheckRepo {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.CheckRepo{Owner: shared.WPTRepoOwner, Repo: shared.WPTRepoName}
+}
+
 func getBeforeAndAfterRuns() (before, after shared.TestRun) {
 	before.FullRevisionHash = strings.Repeat("0", 40)
 	before.BrowserName = "chrome"
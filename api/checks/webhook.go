@@ -7,9 +7,11 @@ package checks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 
 	"github.com/google/go-github/v90/github"
 	"github.com/web-platform-tests/wpt.fyi/shared"
@@ -33,6 +35,71 @@ func isWPTFYIApp(appID int64) bool {
 	return appID == wptfyiCheckAppID || appID == wptfyiStagingCheckAppID
 }
 
+// isCheckApp returns whether the app creates the checks of the repo, i.e. it's
+// one of the wpt.fyi apps, or the app configured for the repo.
+func isCheckApp(repo *shared.CheckRepo, appID int64) bool {
+	return isWPTFYIApp(appID) || (repo != nil && repo.AppID == appID)
+}
+
+// hookTargetIDHeader is the header of GitHub App webhooks which holds the ID
+// of the app.
+const hookTargetIDHeader = "X-GitHub-Hook-Installation-Target-ID"
+
+// checkWebhookSecretName is the name of the secret which signs the webhooks of
+// the wpt.fyi apps, unless the app has its own.
+const checkWebhookSecretName = "github-check-webhook-secret"
+
+// getCheckWebhookSecret returns the secret which signs the webhooks of the app,
+// i.e. the app's own github-check-webhook-secret-<appID> secret, so that the
+// apps of other repos needn't share wpt.fyi's. Only the wpt.fyi apps fall back
+// to the shared github-check-webhook-secret.
+func getCheckWebhookSecret(ds shared.Datastore, appID int64) (string, error) {
+	secret, err := shared.GetSecret(ds, fmt.Sprintf("%s-%d", checkWebhookSecretName, appID))
+	if errors.Is(err, shared.ErrNoSuchEntity) && isWPTFYIApp(appID) {
+		return shared.GetSecret(ds, checkWebhookSecretName)
+	}
+
+	return secret, err
+}
+
+// isEventOfApp returns whether the (validated) payload of the event is of the
+// app whose secret signed it, i.e. the app of the check suite or check run, or
+// for pull requests, the app which creates the checks of the repo. Since the
+// app is chosen by a request header, an app mustn't be able to sign events
+// which are handled as those of another app.
+func isEventOfApp(api API, event webhookGithubEvent, payload []byte, appID int64) (bool, error) {
+	switch event {
+	case eventCheckSuite:
+		var checkSuite github.CheckSuiteEvent
+		if err := json.Unmarshal(payload, &checkSuite); err != nil {
+			return false, err
+		}
+
+		return checkSuite.GetCheckSuite().GetApp().GetID() == appID, nil
+	case eventCheckRun:
+		var checkRun github.CheckRunEvent
+		if err := json.Unmarshal(payload, &checkRun); err != nil {
+			return false, err
+		}
+
+		return checkRun.GetCheckRun().GetApp().GetID() == appID, nil
+	case eventPullRequest:
+		var pullRequest github.PullRequestEvent
+		if err := json.Unmarshal(payload, &pullRequest); err != nil {
+			return false, err
+		}
+		checkRepo, err := api.GetCheckRepo(
+			pullRequest.GetRepo().GetOwner().GetLogin(), pullRequest.GetRepo().GetName())
+		if err != nil {
+			return false, err
+		}
+
+		return isCheckApp(checkRepo, appID), nil
+	}
+
+	return false, nil
+}
+
 // checkWebhookHandler handles GitHub events relating to our wpt.fyi and
 // staging.wpt.fyi GitHub Apps[0], sent to the /api/webhook/check endpoint.
 //
@@ -61,9 +128,17 @@ func checkWebhookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	secret, err := shared.GetSecret(ds, "github-check-webhook-secret")
+	appID, err := strconv.ParseInt(r.Header.Get(hookTargetIDHeader), 10, 64)
 	if err != nil {
-		log.Errorf("Missing secret: github-check-webhook-secret")
+		log.Errorf("Invalid %s header: %s", hookTargetIDHeader, err.Error())
+		http.Error(w, "Missing or invalid "+hookTargetIDHeader+" header", http.StatusBadRequest)
+
+		return
+	}
+
+	secret, err := getCheckWebhookSecret(ds, appID)
+	if err != nil {
+		log.Errorf("Missing secret: %s", err.Error())
 		http.Error(w, "Unable to verify request: secret not found", http.StatusInternalServerError)
 
 		return
@@ -79,8 +154,20 @@ func checkWebhookHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Debugf("GitHub Delivery: %s", r.Header.Get("X-GitHub-Delivery"))
 
-	var processed bool
 	api := NewAPI(ctx)
+	if ok, err := isEventOfApp(api, inputEvent, payload, appID); err != nil {
+		log.Errorf("%v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	} else if !ok {
+		log.Errorf("%s event isn't of App ID %v", event, appID)
+		http.Error(w, "Event isn't of the app which signed it", http.StatusForbidden)
+
+		return
+	}
+
+	var processed bool
 	switch inputEvent {
 	case eventCheckSuite:
 		processed, err = handleCheckSuiteEvent(api, payload)
@@ -131,10 +218,18 @@ func handleCheckSuiteEvent(api API, payload []byte) (bool, error) {
 		appID,
 	)
 
-	if !isWPTFYIApp(appID) {
+	checkRepo, err := api.GetCheckRepo(owner, repo)
+	if err != nil {
+		return false, err
+	} else if !isCheckApp(checkRepo, appID) {
 		log.Infof("Ignoring check_suite App ID %v", appID)
 
 		return false, nil
+	} else if checkRepo == nil {
+		// The forks of wpt contributors aren't configured; their PRs are to wpt.
+		if checkRepo, err = api.GetCheckRepo(shared.WPTRepoOwner, shared.WPTRepoName); err != nil {
+			return false, err
+		}
 	}
 
 	// nolint:nestif // TODO: Fix nestif lint error
@@ -142,7 +237,7 @@ func handleCheckSuiteEvent(api API, payload []byte) (bool, error) {
 		pullRequests := checkSuite.GetCheckSuite().PullRequests
 		prNumbers := []int{}
 		for _, pr := range pullRequests {
-			if pr.GetBase().GetRepo().GetID() == wptRepoID {
+			if pr.GetBase().GetRepo().GetID() == checkRepo.RepoID {
 				prNumbers = append(prNumbers, pr.GetNumber())
 			}
 		}
@@ -151,9 +246,9 @@ func handleCheckSuiteEvent(api API, payload []byte) (bool, error) {
 		if action == requestedAction {
 			for _, p := range pullRequests {
 				destRepoID := p.GetBase().GetRepo().GetID()
-				if destRepoID == wptRepoID && p.GetHead().GetRepo().GetID() != destRepoID {
-					// Errors are already logged by CreateWPTCheckSuite
-					_, _ = api.CreateWPTCheckSuite(appID, installationID, sha, prNumbers...)
+				if destRepoID == checkRepo.RepoID && p.GetHead().GetRepo().GetID() != destRepoID {
+					// Errors are already logged by CreateCheckSuite
+					_, _ = api.CreateCheckSuite(*checkRepo, sha, prNumbers...)
 				}
 			}
 		}
@@ -164,7 +259,15 @@ func handleCheckSuiteEvent(api API, payload []byte) (bool, error) {
 		}
 
 		if action == rerequestedAction {
-			return scheduleProcessingForExistingRuns(api.Context(), sha)
+			products, err := checkRepo.GetProducts()
+			if err != nil {
+				return false, err
+			}
+			for i := range products {
+				products[i] = withRepoLabel(checkRepo, products[i])
+			}
+
+			return scheduleProcessingForExistingRuns(api.Context(), sha, products...)
 		}
 	}
 
@@ -192,10 +295,17 @@ func handleCheckRunEvent(
 
 	log.Debugf("Check run %s: %s/%s @ %s (App %v, ID %v)", action, owner, repo, shared.CropString(sha, 7), appName, appID)
 
-	if !isWPTFYIApp(appID) {
-		log.Infof("Ignoring check_run App ID %v", appID)
+	var checkRepo *shared.CheckRepo
+	if !isWPTFYIApp(appID) || owner != shared.WPTRepoOwner || repo != shared.WPTRepoName {
+		// Other apps can be configured to check repos other than wpt.
+		var err error
+		if checkRepo, err = api.GetCheckRepo(owner, repo); err != nil {
+			return false, err
+		} else if !isCheckApp(checkRepo, appID) {
+			log.Infof("Ignoring check_run App ID %v", appID)
 
-		return false, nil
+			return false, nil
+		}
 	}
 
 	login := checkRun.GetSender().GetLogin()
@@ -208,6 +318,7 @@ func handleCheckRunEvent(
 	// [0]: https://developer.github.com/v3/checks/runs/#check-runs-and-requested-actions
 	status := checkRun.GetCheckRun().GetStatus()
 	shouldSchedule := false
+	retry := false
 	if (action == "created" && status != "completed") || action == "rerequested" {
 		shouldSchedule = true
 	} else if action == "requested_action" {
@@ -215,6 +326,9 @@ func handleCheckRunEvent(
 		switch actionID {
 		case "recompute":
 			shouldSchedule = true
+		case "retry":
+			shouldSchedule = true
+			retry = true
 		case "ignore":
 			err := api.IgnoreFailure(
 				login,
@@ -253,8 +367,13 @@ func handleCheckRunEvent(
 
 			return false, err
 		}
-		// Errors are logged by ScheduleResultsProcessing
-		_ = api.ScheduleResultsProcessing(sha, spec)
+		spec = withRepoLabel(checkRepo, spec)
+		// Errors are logged by ScheduleRetry and ScheduleResultsProcessing
+		if retry {
+			_ = api.ScheduleRetry(sha, spec)
+		} else {
+			_ = api.ScheduleResultsProcessing(sha, spec)
+		}
 
 		return true, nil
 	}
@@ -264,9 +383,10 @@ func handleCheckRunEvent(
 }
 
 // handlePullRequestEvent reaches to pull requests from forks, ensuring that a
-// GitHub check_suite is created in the main WPT repository for those. GitHub
-// automatically creates a check_suite for code pushed to the WPT repository,
-// so we don't need to do anything for same-repo pull requests.
+// GitHub check_suite is created in the checked repository (wpt, or one
+// configured by a CheckRepo) for those. GitHub automatically creates a
+// check_suite for code pushed to the repository, so we don't need to do
+// anything for same-repo pull requests.
 func handlePullRequestEvent(api API, payload []byte) (bool, error) {
 	log := shared.GetLogger(api.Context())
 	var pullRequest github.PullRequestEvent
@@ -283,13 +403,22 @@ func handlePullRequestEvent(api API, payload []byte) (bool, error) {
 		return false, nil
 	}
 
+	owner := pullRequest.GetRepo().GetOwner().GetLogin()
+	repo := pullRequest.GetRepo().GetName()
+	checkRepo, err := api.GetCheckRepo(owner, repo)
+	if err != nil {
+		return false, err
+	} else if checkRepo == nil {
+		log.Debugf("Skipping pull request to unconfigured repo %s/%s", owner, repo)
+
+		return false, nil
+	}
+
 	sha := pullRequest.GetPullRequest().GetHead().GetSHA()
 	destRepoID := pullRequest.GetPullRequest().GetBase().GetRepo().GetID()
-	if destRepoID == wptRepoID && pullRequest.GetPullRequest().GetHead().GetRepo().GetID() != destRepoID {
+	if destRepoID == checkRepo.RepoID && pullRequest.GetPullRequest().GetHead().GetRepo().GetID() != destRepoID {
 		// Pull is across forks; request a check suite on the main fork too.
-		appID, installationID := api.GetWPTRepoAppInstallationIDs()
-
-		return api.CreateWPTCheckSuite(appID, installationID, sha, pullRequest.GetNumber())
+		return api.CreateCheckSuite(*checkRepo, sha, pullRequest.GetNumber())
 	}
 
 	return false, nil
@@ -336,6 +465,19 @@ func createCheckRun(ctx context.Context, suite shared.CheckSuite, opts github.Cr
 		return false, err
 	}
 
+	// Only the first batch of annotations can be created with the run; the
+	// others are added by updating it.
+	var batches [][]*github.CheckRunAnnotation
+	if opts.Output != nil {
+		batches = batchAnnotations(opts.Output.Annotations)
+		output := *opts.Output
+		output.Annotations = nil
+		if len(batches) > 0 {
+			output.Annotations = batches[0]
+		}
+		opts.Output = &output
+	}
+
 	checkRun, resp, err := client.Checks.CreateCheckRun(ctx, suite.Owner, suite.Repo, opts)
 	if err != nil {
 		msg := "Failed to create check_run"
@@ -347,6 +489,13 @@ func createCheckRun(ctx context.Context, suite shared.CheckSuite, opts github.Cr
 		return false, err
 	} else if checkRun != nil {
 		log.Infof("Created check_run %v", checkRun.GetID())
+		if len(batches) > 1 {
+			err = addCheckRunAnnotations(ctx, client, suite, checkRun.GetID(), opts.Name,
+				opts.Output.GetTitle(), opts.Output.GetSummary(), batches[1:])
+			if err != nil {
+				log.Warningf("Failed to annotate check_run %v: %s", checkRun.GetID(), err.Error())
+			}
+		}
 	}
 
 	return true, nil
@@ -0,0 +1,44 @@
+//go:build medium
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package checks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestGetCheckWebhookSecret(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	_, err = getCheckWebhookSecret(store, 123)
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+
+	_, err = store.Put(store.NewNameKey("Token", checkWebhookSecretName), &shared.Token{Secret: "shared"})
+	assert.Nil(t, err)
+	_, err = store.Put(store.NewNameKey("Token", checkWebhookSecretName+"-123"), &shared.Token{Secret: "app"})
+	assert.Nil(t, err)
+
+	secret, err := getCheckWebhookSecret(store, 123)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", secret)
+
+	// Only the wpt.fyi apps use the shared secret.
+	_, err = getCheckWebhookSecret(store, 456)
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+	secret, err = getCheckWebhookSecret(store, wptfyiCheckAppID)
+	assert.Nil(t, err)
+	assert.Equal(t, "shared", secret)
+	secret, err = getCheckWebhookSecret(store, wptfyiStagingCheckAppID)
+	assert.Nil(t, err)
+	assert.Equal(t, "shared", secret)
+}
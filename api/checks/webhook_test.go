@@ -36,12 +36,57 @@ func TestHandleCheckRunEvent_InvalidApp(t *testing.T) {
 
 	api := mock_checks.NewMockAPI(mockCtrl)
 	api.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	api.EXPECT().GetCheckRepo("", "").Return(nil, nil)
 
 	processed, err := handleCheckRunEvent(api, payload)
 	assert.Nil(t, err)
 	assert.False(t, processed)
 }
 
+func TestHandleCheckRunEvent_ForkApp(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fork := getForkCheckRepo()
+	requestedAction := "requested_action"
+	name := "chrome"
+	username := "lukebjerring"
+	event := github.CheckRunEvent{
+		Action: &requestedAction,
+		CheckRun: &github.CheckRun{
+			App:  &github.App{ID: &fork.AppID},
+			Name: &name,
+		},
+		Repo: &github.Repository{
+			Owner: &github.User{Login: &fork.Owner},
+			Name:  &fork.Repo,
+		},
+		RequestedAction: &github.RequestedAction{Identifier: "ignore"},
+		Installation:    &github.Installation{AppID: &fork.AppID},
+		Sender:          &github.User{Login: &username},
+	}
+	payload, _ := json.Marshal(event)
+
+	api := mock_checks.NewMockAPI(mockCtrl)
+	api.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	api.EXPECT().GetCheckRepo(fork.Owner, fork.Repo).Return(&fork, nil)
+	api.EXPECT().IgnoreFailure(username, fork.Owner, fork.Repo, event.GetCheckRun(), event.GetInstallation())
+
+	processed, err := handleCheckRunEvent(api, payload)
+	assert.Nil(t, err)
+	assert.True(t, processed)
+
+	// The app of the fork doesn't check other repos.
+	owner := shared.WPTRepoOwner
+	event.Repo.Owner.Login = &owner
+	payload, _ = json.Marshal(event)
+	api.EXPECT().GetCheckRepo(owner, fork.Repo).Return(nil, nil)
+
+	processed, err = handleCheckRunEvent(api, payload)
+	assert.Nil(t, err)
+	assert.False(t, processed)
+}
+
 func TestHandleCheckRunEvent_Created_Completed(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -184,6 +229,26 @@ func TestHandleCheckRunEvent_ActionRequested_Cancel(t *testing.T) {
 	assert.True(t, processed)
 }
 
+func TestHandleCheckRunEvent_ActionRequested_Retry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha := strings.Repeat("0123456789", 4)
+	event := getCheckRunCreatedEvent("completed", "lukebjerring", sha)
+	requestedAction := "requested_action"
+	event.Action = &requestedAction
+	event.RequestedAction = &github.RequestedAction{Identifier: "retry"}
+	payload, _ := json.Marshal(event)
+
+	api := mock_checks.NewMockAPI(mockCtrl)
+	api.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	api.EXPECT().ScheduleRetry(sha, sharedtest.SameProductSpec("chrome")).Return(nil)
+
+	processed, err := handleCheckRunEvent(api, payload)
+	assert.Nil(t, err)
+	assert.True(t, processed)
+}
+
 func getCheckRunCreatedEvent(status, sender, sha string) github.CheckRunEvent {
 	id := int64(wptfyiStagingCheckAppID)
 	chrome := "chrome"
@@ -216,14 +281,84 @@ func TestHandlePullRequestEvent_ChecksEnabledForUser(t *testing.T) {
 
 	api := mock_checks.NewMockAPI(mockCtrl)
 	api.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
-	api.EXPECT().GetWPTRepoAppInstallationIDs().Return(wptfyiStagingCheckAppID, wptRepoStagingInstallationID)
-	api.EXPECT().CreateWPTCheckSuite(wptfyiStagingCheckAppID, wptRepoStagingInstallationID, sha, 123).Return(true, nil)
+	checkRepo := shared.CheckRepo{
+		Owner:          shared.WPTRepoOwner,
+		Repo:           shared.WPTRepoName,
+		RepoID:         wptRepoID,
+		AppID:          wptfyiStagingCheckAppID,
+		InstallationID: wptRepoStagingInstallationID,
+	}
+	api.EXPECT().GetCheckRepo(shared.WPTRepoOwner, shared.WPTRepoName).Return(&checkRepo, nil)
+	api.EXPECT().CreateCheckSuite(checkRepo, sha, 123).Return(true, nil)
 
 	processed, err := handlePullRequestEvent(api, payload)
 	assert.Nil(t, err)
 	assert.True(t, processed)
 }
 
+func TestHandlePullRequestEvent_UnconfiguredRepo(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha := strings.Repeat("1234567890", 4)
+	event := getOpenedPREvent("lukebjerring", sha)
+	owner, name := "someone", "wpt"
+	event.Repo = &github.Repository{Owner: &github.User{Login: &owner}, Name: &name}
+	payload, _ := json.Marshal(event)
+
+	api := mock_checks.NewMockAPI(mockCtrl)
+	api.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	api.EXPECT().GetCheckRepo(owner, name).Return(nil, nil)
+
+	processed, err := handlePullRequestEvent(api, payload)
+	assert.Nil(t, err)
+	assert.False(t, processed)
+}
+
+func TestIsEventOfApp(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fork := getForkCheckRepo()
+	api := mock_checks.NewMockAPI(mockCtrl)
+
+	suite, _ := json.Marshal(github.CheckSuiteEvent{
+		CheckSuite: &github.CheckSuite{App: &github.App{ID: &fork.AppID}},
+	})
+	ok, err := isEventOfApp(api, eventCheckSuite, suite, fork.AppID)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	// Other apps can't sign the events of the fork's app.
+	ok, err = isEventOfApp(api, eventCheckSuite, suite, wptfyiCheckAppID)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	run, _ := json.Marshal(github.CheckRunEvent{
+		CheckRun: &github.CheckRun{App: &github.App{ID: &fork.AppID}},
+	})
+	ok, err = isEventOfApp(api, eventCheckRun, run, fork.AppID)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = isEventOfApp(api, eventCheckRun, run, 456)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	// Pull requests are of the apps which check the repo.
+	pr := getOpenedPREvent("lukebjerring", strings.Repeat("1234567890", 4))
+	pr.Repo = &github.Repository{Owner: &github.User{Login: &fork.Owner}, Name: &fork.Repo}
+	prPayload, _ := json.Marshal(pr)
+	api.EXPECT().GetCheckRepo(fork.Owner, fork.Repo).Times(3).Return(&fork, nil)
+	ok, err = isEventOfApp(api, eventPullRequest, prPayload, fork.AppID)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = isEventOfApp(api, eventPullRequest, prPayload, wptfyiCheckAppID)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = isEventOfApp(api, eventPullRequest, prPayload, 456)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
 func getOpenedPREvent(user, sha string) github.PullRequestEvent {
 	opened := "opened"
 	// handlePullRequestEvent only operates on pull requests from forks, so
@@ -231,8 +366,11 @@ func getOpenedPREvent(user, sha string) github.PullRequestEvent {
 	headRepoID := wptRepoID - 1
 	baseRepoID := wptRepoID
 	number := 123
+	owner := shared.WPTRepoOwner
+	name := shared.WPTRepoName
 	return github.PullRequestEvent{
 		Number: &number,
+		Repo:   &github.Repository{Owner: &github.User{Login: &owner}, Name: &name},
 		PullRequest: &github.PullRequest{
 			User: &github.User{Login: &user},
 			Head: &github.PullRequestBranch{
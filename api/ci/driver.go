@@ -0,0 +1,204 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// HandleNotification handles the notification from a CI system that a build
+// has finished, scheduling the processing of the results of each of its
+// artifacts. Every notification is recorded as a shared.CINotification with
+// its outcome, so that it can be replayed if handling it failed; artifacts
+// which runs were created for by earlier notifications of the build are
+// skipped.
+func HandleNotification(a receiver.API, provider CIProvider, w http.ResponseWriter, r *http.Request) {
+	log := shared.GetLogger(a.Context())
+	notification, err := recordNotification(a, provider.Uploader(), r)
+	if err != nil {
+		log.Errorf("Failed to read %s notification: %s", provider.Uploader(), err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	r = r.WithContext(context.WithValue(r.Context(), notificationKey{}, notification))
+	handleNotification(a, provider, notificationWriter{ResponseWriter: w, notification: notification}, r)
+	if notification.Outcome == shared.CINotificationIgnored {
+		// Ignored notifications (e.g. the frequent status events of builds
+		// which aren't finished) are never replayed, so only their outcome
+		// is kept.
+		notification.Headers = nil
+		notification.Payload = ""
+	}
+	if err := a.PutCINotification(notification); err != nil {
+		log.Errorf("Failed to record the outcome of %s notification %d: %s",
+			provider.Uploader(), notification.ID, err.Error())
+	}
+}
+
+func handleNotification(a receiver.API, provider CIProvider, w notificationWriter, r *http.Request) {
+	log := shared.GetLogger(a.Context())
+	build, err := provider.ParseEvent(r)
+	var eventErr EventError
+	switch {
+	case errors.Is(err, ErrIgnored):
+		log.Debugf("Ignoring %s notification: %s", provider.Uploader(), err.Error())
+		w.finish(shared.CINotificationIgnored, http.StatusNoContent, err)
+
+		return
+	case errors.As(err, &eventErr):
+		log.Warningf("Invalid %s notification: %s", provider.Uploader(), err.Error())
+		w.finish(shared.CINotificationInvalid, eventErr.Status, err)
+
+		return
+	case err != nil:
+		log.Errorf("Failed to parse %s notification: %s", provider.Uploader(), err.Error())
+		w.finish(shared.CINotificationFailed, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	notification := w.notification
+	notification.BuildID = build.ID
+	if notification.Verified && notification.Replays == 0 {
+		// Record that the notification was verified before processing it, in
+		// case processing is interrupted and it has to be replayed.
+		if err := a.PutCINotification(notification); err != nil {
+			log.Warningf("Failed to record %s notification %d: %s", provider.Uploader(), notification.ID, err.Error())
+		}
+	}
+	processed, err := processedArtifacts(a, provider.Uploader(), build.ID)
+	if err != nil {
+		log.Errorf("Failed to load the notifications of %s build %s: %s", provider.Uploader(), build.ID, err.Error())
+		w.finish(shared.CINotificationFailed, http.StatusInternalServerError, err)
+
+		return
+	}
+	before := processed.Clone()
+	runs, err := ProcessBuild(a, provider, build, processed)
+	for _, run := range runs {
+		notification.Runs = append(notification.Runs, run.ID)
+	}
+	notification.Artifacts = append(notification.Artifacts, shared.ToStringSlice(processed.Difference(before))...)
+	sort.Strings(notification.Artifacts)
+	switch {
+	case errors.Is(err, ErrNoResults), errors.Is(err, ErrDuplicate):
+		log.Infof("%s build %s: %s", provider.Uploader(), build.ID, err.Error())
+		outcome := shared.CINotificationNoResults
+		if errors.Is(err, ErrDuplicate) {
+			outcome = shared.CINotificationDuplicate
+		}
+		w.finish(outcome, http.StatusNoContent, err)
+
+		return
+	case err != nil:
+		log.Errorf("%v", err)
+		w.finish(shared.CINotificationFailed, http.StatusInternalServerError, err)
+
+		return
+	}
+	notification.Outcome = shared.CINotificationSucceeded
+	provider.ReportStatus(w, build, runs)
+}
+
+// ProcessBuild creates a pending run for each artifact of the build which is
+// not in processed (the names of the artifacts which runs were already created
+// for), and schedules the processing of its results. It returns the created
+// runs, and adds their artifacts to processed.
+func ProcessBuild(
+	a receiver.API, provider CIProvider, build *Build, processed mapset.Set) ([]shared.PendingTestRun, error) {
+	artifacts, err := provider.ListArtifacts(build)
+	if err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		return nil, ErrNoResults
+	}
+
+	// Ensure we call back to this appengine version instance.
+	callbackURL := fmt.Sprintf("https://%s/api/results/create", a.GetVersionedHostname())
+	var runs []shared.PendingTestRun
+	var errs []error
+	skipped := 0
+	for _, artifact := range artifacts {
+		if processed.Contains(artifact.Name) {
+			shared.GetLogger(a.Context()).Infof("Skipping %s of %s build %s, which was already processed",
+				artifact.Name, provider.Uploader(), build.ID)
+			skipped++
+
+			continue
+		}
+		run, err := createPendingRun(a, provider, build, artifact, callbackURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("artifact %s: %w", artifact.Name, err))
+
+			continue
+		}
+		processed.Add(artifact.Name)
+		runs = append(runs, run)
+	}
+	if skipped == len(artifacts) {
+		return nil, ErrDuplicate
+	}
+
+	return runs, shared.NewMultiError(errs, fmt.Sprintf("creating runs for %s build %s", provider.Uploader(), build.ID))
+}
+
+func createPendingRun(
+	a receiver.API, provider CIProvider, build *Build, artifact Artifact, callbackURL string) (shared.PendingTestRun, error) {
+	log := shared.GetLogger(a.Context())
+	uploader := provider.Uploader()
+	product, labels := provider.ProductAndLabels(build, artifact)
+	if product.FullRevisionHash == "" {
+		product.FullRevisionHash = build.SHA
+	}
+	log.Infof("Uploading %s of %s build %s with labels %v", artifact.Name, uploader, build.ID, labels)
+
+	// See ReserveUploadQuota for the quota of URL payloads.
+	if err := a.ReserveUploadQuota(uploader, 0); err != nil {
+		return shared.PendingTestRun{}, err
+	}
+	extraParams := map[string]string{
+		"revision":     product.FullRevisionHash,
+		"labels":       strings.Join(labels, ","),
+		"callback_url": callbackURL,
+	}
+	task, err := a.ScheduleResultsTask(uploader, artifact.Results, artifact.Screenshots, artifact.Archives, extraParams)
+	if err != nil {
+		return shared.PendingTestRun{}, err
+	}
+	id, err := strconv.ParseInt(task, 10, 64)
+	if err != nil {
+		return shared.PendingTestRun{}, fmt.Errorf("unexpected task name %q: %w", task, err)
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.PendingTestRun{
+		ID:                id,
+		Uploader:          uploader,
+		Stage:             shared.StageWptFyiReceived,
+		ProductAtRevision: product,
+	}
+	// The revision is already recorded by ScheduleResultsTask; the product
+	// is only known to some providers before the results are processed.
+	if product.BrowserName != "" {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		if err := a.UpdatePendingTestRun(uploader, shared.PendingTestRun{ID: id, ProductAtRevision: product}); err != nil {
+			log.Warningf("Failed to record the product of pending run %d: %s", id, err.Error())
+		}
+	}
+
+	return run, nil
+}
@@ -0,0 +1,267 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+const sha = "0123456789012345678901234567890123456789"
+
+type fakeProvider struct {
+	build     *Build
+	parseErr  error
+	artifacts []Artifact
+	listErr   error
+}
+
+func (p fakeProvider) Uploader() string {
+	return "fake-ci"
+}
+
+func (p fakeProvider) ParseEvent(_ *http.Request) (*Build, error) {
+	return p.build, p.parseErr
+}
+
+func (p fakeProvider) ListArtifacts(_ *Build) ([]Artifact, error) {
+	return p.artifacts, p.listErr
+}
+
+func (p fakeProvider) ProductAndLabels(_ *Build, artifact Artifact) (shared.ProductAtRevision, []string) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	product := shared.ProductAtRevision{}
+	if artifact.Name == "chrome" {
+		product.BrowserName = "chrome"
+	}
+
+	return product, []string{artifact.Name, shared.MasterLabel}
+}
+
+func (p fakeProvider) ReportStatus(w http.ResponseWriter, _ *Build, runs []shared.PendingTestRun) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "%d runs", len(runs))
+}
+
+func TestHandleNotification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	provider := fakeProvider{
+		build: &Build{ID: "42", SHA: sha},
+		artifacts: []Artifact{
+			{Name: "chrome", Results: []string{"https://ci/chrome.json"}},
+			{Name: "firefox", Archives: []string{"https://ci/firefox.zip"}},
+		},
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetVersionedHostname().Return("v1.wpt.fyi")
+	a.EXPECT().ReserveUploadQuota("fake-ci", int64(0)).Times(2).Return(nil)
+	a.EXPECT().ListCINotificationsOfBuild("fake-ci", "42").Return(nil, nil)
+	var recorded []shared.CINotification
+	a.EXPECT().PutCINotification(gomock.Any()).Times(2).DoAndReturn(func(n *shared.CINotification) error {
+		n.ID = 7
+		recorded = append(recorded, *n)
+
+		return nil
+	})
+	gomock.InOrder(
+		a.EXPECT().ScheduleResultsTask("fake-ci", []string{"https://ci/chrome.json"}, nil, nil, map[string]string{
+			"revision":     sha,
+			"labels":       "chrome,master",
+			"callback_url": "https://v1.wpt.fyi/api/results/create",
+		}).Return("123", nil),
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		a.EXPECT().UpdatePendingTestRun("fake-ci", shared.PendingTestRun{
+			ID: 123,
+			ProductAtRevision: shared.ProductAtRevision{
+				Product:          shared.Product{BrowserName: "chrome"},
+				FullRevisionHash: sha,
+			},
+		}).Return(nil),
+		a.EXPECT().ScheduleResultsTask("fake-ci", nil, nil, []string{"https://ci/firefox.zip"}, map[string]string{
+			"revision":     sha,
+			"labels":       "firefox,master",
+			"callback_url": "https://v1.wpt.fyi/api/results/create",
+		}).Return("124", nil),
+	)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/notify?build=42", strings.NewReader(`{"id":42}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer secret")
+	HandleNotification(a, provider, w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2 runs", w.Body.String())
+
+	assert.Len(t, recorded, 2)
+	received, handled := recorded[0], recorded[1]
+	assert.Equal(t, shared.CINotificationReceived, received.Outcome)
+	assert.Equal(t, "fake-ci", received.Uploader)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "/notify?build=42", received.URL)
+	assert.Equal(t, []shared.CINotificationHeader{{Name: "Content-Type", Value: "application/json"}}, received.Headers)
+	assert.Equal(t, `{"id":42}`, received.Payload)
+	assert.Equal(t, int64(7), handled.ID)
+	assert.Equal(t, shared.CINotificationSucceeded, handled.Outcome)
+	assert.Equal(t, http.StatusOK, handled.Status)
+	assert.Equal(t, "42", handled.BuildID)
+	assert.Equal(t, []string{"chrome", "firefox"}, handled.Artifacts)
+	assert.Equal(t, []int64{123, 124}, handled.Runs)
+}
+
+func TestHandleNotification_duplicate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	provider := fakeProvider{
+		build: &Build{ID: "42", SHA: sha},
+		artifacts: []Artifact{
+			{Name: "chrome", Results: []string{"https://ci/chrome.json"}},
+			{Name: "firefox", Archives: []string{"https://ci/firefox.zip"}},
+		},
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetVersionedHostname().AnyTimes().Return("v1.wpt.fyi")
+	var handled shared.CINotification
+	a.EXPECT().PutCINotification(gomock.Any()).AnyTimes().DoAndReturn(func(n *shared.CINotification) error {
+		handled = *n
+
+		return nil
+	})
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	earlier := []shared.CINotification{{ID: 1, Artifacts: []string{"chrome"}}}
+	a.EXPECT().ListCINotificationsOfBuild("fake-ci", "42").Return(earlier, nil)
+	a.EXPECT().ReserveUploadQuota("fake-ci", int64(0)).Return(nil)
+	a.EXPECT().ScheduleResultsTask("fake-ci", nil, nil, []string{"https://ci/firefox.zip"}, gomock.Any()).
+		Return("124", nil)
+
+	w := httptest.NewRecorder()
+	HandleNotification(a, provider, w, httptest.NewRequest(http.MethodPost, "/notify", nil))
+	assert.Equal(t, "1 runs", w.Body.String())
+	assert.Equal(t, []string{"firefox"}, handled.Artifacts)
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	earlier = append(earlier, shared.CINotification{ID: 2, Artifacts: []string{"firefox"}})
+	a.EXPECT().ListCINotificationsOfBuild("fake-ci", "42").Return(earlier, nil)
+	w = httptest.NewRecorder()
+	HandleNotification(a, provider, w, httptest.NewRequest(http.MethodPost, "/notify", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, shared.CINotificationDuplicate, handled.Outcome)
+	assert.Nil(t, handled.Artifacts)
+}
+
+func TestHandleNotification_errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().ListCINotificationsOfBuild("fake-ci", "42").AnyTimes().Return(nil, nil)
+	var handled shared.CINotification
+	a.EXPECT().PutCINotification(gomock.Any()).AnyTimes().DoAndReturn(func(n *shared.CINotification) error {
+		handled = *n
+
+		return nil
+	})
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	for _, c := range []struct {
+		name     string
+		provider fakeProvider
+		status   int
+		outcome  shared.CINotificationOutcome
+	}{
+		{
+			"ignored", fakeProvider{parseErr: fmt.Errorf("pending: %w", ErrIgnored)},
+			http.StatusNoContent, shared.CINotificationIgnored,
+		},
+		{
+			"invalid", fakeProvider{parseErr: NewEventError(http.StatusBadRequest, "bad")},
+			http.StatusBadRequest, shared.CINotificationInvalid,
+		},
+		{
+			"parse error", fakeProvider{parseErr: errors.New("oops")},
+			http.StatusInternalServerError, shared.CINotificationFailed,
+		},
+		{
+			"no results", fakeProvider{build: &Build{ID: "42"}},
+			http.StatusNoContent, shared.CINotificationNoResults,
+		},
+		{
+			// The notifications of builds without an ID aren't loaded.
+			"no results without an ID", fakeProvider{build: &Build{}},
+			http.StatusNoContent, shared.CINotificationNoResults,
+		},
+		{
+			"list error", fakeProvider{build: &Build{ID: "42"}, listErr: errors.New("oops")},
+			http.StatusInternalServerError, shared.CINotificationFailed,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HandleNotification(a, c.provider, w, httptest.NewRequest(http.MethodPost, "/notify", strings.NewReader("{}")))
+			assert.Equal(t, c.status, w.Code)
+			assert.Equal(t, c.outcome, handled.Outcome)
+			assert.Equal(t, c.status, handled.Status)
+			assert.NotEmpty(t, handled.Error)
+			// Only the payloads of ignored notifications aren't kept.
+			if c.outcome == shared.CINotificationIgnored {
+				assert.Empty(t, handled.Payload)
+			} else {
+				assert.Equal(t, "{}", handled.Payload)
+			}
+		})
+	}
+}
+
+func TestProcessBuild_partialFailure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	provider := fakeProvider{
+		build: &Build{ID: "42", SHA: sha},
+		artifacts: []Artifact{
+			{Name: "safari", Results: []string{"https://ci/safari.json"}},
+			{Name: "firefox", Results: []string{"https://ci/firefox.json"}},
+		},
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetVersionedHostname().Return("v1.wpt.fyi")
+	gomock.InOrder(
+		a.EXPECT().ReserveUploadQuota("fake-ci", int64(0)).Return(errors.New("over quota")),
+		a.EXPECT().ReserveUploadQuota("fake-ci", int64(0)).Return(nil),
+		a.EXPECT().ScheduleResultsTask("fake-ci", []string{"https://ci/firefox.json"}, nil, nil, gomock.Any()).
+			Return("124", nil),
+	)
+
+	processed := mapset.NewSet()
+	runs, err := ProcessBuild(a, provider, provider.build, processed)
+	assert.ErrorContains(t, err, "artifact safari: over quota")
+	assert.Equal(t, []string{"firefox"}, shared.ToStringSlice(processed))
+	assert.Len(t, runs, 1)
+	assert.Equal(t, int64(124), runs[0].ID)
+	assert.Equal(t, "fake-ci", runs[0].Uploader)
+	assert.Equal(t, sha, runs[0].FullRevisionHash)
+}
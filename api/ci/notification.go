@@ -0,0 +1,285 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/gorilla/mux"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// defaultNotificationsLimit is the number of notifications listed, if no limit
+// is given.
+const defaultNotificationsLimit = 100
+
+// interruptedAfter is how long after its last update a notification which is
+// still being handled is considered to have been interrupted (e.g. by the
+// request deadline), and can be replayed.
+const interruptedAfter = 15 * time.Minute
+
+// unrecordedHeaders are the credential headers which are not recorded, so
+// notifications authenticated by them can't be replayed.
+var unrecordedHeaders = map[string]bool{
+	"Authorization":  true,
+	"Cookie":         true,
+	"X-Gitlab-Token": true,
+}
+
+// replayKey is the context key of the notification which a request replays.
+type replayKey struct{}
+
+// notificationKey is the context key of the notification of a request which
+// is being handled.
+type notificationKey struct{}
+
+// MarkVerified records that the credentials of the notification of the
+// request were verified, so that it can be replayed without them; see
+// IsVerifiedReplay.
+func MarkVerified(r *http.Request) {
+	if notification, ok := r.Context().Value(notificationKey{}).(*shared.CINotification); ok {
+		notification.Verified = true
+	}
+}
+
+// IsVerifiedReplay returns whether the request is a replay (which only admins
+// can request) of a notification whose credentials were verified when it was
+// received. Providers don't verify the credentials of such replays again,
+// since they may no longer be verifiable.
+func IsVerifiedReplay(r *http.Request) bool {
+	notification, ok := r.Context().Value(replayKey{}).(*shared.CINotification)
+
+	return ok && notification.Verified
+}
+
+// recordNotification records the notification of the request as received,
+// before it is handled. The body of the request is read, and replaced with a
+// copy.
+func recordNotification(a receiver.API, uploader string, r *http.Request) (*shared.CINotification, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	notification, replay := r.Context().Value(replayKey{}).(*shared.CINotification)
+	if replay {
+		notification.Replays++
+	} else {
+		var headers []shared.CINotificationHeader
+		for name, values := range r.Header {
+			if unrecordedHeaders[name] {
+				continue
+			}
+			for _, value := range values {
+				headers = append(headers, shared.CINotificationHeader{Name: name, Value: value})
+			}
+		}
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		notification = &shared.CINotification{
+			Uploader: uploader,
+			Method:   r.Method,
+			URL:      r.URL.RequestURI(),
+			Headers:  headers,
+			Payload:  string(body),
+			Received: time.Now(),
+		}
+	}
+	notification.Outcome = shared.CINotificationReceived
+	notification.Status = 0
+	notification.Error = ""
+	if err := a.PutCINotification(notification); err != nil {
+		// The notification is recorded again once it is handled.
+		shared.GetLogger(a.Context()).Warningf("Failed to record %s notification: %s", uploader, err.Error())
+	}
+
+	return notification, nil
+}
+
+// processedArtifacts returns the names of the artifacts of the build which
+// runs were created for by its notifications. Builds without an ID can't be
+// told apart, so none of their artifacts are considered processed.
+func processedArtifacts(a receiver.API, uploader, buildID string) (mapset.Set, error) {
+	if buildID == "" {
+		return mapset.NewSet(), nil
+	}
+	notifications, err := a.ListCINotificationsOfBuild(uploader, buildID)
+	if err != nil {
+		return nil, err
+	}
+	processed := mapset.NewSet()
+	for _, notification := range notifications {
+		for _, artifact := range notification.Artifacts {
+			processed.Add(artifact)
+		}
+	}
+
+	return processed, nil
+}
+
+// notificationWriter records the response to a notification.
+type notificationWriter struct {
+	http.ResponseWriter
+
+	notification *shared.CINotification
+}
+
+func (w notificationWriter) WriteHeader(status int) {
+	w.notification.Status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w notificationWriter) Write(b []byte) (int, error) {
+	if w.notification.Status == 0 {
+		w.notification.Status = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// finish responds to the notification with the error, recording the outcome.
+func (w notificationWriter) finish(outcome shared.CINotificationOutcome, status int, err error) {
+	w.notification.Outcome = outcome
+	w.notification.Error = err.Error()
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		fmt.Fprintln(w, err.Error())
+
+		return
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// discardWriter discards the response to a replayed notification, whose
+// outcome is recorded in the notification instead.
+type discardWriter struct {
+	header http.Header
+}
+
+func (w discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w discardWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w discardWriter) WriteHeader(_ int) {}
+
+// HandleNotificationList responds with the most recently received
+// notifications with the "outcome" param (failed, by default), up to the
+// "limit" param. It is only available to admins.
+func HandleNotificationList(a receiver.API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	outcome := shared.CINotificationOutcome(r.URL.Query().Get("outcome"))
+	if outcome == "" {
+		outcome = shared.CINotificationFailed
+	}
+	limit := defaultNotificationsLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil || limit < 1 {
+			http.Error(w, "Invalid limit: "+param, http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	notifications, err := a.ListCINotifications(outcome, limit)
+	if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to list CI notifications: %s", err.Error())
+		http.Error(w, "Failed to list CI notifications", http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+// HandleNotificationReplay replays the failed (or interrupted) notification of
+// the request through the handler of its route in the router, and responds
+// with the notification and its new outcome. Artifacts which runs were
+// already created for are skipped. It is only available to admins.
+func HandleNotificationReplay(a receiver.API, router http.Handler, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	idParam := mux.Vars(r)["id"]
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID: "+idParam, http.StatusBadRequest)
+
+		return
+	}
+	log := shared.GetLogger(a.Context())
+	notification, err := a.GetCINotification(id)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		http.Error(w, "CI notification not found", http.StatusNotFound)
+
+		return
+	} else if err != nil {
+		log.Errorf("Failed to load CI notification %d: %s", id, err.Error())
+		http.Error(w, "Failed to load CI notification", http.StatusInternalServerError)
+
+		return
+	}
+	interrupted := notification.Outcome == shared.CINotificationReceived &&
+		time.Since(notification.Updated) > interruptedAfter
+	if notification.Outcome != shared.CINotificationFailed && !interrupted {
+		http.Error(w, fmt.Sprintf("CI notification %d is %s, not failed", id, notification.Outcome), http.StatusConflict)
+
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), replayKey{}, notification)
+	replay, err := http.NewRequestWithContext(
+		ctx, notification.Method, notification.URL, strings.NewReader(notification.Payload))
+	if err != nil {
+		http.Error(w, "Invalid CI notification: "+err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	for _, header := range notification.Headers {
+		replay.Header.Add(header.Name, header.Value)
+	}
+	replays := notification.Replays
+	router.ServeHTTP(discardWriter{header: http.Header{}}, replay)
+	if notification.Replays == replays {
+		http.Error(w, "No CI notification handler for "+notification.URL, http.StatusInternalServerError)
+
+		return
+	}
+	log.Infof("Replayed %s notification %d: %s", notification.Uploader, id, notification.Outcome)
+	writeJSON(w, http.StatusOK, notification)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	marshalled, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(marshalled)
+}
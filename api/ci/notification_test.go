@@ -0,0 +1,164 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ci
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestHandleNotificationList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().IsAdmin(gomock.Any()).AnyTimes().Return(true)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	failed := []shared.CINotification{{ID: 1, Uploader: "taskcluster", Outcome: shared.CINotificationFailed}}
+	a.EXPECT().ListCINotifications(shared.CINotificationFailed, defaultNotificationsLimit).Return(failed, nil)
+	a.EXPECT().ListCINotifications(shared.CINotificationIgnored, 5).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	HandleNotificationList(a, w, httptest.NewRequest(http.MethodGet, "/api/ci/notifications", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var listed []shared.CINotification
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, failed, listed)
+
+	w = httptest.NewRecorder()
+	HandleNotificationList(a, w, httptest.NewRequest(http.MethodGet, "/api/ci/notifications?outcome=ignored&limit=5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	HandleNotificationList(a, w, httptest.NewRequest(http.MethodGet, "/api/ci/notifications?limit=0", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleNotificationList_notAdmin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().IsAdmin(gomock.Any()).Return(false)
+
+	w := httptest.NewRecorder()
+	HandleNotificationList(a, w, httptest.NewRequest(http.MethodGet, "/api/ci/notifications", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func newReplayRequest(id string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/ci/notifications/"+id+"/replay", nil)
+
+	return mux.SetURLVars(r, map[string]string{"id": id})
+}
+
+func TestHandleNotificationReplay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	provider := fakeProvider{
+		build: &Build{ID: "42", SHA: sha},
+		artifacts: []Artifact{
+			{Name: "chrome", Results: []string{"https://ci/chrome.json"}},
+			{Name: "firefox", Results: []string{"https://ci/firefox.json"}},
+		},
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	stored := shared.CINotification{
+		ID:        7,
+		Uploader:  "fake-ci",
+		BuildID:   "42",
+		Method:    http.MethodPost,
+		URL:       "/notify?build=42",
+		Headers:   []shared.CINotificationHeader{{Name: "X-Event", Value: "build"}},
+		Payload:   `{"id":42}`,
+		Outcome:   shared.CINotificationFailed,
+		Error:     "artifact firefox: over quota",
+		Artifacts: []string{"chrome"},
+		Runs:      []int64{123},
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().IsAdmin(gomock.Any()).Return(true)
+	a.EXPECT().GetCINotification(int64(7)).Return(&stored, nil)
+	a.EXPECT().PutCINotification(gomock.Any()).Times(2).Return(nil)
+	a.EXPECT().ListCINotificationsOfBuild("fake-ci", "42").Return([]shared.CINotification{stored}, nil)
+	a.EXPECT().GetVersionedHostname().Return("v1.wpt.fyi")
+	a.EXPECT().ReserveUploadQuota("fake-ci", int64(0)).Return(nil)
+	a.EXPECT().ScheduleResultsTask("fake-ci", []string{"https://ci/firefox.json"}, nil, nil, gomock.Any()).
+		Return("124", nil)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/notify", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "42", r.URL.Query().Get("build"))
+		assert.Equal(t, "build", r.Header.Get("X-Event"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"id":42}`, string(body))
+		HandleNotification(a, provider, w, r)
+	}).Methods(http.MethodPost)
+
+	w := httptest.NewRecorder()
+	HandleNotificationReplay(a, router, w, newReplayRequest("7"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var replayed shared.CINotification
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &replayed))
+	assert.Equal(t, int64(7), replayed.ID)
+	assert.Equal(t, shared.CINotificationSucceeded, replayed.Outcome)
+	assert.Equal(t, "", replayed.Error)
+	assert.Equal(t, 1, replayed.Replays)
+	assert.Equal(t, []string{"chrome", "firefox"}, replayed.Artifacts)
+	assert.Equal(t, []int64{123, 124}, replayed.Runs)
+}
+
+func TestHandleNotificationReplay_errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().IsAdmin(gomock.Any()).AnyTimes().Return(true)
+	a.EXPECT().GetCINotification(int64(1)).Return(nil, shared.ErrNoSuchEntity)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	a.EXPECT().GetCINotification(int64(2)).Return(&shared.CINotification{
+		ID: 2, Outcome: shared.CINotificationSucceeded,
+	}, nil)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	a.EXPECT().GetCINotification(int64(3)).Return(&shared.CINotification{
+		ID: 3, Outcome: shared.CINotificationReceived, Updated: time.Now(),
+	}, nil)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	a.EXPECT().GetCINotification(int64(4)).Return(&shared.CINotification{
+		ID: 4, Outcome: shared.CINotificationFailed, Method: http.MethodPost, URL: "/unknown",
+	}, nil)
+
+	for _, c := range []struct {
+		id     string
+		status int
+	}{
+		{"1", http.StatusNotFound},
+		{"2", http.StatusConflict},
+		{"3", http.StatusConflict},
+		{"4", http.StatusInternalServerError},
+	} {
+		t.Run(c.id, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HandleNotificationReplay(a, mux.NewRouter(), w, newReplayRequest(c.id))
+			assert.Equal(t, c.status, w.Code)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package ci is a framework for collecting the results of CI builds (e.g.
+// Taskcluster task groups, Azure Pipelines builds or GitHub Actions workflow
+// runs). Each CI system implements CIProvider, and a shared driver turns the
+// notifications of finished builds into pending runs, scheduling the
+// processing of their results.
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// ErrIgnored is returned by CIProvider.ParseEvent for notifications which are
+// not about builds whose results should be collected.
+var ErrIgnored = errors.New("notification was ignored")
+
+// ErrNoResults is returned by CIProvider.ListArtifacts when a build has no
+// results (yet), e.g. when no task has finished successfully.
+var ErrNoResults = errors.New("no results found in build")
+
+// ErrDuplicate is returned by ProcessBuild when runs were already created for
+// all the artifacts of a build, by earlier notifications.
+var ErrDuplicate = errors.New("all artifacts of the build were already processed")
+
+// Build is a CI build whose results are collected.
+type Build struct {
+	// ID identifies the build within its CI system, e.g. a task group ID.
+	ID    string
+	Owner string
+	Repo  string
+	// SHA is the tested revision of WPT, which can be empty if the results
+	// embed it.
+	SHA string
+	// Sender is the GitHub user who triggered the build, if known.
+	Sender string
+	// Event is the provider-specific information about the build, which is
+	// passed back to the provider.
+	Event interface{}
+}
+
+// Artifact is a set of results of a Build, which is uploaded as a single run.
+type Artifact struct {
+	// Name identifies the artifact within its build, e.g. the product of a
+	// Taskcluster task, or the name of an Azure Pipelines artifact.
+	Name        string
+	Results     []string
+	Screenshots []string
+	Archives    []string
+}
+
+// CIProvider is the interface of a CI system which wpt.fyi collects results
+// from.
+// nolint:revive // CIProvider is clearer than ci.Provider at call sites.
+type CIProvider interface {
+	// Uploader is the name of the uploader which runs from the CI system are
+	// attributed to.
+	Uploader() string
+	// ParseEvent parses the notification that a build has finished. It
+	// returns ErrIgnored for notifications which should be ignored, and an
+	// EventError for invalid ones.
+	ParseEvent(r *http.Request) (*Build, error)
+	// ListArtifacts lists the artifacts of the build which should be uploaded,
+	// or returns ErrNoResults if there are none.
+	ListArtifacts(build *Build) ([]Artifact, error)
+	// ProductAndLabels derives the product (as far as it is known before the
+	// results are processed) and the labels of the run of an artifact.
+	ProductAndLabels(build *Build, artifact Artifact) (shared.ProductAtRevision, []string)
+	// ReportStatus reports the pending runs created for the build back to
+	// the CI system, in the response to its notification.
+	ReportStatus(w http.ResponseWriter, build *Build, runs []shared.PendingTestRun)
+}
+
+// EventError is an error parsing a notification, which is responded to with
+// the given HTTP status.
+type EventError struct {
+	Status int
+	Err    error
+}
+
+// NewEventError returns an EventError with the given status and message.
+func NewEventError(status int, format string, a ...interface{}) EventError {
+	return EventError{Status: status, Err: fmt.Errorf(format, a...)}
+}
+
+func (e EventError) Error() string {
+	return e.Err.Error()
+}
+
+func (e EventError) Unwrap() error {
+	return e.Err
+}
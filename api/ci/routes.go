@@ -0,0 +1,30 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ci
+
+import (
+	"net/http"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// RegisterRoutes adds the route handlers of CI notifications.
+func RegisterRoutes() {
+	// ADMIN API endpoint for listing CI notifications by outcome.
+	shared.AddRoute("/api/ci/notifications", "api-ci-notifications", notificationsHandler).Methods("GET")
+	// ADMIN API endpoint for replaying a failed CI notification through the
+	// handler of its route.
+	shared.AddRoute("/api/ci/notifications/{id:[0-9]+}/replay", "api-ci-notification-replay",
+		notificationReplayHandler).Methods("POST")
+}
+
+func notificationsHandler(w http.ResponseWriter, r *http.Request) {
+	HandleNotificationList(receiver.NewAPI(r.Context()), w, r)
+}
+
+func notificationReplayHandler(w http.ResponseWriter, r *http.Request) {
+	HandleNotificationReplay(receiver.NewAPI(r.Context()), shared.Router(), w, r)
+}
@@ -0,0 +1,15 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package generic
+
+import "github.com/web-platform-tests/wpt.fyi/shared"
+
+// RegisterRoutes adds all the api route handlers.
+func RegisterRoutes() {
+	// PROTECTED API endpoint for notifying wpt.fyi of the results of a build
+	// of a third-party CI, signed with the secret of the uploader.
+	shared.AddRoute("/api/webhook/generic/{uploader}", "api-webhook-generic", webhookHandler).
+		Methods("POST")
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package generic receives signed webhook notifications of finished builds
+// from the CI systems of third-party runners, which list the URLs of their
+// results. Adapters for the webhooks of specific CI systems (e.g. GitLab)
+// convert their events into the generic Payload.
+package generic
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/api/webhooks"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// maxNotificationAge is how long after its signed timestamp a notification is
+// accepted, so that a captured notification can't be replayed later. It
+// allows for some clock skew, in both directions.
+const maxNotificationAge = 5 * time.Minute
+
+// Payload is the JSON payload of a generic webhook notification.
+type Payload struct {
+	// ID identifies the build within the CI system, e.g. a pipeline ID.
+	ID string `json:"id"`
+	// Revision is the full SHA of the tested WPT revision, which can be
+	// omitted if the results embed it.
+	Revision string `json:"revision,omitempty"`
+	// Product is the tested product, as far as it is known; the results
+	// are authoritative.
+	Product shared.Product `json:"product"`
+	// Labels are added to the runs of all the artifacts.
+	Labels    []string   `json:"labels,omitempty"`
+	Artifacts []Artifact `json:"artifacts"`
+}
+
+// Artifact is a set of results of the build, which is uploaded as a single
+// run.
+type Artifact struct {
+	Name           string   `json:"name"`
+	ResultURLs     []string `json:"result_urls,omitempty"`
+	ScreenshotURLs []string `json:"screenshot_urls,omitempty"`
+	ArchiveURLs    []string `json:"archive_urls,omitempty"`
+	// Labels are added to the run of the artifact.
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Validate checks that the payload has an ID, and results at valid URLs.
+func (p Payload) Validate() error {
+	// The artifacts of a build which were already processed are skipped by
+	// its ID, so builds without one would be mistaken for each other.
+	if p.ID == "" {
+		return errors.New("no id")
+	}
+	if len(p.Artifacts) == 0 {
+		return errors.New("no artifacts")
+	}
+	for _, artifact := range p.Artifacts {
+		if len(artifact.ResultURLs) == 0 && len(artifact.ArchiveURLs) == 0 {
+			return fmt.Errorf("artifact %q has no result_urls or archive_urls", artifact.Name)
+		}
+		for _, urls := range [][]string{artifact.ResultURLs, artifact.ScreenshotURLs, artifact.ArchiveURLs} {
+			for _, u := range urls {
+				if parsed, err := url.Parse(u); err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+					return fmt.Errorf("artifact %q has an invalid URL %q", artifact.Name, u)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Provider is the ci.CIProvider of the generic webhook of an uploader. It is
+// embedded by the providers of adapters, which only parse events differently.
+type Provider struct {
+	a        receiver.API
+	uploader string
+}
+
+// NewProvider returns the Provider of the generic webhook of the uploader.
+func NewProvider(a receiver.API, uploader string) Provider {
+	return Provider{a: a, uploader: uploader}
+}
+
+func (p Provider) Uploader() string {
+	return p.uploader
+}
+
+// Secret returns the webhook secret of the uploader (see
+// receiver.WebhookSecretName), which the notifications of its webhooks are
+// verified with.
+func (p Provider) Secret() (string, error) {
+	return p.a.GetWebhookSecret(p.uploader)
+}
+
+// ParseEvent parses a Payload, which is signed in the webhooks.SignatureHeader
+// along with the webhooks.TimestampHeader (see webhooks.SignTimestamped) with
+// the webhook secret of the uploader. Notifications sent more than
+// maxNotificationAge ago are rejected, unless an admin replays a notification
+// which was verified when it was received (see ci.IsVerifiedReplay).
+func (p Provider) ParseEvent(r *http.Request) (*ci.Build, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !ci.IsVerifiedReplay(r) {
+		if err := p.verify(r, body); err != nil {
+			return nil, err
+		}
+		ci.MarkVerified(r)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "invalid payload: %s", err.Error())
+	}
+
+	return NewBuild(payload)
+}
+
+// verify checks the signature and the freshness of the timestamp of the
+// notification.
+func (p Provider) verify(r *http.Request, body []byte) error {
+	secret, err := p.Secret()
+	if err != nil {
+		shared.GetLogger(p.a.Context()).Warningf("Failed to get the webhook secret of %s: %s", p.uploader, err.Error())
+
+		return ci.NewEventError(http.StatusUnauthorized, "unknown uploader %s", p.uploader)
+	}
+	timestamp := r.Header.Get(webhooks.TimestampHeader)
+	signature := r.Header.Get(webhooks.SignatureHeader)
+	if !hmac.Equal([]byte(signature), []byte(webhooks.SignTimestamped(secret, timestamp, body))) {
+		return ci.NewEventError(http.StatusUnauthorized, "invalid %s", webhooks.SignatureHeader)
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ci.NewEventError(http.StatusUnauthorized, "invalid %s %q", webhooks.TimestampHeader, timestamp)
+	}
+	if age := time.Since(time.Unix(sent, 0)); age > maxNotificationAge || age < -maxNotificationAge {
+		return ci.NewEventError(http.StatusUnauthorized, "stale %s %s", webhooks.TimestampHeader, timestamp)
+	}
+
+	return nil
+}
+
+// NewBuild validates the payload, and returns the build it describes.
+func NewBuild(payload Payload) (*ci.Build, error) {
+	if err := payload.Validate(); err != nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "invalid payload: %s", err.Error())
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &ci.Build{
+		ID:    payload.ID,
+		SHA:   payload.Revision,
+		Event: payload,
+	}, nil
+}
+
+func (p Provider) ListArtifacts(b *ci.Build) ([]ci.Artifact, error) {
+	payload := b.Event.(Payload)
+	artifacts := make([]ci.Artifact, len(payload.Artifacts))
+	for i, artifact := range payload.Artifacts {
+		artifacts[i] = ci.Artifact{
+			Name:        artifact.Name,
+			Results:     artifact.ResultURLs,
+			Screenshots: artifact.ScreenshotURLs,
+			Archives:    artifact.ArchiveURLs,
+		}
+	}
+
+	return artifacts, nil
+}
+
+func (p Provider) ProductAndLabels(b *ci.Build, artifact ci.Artifact) (shared.ProductAtRevision, []string) {
+	payload := b.Event.(Payload)
+	labels := shared.NewSetFromStringSlice(payload.Labels)
+	for _, a := range payload.Artifacts {
+		if a.Name == artifact.Name {
+			for _, label := range a.Labels {
+				labels.Add(label)
+			}
+		}
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.ProductAtRevision{
+		Product:          payload.Product,
+		FullRevisionHash: payload.Revision,
+	}, shared.ToStringSlice(labels)
+}
+
+// buildStatus is the response to a notification.
+type buildStatus struct {
+	ID   string                  `json:"id"`
+	Runs []shared.PendingTestRun `json:"runs"`
+}
+
+// ReportStatus responds with the pending runs of the build, whose progress
+// can be followed at /api/status/{id}.
+func (p Provider) ReportStatus(w http.ResponseWriter, b *ci.Build, runs []shared.PendingTestRun) {
+	body, err := json.Marshal(buildStatus{ID: b.ID, Runs: runs})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(body)
+}
+
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
+	a := receiver.NewAPI(r.Context())
+	ci.HandleNotification(a, NewProvider(a, mux.Vars(r)["uploader"]), w, r)
+}
@@ -0,0 +1,238 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package generic
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/api/webhooks"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+const sha = "0123456789012345678901234567890123456789"
+
+func newPayload() Payload {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return Payload{
+		ID:       "build-1",
+		Revision: sha,
+		Product:  shared.Product{BrowserName: "servo", BrowserVersion: "0.1"},
+		Labels:   []string{"nightly"},
+		Artifacts: []Artifact{
+			{Name: "results", ResultURLs: []string{"https://ci.example/results.json.gz"}, Labels: []string{"experimental"}},
+			{Name: "archive", ArchiveURLs: []string{"https://ci.example/results.zip"}},
+		},
+	}
+}
+
+func newSignedRequestAt(t *testing.T, payload Payload, secret string, sent time.Time) *http.Request {
+	body, err := json.Marshal(payload)
+	assert.Nil(t, err)
+	r := httptest.NewRequest(http.MethodPost, "/api/webhook/generic/servo", strings.NewReader(string(body)))
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+	r.Header.Set(webhooks.TimestampHeader, timestamp)
+	r.Header.Set(webhooks.SignatureHeader, webhooks.SignTimestamped(secret, timestamp, body))
+
+	return r
+}
+
+func newSignedRequest(t *testing.T, payload Payload, secret string) *http.Request {
+	return newSignedRequestAt(t, payload, secret, time.Now())
+}
+
+func TestPayloadValidate(t *testing.T) {
+	assert.Nil(t, newPayload().Validate())
+
+	payload := newPayload()
+	payload.ID = ""
+	assert.EqualError(t, payload.Validate(), "no id")
+
+	payload = newPayload()
+	payload.Artifacts = nil
+	assert.EqualError(t, payload.Validate(), "no artifacts")
+
+	payload = newPayload()
+	payload.Artifacts[1].ArchiveURLs = nil
+	assert.EqualError(t, payload.Validate(), `artifact "archive" has no result_urls or archive_urls`)
+
+	payload = newPayload()
+	payload.Artifacts[0].ScreenshotURLs = []string{"file:///etc/passwd"}
+	assert.EqualError(t, payload.Validate(), `artifact "results" has an invalid URL "file:///etc/passwd"`)
+}
+
+func TestProviderParseEvent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetWebhookSecret("servo").AnyTimes().Return("secret", nil)
+	p := NewProvider(a, "servo")
+
+	build, err := p.ParseEvent(newSignedRequest(t, newPayload(), "secret"))
+	assert.Nil(t, err)
+	assert.Equal(t, "build-1", build.ID)
+	assert.Equal(t, sha, build.SHA)
+
+	artifacts, err := p.ListArtifacts(build)
+	assert.Nil(t, err)
+	assert.Equal(t, []ci.Artifact{
+		{Name: "results", Results: []string{"https://ci.example/results.json.gz"}},
+		{Name: "archive", Archives: []string{"https://ci.example/results.zip"}},
+	}, artifacts)
+
+	product, labels := p.ProductAndLabels(build, artifacts[0])
+	assert.Equal(t, "servo", product.BrowserName)
+	assert.Equal(t, "0.1", product.BrowserVersion)
+	assert.Equal(t, sha, product.FullRevisionHash)
+	assert.ElementsMatch(t, []string{"nightly", "experimental"}, labels)
+	_, labels = p.ProductAndLabels(build, artifacts[1])
+	assert.ElementsMatch(t, []string{"nightly"}, labels)
+}
+
+func TestProviderParseEvent_errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetWebhookSecret("servo").AnyTimes().Return("secret", nil)
+	a.EXPECT().GetWebhookSecret("nobody").AnyTimes().Return("", receiver.ErrNoWebhookSecret)
+	a.EXPECT().GetWebhookSecret("broken").AnyTimes().Return("", errors.New("datastore unavailable"))
+
+	invalid := newPayload()
+	invalid.Artifacts = nil
+	unsigned := newSignedRequest(t, newPayload(), "secret")
+	unsigned.Header.Del(webhooks.TimestampHeader)
+	retimed := newSignedRequest(t, newPayload(), "secret")
+	retimed.Header.Set(webhooks.TimestampHeader, strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	for _, c := range []struct {
+		name     string
+		uploader string
+		r        *http.Request
+		status   int
+	}{
+		{"wrong secret", "servo", newSignedRequest(t, newPayload(), "guess"), http.StatusUnauthorized},
+		{"no webhook secret", "nobody", newSignedRequest(t, newPayload(), ""), http.StatusUnauthorized},
+		{"secret error", "broken", newSignedRequest(t, newPayload(), ""), http.StatusUnauthorized},
+		{"no timestamp", "servo", unsigned, http.StatusUnauthorized},
+		{"unsigned timestamp", "servo", retimed, http.StatusUnauthorized},
+		{"stale", "servo", newSignedRequestAt(t, newPayload(), "secret", time.Now().Add(-time.Hour)), http.StatusUnauthorized},
+		{"future", "servo", newSignedRequestAt(t, newPayload(), "secret", time.Now().Add(time.Hour)), http.StatusUnauthorized},
+		{"invalid payload", "servo", newSignedRequest(t, invalid, "secret"), http.StatusBadRequest},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := NewProvider(a, c.uploader).ParseEvent(c.r)
+			var eventErr ci.EventError
+			assert.ErrorAs(t, err, &eventErr)
+			assert.Equal(t, c.status, eventErr.Status)
+		})
+	}
+}
+
+func TestHandleNotification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetWebhookSecret("servo").Return("secret", nil)
+	a.EXPECT().GetVersionedHostname().Return("wpt.fyi")
+	// The notification is recorded when it's received, once it's verified,
+	// and with its outcome.
+	a.EXPECT().PutCINotification(gomock.Any()).Times(3).Return(nil)
+	a.EXPECT().ListCINotificationsOfBuild("servo", "build-1").Return(nil, nil)
+	a.EXPECT().ReserveUploadQuota("servo", int64(0)).Times(2).Return(nil)
+	a.EXPECT().ScheduleResultsTask(
+		"servo", []string{"https://ci.example/results.json.gz"}, nil, nil, gomock.Any()).Return("1", nil)
+	a.EXPECT().ScheduleResultsTask(
+		"servo", nil, nil, []string{"https://ci.example/results.zip"}, gomock.Any()).Return("2", nil)
+	a.EXPECT().UpdatePendingTestRun(gomock.Any(), gomock.Any()).Times(2).Return(nil)
+
+	w := httptest.NewRecorder()
+	ci.HandleNotification(a, NewProvider(a, "servo"), w, newSignedRequest(t, newPayload(), "secret"))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var status buildStatus
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "build-1", status.ID)
+	assert.Len(t, status.Runs, 2)
+	assert.Equal(t, int64(2), status.Runs[1].ID)
+	assert.Equal(t, "servo", status.Runs[1].BrowserName)
+}
+
+func TestHandleNotificationReplay_stale(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// The notification was interrupted, long after its timestamp went stale.
+	signed := newSignedRequestAt(t, newPayload(), "secret", time.Now().Add(-time.Hour))
+	body, _ := io.ReadAll(signed.Body)
+	newStored := func(verified bool) *shared.CINotification {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		return &shared.CINotification{
+			ID:       7,
+			Uploader: "servo",
+			Method:   http.MethodPost,
+			URL:      "/api/webhook/generic/servo",
+			Headers: []shared.CINotificationHeader{
+				{Name: webhooks.TimestampHeader, Value: signed.Header.Get(webhooks.TimestampHeader)},
+				{Name: webhooks.SignatureHeader, Value: signed.Header.Get(webhooks.SignatureHeader)},
+			},
+			Payload:  string(body),
+			Outcome:  shared.CINotificationReceived,
+			Updated:  time.Now().Add(-time.Hour),
+			Verified: verified,
+		}
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().IsAdmin(gomock.Any()).AnyTimes().Return(true)
+	a.EXPECT().PutCINotification(gomock.Any()).AnyTimes().Return(nil)
+	router := mux.NewRouter()
+	router.HandleFunc("/api/webhook/generic/{uploader}", func(w http.ResponseWriter, r *http.Request) {
+		ci.HandleNotification(a, NewProvider(a, mux.Vars(r)["uploader"]), w, r)
+	})
+	replay := func() *shared.CINotification {
+		w := httptest.NewRecorder()
+		r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/ci/notifications/7/replay", nil),
+			map[string]string{"id": "7"})
+		ci.HandleNotificationReplay(a, router, w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var replayed shared.CINotification
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &replayed))
+
+		return &replayed
+	}
+
+	// A notification which wasn't verified when it was received is verified
+	// again, and its stale timestamp rejected.
+	a.EXPECT().GetCINotification(int64(7)).Return(newStored(false), nil)
+	a.EXPECT().GetWebhookSecret("servo").Return("secret", nil)
+	assert.Equal(t, shared.CINotificationInvalid, replay().Outcome)
+
+	// A verified one is replayed.
+	a.EXPECT().GetCINotification(int64(7)).Return(newStored(true), nil)
+	a.EXPECT().GetVersionedHostname().Return("wpt.fyi")
+	a.EXPECT().ListCINotificationsOfBuild("servo", "build-1").Return(nil, nil)
+	a.EXPECT().ReserveUploadQuota("servo", int64(0)).Times(2).Return(nil)
+	a.EXPECT().ScheduleResultsTask("servo", gomock.Any(), nil, gomock.Any(), gomock.Any()).Times(2).Return("1", nil)
+	a.EXPECT().UpdatePendingTestRun(gomock.Any(), gomock.Any()).Times(2).Return(nil)
+	assert.Equal(t, shared.CINotificationSucceeded, replay().Outcome)
+}
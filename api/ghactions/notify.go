@@ -14,8 +14,10 @@ import (
 	mapset "github.com/deckarep/golang-set"
 	"github.com/gobwas/glob"
 	"github.com/google/go-github/v90/github"
-	uc "github.com/web-platform-tests/wpt.fyi/api/receiver/client"
 
+	"github.com/web-platform-tests/wpt.fyi/api/checks"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
@@ -28,116 +30,122 @@ var (
 )
 
 func notifyHandler(w http.ResponseWriter, r *http.Request) {
-	rawRunID := r.FormValue("run_id")
-	var runID int64
-	var err error
-	if runID, err = strconv.ParseInt(rawRunID, 0, 0); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid run id: %s", rawRunID), http.StatusBadRequest)
+	ctx := r.Context()
+	p := provider{ctx: ctx, aeAPI: shared.NewAppEngineAPI(ctx)}
+	ci.HandleNotification(receiver.NewAPI(ctx), p, w, r)
+}
+
+// provider is the ci.CIProvider of GitHub Actions workflow runs.
+type provider struct {
+	ctx   context.Context // nolint:containedctx // TODO: Fix containedctx lint error
+	aeAPI shared.AppEngineAPI
+}
+
+// workflowRunEvent is the GitHub Actions-specific information of a ci.Build.
+type workflowRunEvent struct {
+	ghClient         *github.Client
+	workflowRun      *github.WorkflowRun
+	runID            int64
+	artifactNameGlob glob.Glob
+	checkRepo        shared.CheckRepo
+}
+
+func (p provider) Uploader() string {
+	return uploaderName
+}
 
-		return
+// ParseEvent parses the notification that the workflow run given by the run_id
+// param, of web-platform-tests/wpt or another repo configured by a CheckRepo
+// entity, has finished. Only the artifacts matching the artifact_name glob
+// param are uploaded.
+func (p provider) ParseEvent(r *http.Request) (*ci.Build, error) {
+	rawRunID := r.FormValue("run_id")
+	runID, err := strconv.ParseInt(rawRunID, 0, 0)
+	if err != nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "Invalid run id: %s", rawRunID)
 	}
 
 	owner := r.FormValue("owner")
 	repo := r.FormValue("repo")
-
-	if owner != shared.WPTRepoOwner || repo != shared.WPTRepoName {
-		http.Error(w, fmt.Sprintf("Invalid repo: %s/%s", owner, repo), http.StatusBadRequest)
-
-		return
+	checkRepo, err := checks.NewAPI(p.ctx).GetCheckRepo(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the config of %s/%s: %w", owner, repo, err)
+	} else if checkRepo == nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "Invalid repo: %s/%s", owner, repo)
 	}
 
 	artifactName := r.FormValue("artifact_name")
 	artifactNameGlob, err := glob.Compile(artifactName)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Invalid artifact name: %s", artifactName), http.StatusBadRequest)
-
-		return
+		return nil, ci.NewEventError(http.StatusBadRequest, "Invalid artifact name: %s", artifactName)
 	}
 
-	ctx := r.Context()
-	aeAPI := shared.NewAppEngineAPI(ctx)
-	log := shared.GetLogger(ctx)
-
-	ghClient, err := aeAPI.GetGitHubClient()
+	ghClient, err := p.aeAPI.GetGitHubClient()
 	if err != nil {
-		log.Errorf("Failed to get GitHub client: %s", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
+		return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 	}
-
-	processed, err := processBuild(
-		ctx,
-		aeAPI,
-		ghClient,
-		owner,
-		repo,
-		runID,
-		artifactNameGlob,
-	)
-
+	workflowRun, _, err := ghClient.Actions.GetWorkflowRunByID(p.ctx, owner, repo, runID)
 	if err != nil {
-		log.Errorf("%v", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
+		return nil, err
 	}
 
-	if processed {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, "GitHub Actions workflow run artifacts retrieved successfully")
-	} else {
-		w.WriteHeader(http.StatusNoContent)
-		fmt.Fprintln(w, "Notification of workflow run artifacts was ignored")
+	var sha string
+	if workflowRun.GetEvent() == "pull_request" {
+		sha = workflowRun.GetHeadSHA()
 	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &ci.Build{
+		ID:    rawRunID,
+		Owner: owner,
+		Repo:  repo,
+		SHA:   sha,
+		Event: workflowRunEvent{
+			ghClient:         ghClient,
+			workflowRun:      workflowRun,
+			runID:            runID,
+			artifactNameGlob: artifactNameGlob,
+			checkRepo:        *checkRepo,
+		},
+	}, nil
 }
 
-func processBuild(
-	ctx context.Context,
-	aeAPI shared.AppEngineAPI,
-	ghClient *github.Client,
-	owner string,
-	repo string,
-	runID int64,
-	artifactNameGlob glob.Glob,
-) (bool, error) {
-	log := shared.GetLogger(ctx)
-
-	workflowRun, _, err := ghClient.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
-	if err != nil {
-		return false, err
-	}
+// ListArtifacts returns all the artifacts of the workflow run which match the
+// glob as a single artifact, named after the first one, so that they are
+// uploaded as a single run.
+func (p provider) ListArtifacts(b *ci.Build) ([]ci.Artifact, error) {
+	log := shared.GetLogger(p.ctx)
+	event := b.Event.(workflowRunEvent)
 
 	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
 	opts := &github.ListOptions{PerPage: 100}
 
-	archiveURLs := []string{}
-
-	var labels mapset.Set
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	upload := ci.Artifact{}
 
 	for {
-		artifacts, resp, err := ghClient.Actions.ListWorkflowRunArtifacts(ctx, owner, repo, runID, opts)
+		artifacts, resp, err := event.ghClient.Actions.ListWorkflowRunArtifacts(p.ctx, b.Owner, b.Repo, event.runID, opts)
 
 		if err != nil {
-			return false, err
+			return nil, err
 		}
 
 		for _, artifact := range artifacts.Artifacts {
 
-			if !artifactNameGlob.Match(*artifact.Name) {
-				log.Infof("Skipping artifact %s", *artifact.Name)
+			if !event.artifactNameGlob.Match(artifact.GetName()) {
+				log.Infof("Skipping artifact %s", artifact.GetName())
 
 				continue
 			}
 
-			log.Infof("Adding %s for %s/%s run %v to upload...", *artifact.Name, owner, repo, runID)
+			log.Infof("Adding %s for %s/%s run %v to upload...", artifact.GetName(), b.Owner, b.Repo, event.runID)
 
 			// Set the labels based on the first artifact we find.
-			if len(archiveURLs) == 0 {
-				labels = chooseLabels(workflowRun, *artifact.Name, owner, repo)
+			if len(upload.Archives) == 0 {
+				upload.Name = artifact.GetName()
 			}
 
-			archiveURLs = append(archiveURLs, *artifact.ArchiveDownloadURL)
+			upload.Archives = append(upload.Archives, artifact.GetArchiveDownloadURL())
 		}
 
 		if resp.NextPage == 0 {
@@ -146,48 +154,47 @@ func processBuild(
 		opts.Page = resp.NextPage
 	}
 
-	var sha string
-	if *workflowRun.Event == "pull_request" {
-		sha = *workflowRun.HeadSHA
+	if len(upload.Archives) == 0 {
+		return nil, nil
 	}
 
-	uploader, err := aeAPI.GetUploader(uploaderName)
-	if err != nil {
-		return false, fmt.Errorf("failed to get uploader creds from Datastore: %w", err)
-	}
+	return []ci.Artifact{upload}, nil
+}
 
-	uploadClient := uc.NewClient(aeAPI)
-	err = uploadClient.CreateRun(
-		sha,
-		uploader.Username,
-		uploader.Password,
-		nil,
-		nil,
-		archiveURLs,
-		shared.ToStringSlice(labels))
+func (p provider) ProductAndLabels(b *ci.Build, artifact ci.Artifact) (shared.ProductAtRevision, []string) {
+	event := b.Event.(workflowRunEvent)
+	labels := chooseLabels(event.workflowRun, artifact.Name, event.checkRepo)
 
-	if err != nil {
-		return false, fmt.Errorf("failed to create run: %w", err)
-	}
+	// The product is only known from the results.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.ProductAtRevision{}, shared.ToStringSlice(labels)
+}
 
-	return true, nil
+func (p provider) ReportStatus(w http.ResponseWriter, _ *ci.Build, _ []shared.PendingTestRun) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "GitHub Actions workflow run artifacts retrieved successfully")
 }
 
 func chooseLabels( // nolint:ireturn // TODO: Fix ireturn lint error
 	workflowRun *github.WorkflowRun,
 	artifactName string,
-	owner string,
-	repo string,
+	checkRepo shared.CheckRepo,
 ) mapset.Set {
 	labels := mapset.NewSet()
 
+	// The runs of repos other than wpt are labelled with their repo, so that
+	// they are only compared with each other.
+	if repoLabel := checkRepo.RepoLabel(); repoLabel != "" {
+		labels.Add(repoLabel)
+	}
+
 	// We don't actually check the event here, provided it meets
 	// the criteria to be a run on master.
-	if (*workflowRun.HeadRepository.Owner.Login == owner &&
-		*workflowRun.HeadRepository.Name == repo) &&
-		(*workflowRun.HeadBranch == "master" ||
+	if (*workflowRun.HeadRepository.Owner.Login == checkRepo.Owner &&
+		*workflowRun.HeadRepository.Name == checkRepo.Repo) &&
+		(*workflowRun.HeadBranch == checkRepo.GetMasterBranch() ||
 			epochBranchesRegex.MatchString(*workflowRun.HeadBranch)) {
-		labels.Add(shared.MasterLabel)
+		labels.Add(checkRepo.MasterLabel())
 	} else if *workflowRun.Event == "pull_request" {
 		if prHeadRegex.MatchString(artifactName) {
 			labels.Add(shared.PRHeadLabel)
@@ -11,6 +11,8 @@ import (
 
 	"github.com/google/go-github/v90/github"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
 func PointerTo[T any](v T) *T {
@@ -37,6 +39,9 @@ func TestEpochBranchesRegex(t *testing.T) {
 }
 
 func TestChooseLabels(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	wpt := shared.CheckRepo{Owner: "web-platform-tests", Repo: "wpt"}
+
 	wptOrgUser := github.User{
 		Login: PointerTo("web-platform-tests"),
 	}
@@ -99,33 +104,52 @@ func TestChooseLabels(t *testing.T) {
 
 	assert.ElementsMatch(
 		t,
-		chooseLabels(&masterWorkflowRun, "results-safari-1", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&masterWorkflowRun, "results-safari-1", wpt).ToSlice(),
 		[]string{"master"},
 	)
 
 	assert.ElementsMatch(t,
-		chooseLabels(&masterOtherWorkflowRun, "results-safari-1", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&masterOtherWorkflowRun, "results-safari-1", wpt).ToSlice(),
 		[]string{},
 	)
 
 	assert.ElementsMatch(t,
-		chooseLabels(&prWorkflowRun, "results-safari-1", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&prWorkflowRun, "results-safari-1", wpt).ToSlice(),
 		[]string{},
 	)
 
 	assert.ElementsMatch(t,
-		chooseLabels(&prOtherWorkflowRun, "results-safari-1", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&prOtherWorkflowRun, "results-safari-1", wpt).ToSlice(),
 		[]string{},
 	)
 
 	assert.ElementsMatch(
 		t,
-		chooseLabels(&prWorkflowRun, "results-safari-1-affected-tests", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&prWorkflowRun, "results-safari-1-affected-tests", wpt).ToSlice(),
 		[]string{"pr_head"},
 	)
 
 	assert.ElementsMatch(t,
-		chooseLabels(&prOtherWorkflowRun, "results-safari-1-affected-tests-without-changes", "web-platform-tests", "wpt").ToSlice(),
+		chooseLabels(&prOtherWorkflowRun, "results-safari-1-affected-tests-without-changes", wpt).ToSlice(),
 		[]string{"pr_base"},
 	)
+
+	// The master runs of other repos are labelled with their repo instead.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	fork := shared.CheckRepo{Owner: "xxx", Repo: "wpt", MasterBranch: "main"}
+	assert.ElementsMatch(t,
+		chooseLabels(&masterOtherWorkflowRun, "results-safari-1", fork).ToSlice(),
+		[]string{"repo:xxx/wpt"},
+	)
+
+	masterOtherWorkflowRun.HeadBranch = PointerTo("main")
+	assert.ElementsMatch(t,
+		chooseLabels(&masterOtherWorkflowRun, "results-safari-1", fork).ToSlice(),
+		[]string{"repo:xxx/wpt", "repo_master"},
+	)
+
+	assert.ElementsMatch(t,
+		chooseLabels(&prOtherWorkflowRun, "results-safari-1-affected-tests", fork).ToSlice(),
+		[]string{"repo:xxx/wpt", "pr_head"},
+	)
 }
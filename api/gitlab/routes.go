@@ -0,0 +1,15 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package gitlab
+
+import "github.com/web-platform-tests/wpt.fyi/shared"
+
+// RegisterRoutes adds all the api route handlers.
+func RegisterRoutes() {
+	// PROTECTED API endpoint for GitLab pipeline events, whose secret token is
+	// the secret of the uploader.
+	shared.AddRoute("/api/webhook/gitlab/{uploader}", "api-webhook-gitlab", webhookHandler).
+		Methods("POST")
+}
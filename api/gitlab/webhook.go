@@ -0,0 +1,183 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package gitlab adapts the pipeline events of GitLab CI webhooks to the
+// generic webhook of third-party runners.
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/gorilla/mux"
+
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/generic"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+)
+
+// TokenHeader is the header carrying the secret token of a GitLab webhook.
+const TokenHeader = "X-Gitlab-Token"
+
+// EventHeader is the header carrying the name of a GitLab webhook event.
+const EventHeader = "X-Gitlab-Event"
+
+const pipelineEvent = "Pipeline Hook"
+
+// PipelineEvent is the subset of a GitLab pipeline event which wpt.fyi uses.
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#pipeline-events
+type PipelineEvent struct {
+	ObjectKind       string          `json:"object_kind"`
+	ObjectAttributes PipelineAttrs   `json:"object_attributes"`
+	Project          Project         `json:"project"`
+	Builds           []PipelineBuild `json:"builds"`
+}
+
+// PipelineAttrs are the attributes of the pipeline of a PipelineEvent.
+type PipelineAttrs struct {
+	ID     int64  `json:"id"`
+	Ref    string `json:"ref"`
+	SHA    string `json:"sha"`
+	Status string `json:"status"`
+}
+
+// Project is the project of a PipelineEvent.
+type Project struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+// PipelineBuild is a job of the pipeline of a PipelineEvent.
+type PipelineBuild struct {
+	ID            int64         `json:"id"`
+	Name          string        `json:"name"`
+	Stage         string        `json:"stage"`
+	Status        string        `json:"status"`
+	ArtifactsFile ArtifactsFile `json:"artifacts_file"`
+}
+
+// ArtifactsFile is the artifacts archive of a PipelineBuild.
+type ArtifactsFile struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+// ArchiveURL is the URL of the artifacts archive of the job.
+func (b PipelineBuild) ArchiveURL(project Project) string {
+	return fmt.Sprintf("%s/-/jobs/%d/artifacts/download", strings.TrimSuffix(project.WebURL, "/"), b.ID)
+}
+
+// Options are the options of a GitLab webhook, given as params of its URL,
+// since the events don't describe the results.
+type Options struct {
+	// Job is a glob of the names of the jobs whose artifacts are results.
+	Job glob.Glob
+	// Revision is the tested WPT revision, if the results don't embed it.
+	Revision string
+	// Labels are added to the runs.
+	Labels []string
+}
+
+// ParseOptions parses the job, revision and labels params of the request.
+func ParseOptions(r *http.Request) (Options, error) {
+	job := r.URL.Query().Get("job")
+	if job == "" {
+		job = "*"
+	}
+	jobGlob, err := glob.Compile(job)
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid job %q: %w", job, err)
+	}
+	var labels []string
+	for _, label := range strings.Split(r.URL.Query().Get("labels"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	return Options{Job: jobGlob, Revision: r.URL.Query().Get("revision"), Labels: labels}, nil
+}
+
+// ToPayload converts a successful pipeline into the generic payload, with an
+// artifact for each successful job matching the options which has artifacts.
+// It returns ci.ErrIgnored for pipelines which are not successful, or have
+// no such jobs.
+func ToPayload(event PipelineEvent, options Options) (generic.Payload, error) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	payload := generic.Payload{
+		ID:       strconv.FormatInt(event.ObjectAttributes.ID, 10),
+		Revision: options.Revision,
+		Labels:   options.Labels,
+	}
+	if event.ObjectKind != "pipeline" || event.ObjectAttributes.Status != "success" {
+		return payload, fmt.Errorf("%s with status %q: %w", event.ObjectKind, event.ObjectAttributes.Status, ci.ErrIgnored)
+	}
+	for _, build := range event.Builds {
+		if build.Status != "success" || build.ArtifactsFile.Filename == "" || !options.Job.Match(build.Name) {
+			continue
+		}
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		payload.Artifacts = append(payload.Artifacts, generic.Artifact{
+			Name:        build.Name,
+			ArchiveURLs: []string{build.ArchiveURL(event.Project)},
+		})
+	}
+	if len(payload.Artifacts) == 0 {
+		return payload, fmt.Errorf("no jobs with artifacts in pipeline %s: %w", payload.ID, ci.ErrIgnored)
+	}
+
+	return payload, nil
+}
+
+// provider adapts GitLab pipeline events to the generic webhook of an
+// uploader.
+type provider struct {
+	generic.Provider
+}
+
+// ParseEvent parses a pipeline event, whose secret token is the webhook secret
+// of the uploader. GitLab doesn't sign its events, so unlike the generic
+// webhook, their timestamps can't be verified; replayed events are skipped as
+// duplicates of their pipeline by ci.HandleNotification. The token isn't
+// recorded, so an admin's replay of an event relies on it having been verified
+// when the event was received (see ci.IsVerifiedReplay).
+func (p provider) ParseEvent(r *http.Request) (*ci.Build, error) {
+	if !ci.IsVerifiedReplay(r) {
+		secret, err := p.Secret()
+		if err != nil || subtle.ConstantTimeCompare([]byte(r.Header.Get(TokenHeader)), []byte(secret)) != 1 {
+			return nil, ci.NewEventError(http.StatusUnauthorized, "invalid %s", TokenHeader)
+		}
+		ci.MarkVerified(r)
+	}
+	if event := r.Header.Get(EventHeader); event != pipelineEvent {
+		return nil, fmt.Errorf("%s event: %w", event, ci.ErrIgnored)
+	}
+	options, err := ParseOptions(r)
+	if err != nil {
+		return nil, ci.EventError{Status: http.StatusBadRequest, Err: err}
+	}
+
+	var event PipelineEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		return nil, ci.NewEventError(http.StatusBadRequest, "invalid pipeline event: %s", err.Error())
+	}
+	payload, err := ToPayload(event, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return generic.NewBuild(payload)
+}
+
+func webhookHandler(w http.ResponseWriter, r *http.Request) {
+	a := receiver.NewAPI(r.Context())
+	p := provider{Provider: generic.NewProvider(a, mux.Vars(r)["uploader"])}
+	ci.HandleNotification(a, p, w, r)
+}
@@ -0,0 +1,174 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/generic"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newEvent() PipelineEvent {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return PipelineEvent{
+		ObjectKind:       "pipeline",
+		ObjectAttributes: PipelineAttrs{ID: 31, Ref: "main", SHA: "abc", Status: "success"},
+		Project:          Project{ID: 1, PathWithNamespace: "servo/wpt", WebURL: "https://gitlab.example/servo/wpt/"},
+		Builds: []PipelineBuild{
+			{ID: 380, Name: "wpt-1", Status: "success", ArtifactsFile: ArtifactsFile{Filename: "artifacts.zip"}},
+			{ID: 381, Name: "wpt-2", Status: "failed", ArtifactsFile: ArtifactsFile{Filename: "artifacts.zip"}},
+			{ID: 382, Name: "lint", Status: "success"},
+			{ID: 383, Name: "build", Status: "success", ArtifactsFile: ArtifactsFile{Filename: "artifacts.zip"}},
+		},
+	}
+}
+
+func parseOptions(t *testing.T, query string) Options {
+	options, err := ParseOptions(httptest.NewRequest(http.MethodPost, "/api/webhook/gitlab/servo?"+query, nil))
+	assert.Nil(t, err)
+
+	return options
+}
+
+func TestParseOptions(t *testing.T) {
+	options := parseOptions(t, "")
+	assert.True(t, options.Job.Match("anything"))
+	assert.Equal(t, "", options.Revision)
+	assert.Nil(t, options.Labels)
+
+	options = parseOptions(t, "job=wpt-*&revision=1234567890&labels=nightly,%20experimental,")
+	assert.True(t, options.Job.Match("wpt-1"))
+	assert.False(t, options.Job.Match("build"))
+	assert.Equal(t, "1234567890", options.Revision)
+	assert.Equal(t, []string{"nightly", "experimental"}, options.Labels)
+
+	_, err := ParseOptions(httptest.NewRequest(http.MethodPost, "/api/webhook/gitlab/servo?job=%5B", nil))
+	assert.NotNil(t, err)
+}
+
+func TestToPayload(t *testing.T) {
+	payload, err := ToPayload(newEvent(), parseOptions(t, "job=wpt-*&labels=nightly"))
+	assert.Nil(t, err)
+	assert.Equal(t, "31", payload.ID)
+	assert.Equal(t, []string{"nightly"}, payload.Labels)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Equal(t, []generic.Artifact{
+		{Name: "wpt-1", ArchiveURLs: []string{"https://gitlab.example/servo/wpt/-/jobs/380/artifacts/download"}},
+	}, payload.Artifacts)
+
+	payload, err = ToPayload(newEvent(), parseOptions(t, ""))
+	assert.Nil(t, err)
+	assert.Len(t, payload.Artifacts, 2)
+}
+
+func TestToPayload_ignored(t *testing.T) {
+	running := newEvent()
+	running.ObjectAttributes.Status = "running"
+	_, err := ToPayload(running, parseOptions(t, ""))
+	assert.ErrorIs(t, err, ci.ErrIgnored)
+
+	_, err = ToPayload(newEvent(), parseOptions(t, "job=lint"))
+	assert.ErrorIs(t, err, ci.ErrIgnored)
+}
+
+func TestProviderParseEvent(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().GetWebhookSecret("servo").AnyTimes().Return("secret", nil)
+	a.EXPECT().GetWebhookSecret("nobody").AnyTimes().Return("", receiver.ErrNoWebhookSecret)
+
+	body, err := json.Marshal(newEvent())
+	assert.Nil(t, err)
+	newRequest := func(token, event string) *http.Request {
+		r := httptest.NewRequest(
+			http.MethodPost, "/api/webhook/gitlab/servo?job=wpt-*&revision=1234567890", strings.NewReader(string(body)))
+		r.Header.Set(TokenHeader, token)
+		r.Header.Set(EventHeader, event)
+
+		return r
+	}
+
+	p := provider{Provider: generic.NewProvider(a, "servo")}
+	build, err := p.ParseEvent(newRequest("secret", pipelineEvent))
+	assert.Nil(t, err)
+	assert.Equal(t, "31", build.ID)
+	assert.Equal(t, "1234567890", build.SHA)
+	artifacts, err := p.ListArtifacts(build)
+	assert.Nil(t, err)
+	assert.Len(t, artifacts, 1)
+
+	_, err = p.ParseEvent(newRequest("secret", "Push Hook"))
+	assert.ErrorIs(t, err, ci.ErrIgnored)
+
+	var eventErr ci.EventError
+	_, err = p.ParseEvent(newRequest("guess", pipelineEvent))
+	assert.ErrorAs(t, err, &eventErr)
+	assert.Equal(t, http.StatusUnauthorized, eventErr.Status)
+
+	_, err = provider{Provider: generic.NewProvider(a, "nobody")}.ParseEvent(newRequest("", pipelineEvent))
+	assert.ErrorAs(t, err, &eventErr)
+	assert.Equal(t, http.StatusUnauthorized, eventErr.Status)
+}
+
+func TestHandleNotificationReplay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// The token of the event isn't recorded, but it was verified when the
+	// event was received.
+	body, err := json.Marshal(newEvent())
+	assert.Nil(t, err)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	stored := shared.CINotification{
+		ID:       7,
+		Uploader: "servo",
+		Method:   http.MethodPost,
+		URL:      "/api/webhook/gitlab/servo?job=wpt-*&revision=1234567890",
+		Headers:  []shared.CINotificationHeader{{Name: EventHeader, Value: pipelineEvent}},
+		Payload:  string(body),
+		Outcome:  shared.CINotificationFailed,
+		Verified: true,
+	}
+	a := mock_receiver.NewMockAPI(mockCtrl)
+	a.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	a.EXPECT().IsAdmin(gomock.Any()).Return(true)
+	a.EXPECT().GetCINotification(int64(7)).Return(&stored, nil)
+	a.EXPECT().PutCINotification(gomock.Any()).Times(2).Return(nil)
+	a.EXPECT().ListCINotificationsOfBuild("servo", "31").Return(nil, nil)
+	a.EXPECT().GetVersionedHostname().Return("wpt.fyi")
+	a.EXPECT().ReserveUploadQuota("servo", int64(0)).Return(nil)
+	a.EXPECT().ScheduleResultsTask("servo", nil, nil, gomock.Any(), gomock.Any()).Return("1", nil)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/webhook/gitlab/{uploader}", func(w http.ResponseWriter, r *http.Request) {
+		p := provider{Provider: generic.NewProvider(a, mux.Vars(r)["uploader"])}
+		ci.HandleNotification(a, p, w, r)
+	})
+	w := httptest.NewRecorder()
+	r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/api/ci/notifications/7/replay", nil),
+		map[string]string{"id": "7"})
+	ci.HandleNotificationReplay(a, router, w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var replayed shared.CINotification
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &replayed))
+	assert.Equal(t, shared.CINotificationSucceeded, replayed.Outcome)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// interopConfigSourceMetadata is the value of the `source` param that requests
+// the config from the wpt-metadata repo rather than the one deployed with the
+// webapp.
+const interopConfigSourceMetadata = "metadata"
+
+// apiInteropConfigHandler serves the interop focus-area config.
+func apiInteropConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	logger := shared.GetLogger(ctx)
+	q := r.URL.Query()
+
+	var config *shared.InteropConfig
+	var err error
+	switch source := q.Get("source"); source {
+	case "":
+		config, err = shared.GetInteropConfig()
+	case interopConfigSourceMetadata:
+		aeAPI := shared.NewAppEngineAPI(ctx)
+		ref := q.Get("ref")
+		config, err = shared.FetchInteropConfig(aeAPI.GetHTTPClientWithTimeout(time.Second*30), &ref)
+	default:
+		http.Error(w, fmt.Sprintf("Invalid source param: %s", source), http.StatusBadRequest)
+
+		return
+	}
+	if err != nil {
+		logger.Errorf("Failed to load interop config: %s", err.Error())
+		http.Error(w, "Failed to load interop config", http.StatusInternalServerError)
+
+		return
+	}
+
+	var res interface{} = config
+	if year := q.Get("year"); year != "" {
+		y := config.GetYear(year)
+		if y == nil {
+			http.Error(w, fmt.Sprintf("Unknown interop year: %s", year), http.StatusNotFound)
+
+			return
+		}
+		res = y
+	}
+
+	marshalled, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	_, err = w.Write(marshalled)
+	if err != nil {
+		logger.Warningf("Failed to write data in api/interop/config handler: %s", err.Error())
+	}
+}
@@ -9,19 +9,27 @@ import (
 	"encoding/json"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
 // apiPendingTestRunsHandler is responsible for emitting JSON for
-// all the non-completed PendingTestRun entities.
+// all the non-completed PendingTestRun entities, or, for /api/status?stuck=true,
+// the numbers of stuck and timed out ones.
 func apiPendingTestRunsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	store := shared.NewAppEngineDatastore(ctx, false)
 
 	filter := strings.ToLower(mux.Vars(r)["filter"])
+	if stuck, _ := strconv.ParseBool(r.URL.Query().Get("stuck")); stuck && filter == "" {
+		emitStuckRunCounts(w, r)
+
+		return
+	}
 	q := store.NewQuery("PendingTestRun")
 	switch filter {
 	case "pending":
@@ -32,6 +40,8 @@ func apiPendingTestRunsHandler(w http.ResponseWriter, r *http.Request) {
 		q = q.Filter("Stage = ", int(shared.StageEmpty))
 	case "duplicate":
 		q = q.Filter("Stage = ", int(shared.StageDuplicate))
+	case "timed_out":
+		q = q.Filter("Stage = ", int(shared.StageTimedOut))
 	case "":
 		// No-op
 	default:
@@ -62,6 +72,82 @@ func apiPendingTestRunsHandler(w http.ResponseWriter, r *http.Request) {
 	emit(r.Context(), w, runs)
 }
 
+// stuckRunCounts is the response of /api/status?stuck=true.
+type stuckRunCounts struct {
+	// Stuck are the numbers of pending runs which have been in each stage for
+	// longer than its timeout, and will be timed out.
+	Stuck map[string]int `json:"stuck"`
+	// TimedOut is the number of pending runs which have timed out.
+	TimedOut int `json:"timed_out"`
+}
+
+// emitStuckRunCounts emits JSON for the numbers of stuck and timed out
+// PendingTestRun entities.
+func emitStuckRunCounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	timeouts, err := shared.LoadPendingRunTimeouts(store)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	var runs []shared.PendingTestRun
+	if _, err := store.GetAll(
+		store.NewQuery("PendingTestRun").Filter("Stage < ", int(shared.StageValid)), &runs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	timedOut, err := store.GetAll(
+		store.NewQuery("PendingTestRun").Filter("Stage = ", int(shared.StageTimedOut)).KeysOnly(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	emit(ctx, w, stuckRunCounts{
+		Stuck:    timeouts.CountStuck(runs, time.Now()),
+		TimedOut: len(timedOut),
+	})
+}
+
+// defaultLatencyDays is the default number of days of pending runs which
+// apiPendingTestRunLatencyHandler computes the latency of stages from.
+const defaultLatencyDays = 7
+
+// maxLatencyDays bounds the ?days=N param of apiPendingTestRunLatencyHandler,
+// which loads all of the pending runs updated in that many days.
+const maxLatencyDays = 30
+
+// apiPendingTestRunLatencyHandler is responsible for emitting JSON for the
+// latency of each stage, computed from the transitions of the PendingTestRun
+// entities updated in the last ?days=N days.
+func apiPendingTestRunLatencyHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	days := defaultLatencyDays
+	if param := r.URL.Query().Get("days"); param != "" {
+		var err error
+		if days, err = strconv.Atoi(param); err != nil || days < 1 || days > maxLatencyDays {
+			http.Error(w, "Invalid days param: "+param, http.StatusBadRequest)
+
+			return
+		}
+	}
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	var runs []shared.PendingTestRun
+	if _, err := store.GetAll(store.NewQuery("PendingTestRun").Filter("Updated > ", since), &runs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	emit(ctx, w, shared.StageLatencies(runs))
+}
+
 // emit to the given writer the JSON marshalled output of the given interface.
 func emit(ctx context.Context, w http.ResponseWriter, i interface{}) {
 	data, err := json.Marshal(i)
@@ -135,3 +135,80 @@ func TestAPIPendingTestHandler_invalidFilter(t *testing.T) {
 	apiPendingTestRunsHandler(resp, r)
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 }
+
+func TestAPIPendingTestHandler_stuck(t *testing.T) {
+	i, err := sharedtest.NewAEInstance(true)
+	assert.Nil(t, err)
+	defer i.Close()
+	r, err := i.NewRequest("GET", "/api/status?stuck=true", nil)
+	assert.Nil(t, err)
+	ctx := r.Context()
+
+	now := time.Now().In(time.UTC)
+	for _, run := range []shared.PendingTestRun{
+		{Updated: now.Add(-48 * time.Hour), Stage: shared.StageCIRunning},
+		{Updated: now.Add(-time.Hour), Stage: shared.StageCIRunning},
+		{Updated: now.Add(-4 * time.Hour), Stage: shared.StageWptFyiProcessing},
+		{Updated: now.Add(-48 * time.Hour), Stage: shared.StageValid},
+		{Updated: now, Stage: shared.StageTimedOut},
+	} {
+		assert.Nil(t, createPendingRun(ctx, &run))
+	}
+
+	resp := httptest.NewRecorder()
+	apiPendingTestRunsHandler(resp, r)
+	body, _ := io.ReadAll(resp.Result().Body)
+	assert.Equal(t, http.StatusOK, resp.Code, string(body))
+	var counts stuckRunCounts
+	assert.Nil(t, json.Unmarshal(body, &counts))
+	assert.Equal(t, map[string]int{"CI_RUNNING": 1, "WPTFYI_PROCESSING": 1}, counts.Stuck)
+	assert.Equal(t, 1, counts.TimedOut)
+}
+
+func TestAPIPendingTestRunLatencyHandler(t *testing.T) {
+	i, err := sharedtest.NewAEInstance(true)
+	assert.Nil(t, err)
+	defer i.Close()
+	r, err := i.NewRequest("GET", "/api/status/latency?days=1", nil)
+	assert.Nil(t, err)
+	ctx := r.Context()
+
+	now := time.Now().In(time.UTC)
+	transitions := func(start time.Time) []shared.PendingTestRunTransition {
+		return []shared.PendingTestRunTransition{
+			{Stage: shared.StageCIRunning, Time: start},
+			{Stage: shared.StageWptFyiReceived, Time: start.Add(time.Hour)},
+			{Stage: shared.StageValid, Time: start.Add(time.Hour + time.Minute)},
+		}
+	}
+	for _, run := range []shared.PendingTestRun{
+		{Updated: now, Stage: shared.StageValid, Transitions: transitions(now.Add(-2 * time.Hour))},
+		// Too old.
+		{Updated: now.Add(-48 * time.Hour), Stage: shared.StageValid, Transitions: transitions(now.Add(-50 * time.Hour))},
+	} {
+		assert.Nil(t, createPendingRun(ctx, &run))
+	}
+
+	resp := httptest.NewRecorder()
+	apiPendingTestRunLatencyHandler(resp, r)
+	body, _ := io.ReadAll(resp.Result().Body)
+	assert.Equal(t, http.StatusOK, resp.Code, string(body))
+	var latencies []shared.StageLatency
+	assert.Nil(t, json.Unmarshal(body, &latencies))
+	assert.Equal(t, []shared.StageLatency{
+		{Stage: shared.StageCIRunning, Count: 1, MeanSeconds: 3600, P50Seconds: 3600, P95Seconds: 3600, MaxSeconds: 3600},
+		{Stage: shared.StageWptFyiReceived, Count: 1, MeanSeconds: 60, P50Seconds: 60, P95Seconds: 60, MaxSeconds: 60},
+	}, latencies)
+
+	r, err = i.NewRequest("GET", "/api/status/latency?days=0", nil)
+	assert.Nil(t, err)
+	resp = httptest.NewRecorder()
+	apiPendingTestRunLatencyHandler(resp, r)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	r, err = i.NewRequest("GET", "/api/status/latency?days=31", nil)
+	assert.Nil(t, err)
+	resp = httptest.NewRecorder()
+	apiPendingTestRunLatencyHandler(resp, r)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,283 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/google/go-github/v90/github"
+	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/api/checks"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+var errPRNotFound = errors.New("PR not found")
+
+// prResults is the response of prHandler.
+type prResults struct {
+	Number  int    `json:"number"`
+	HeadSHA string `json:"head_sha"`
+	BaseSHA string `json:"base_sha"`
+
+	Products []prProductResults `json:"products"`
+	// UnmatchedPending are the pending runs at the head and base SHAs which
+	// aren't of any of the products, e.g. those whose browser isn't known
+	// until their results are processed.
+	UnmatchedPending []shared.PendingTestRun `json:"unmatched_pending"`
+
+	// CheckRuns are the wpt.fyi check runs for the head SHA, or CheckRunsError
+	// why they couldn't be loaded.
+	CheckRuns      []prCheckRun `json:"check_runs"`
+	CheckRunsError string       `json:"check_runs_error,omitempty"`
+}
+
+// prProductResults are the results of a PR for a single product.
+type prProductResults struct {
+	Product string `json:"product"`
+	// Runs are all the runs of the product at the head and base SHAs.
+	Runs shared.TestRuns `json:"runs"`
+	// Pending are the pending runs of the product at the head and base SHAs.
+	Pending []shared.PendingTestRun `json:"pending"`
+	// HeadRun and BaseRun are the runs compared in Diff, or DiffError why
+	// they couldn't be compared.
+	HeadRun   *shared.TestRun `json:"head_run,omitempty"`
+	BaseRun   *shared.TestRun `json:"base_run,omitempty"`
+	Diff      *shared.RunDiff `json:"diff,omitempty"`
+	DiffError string          `json:"diff_error,omitempty"`
+}
+
+// prCheckRun is the state of a GitHub check run.
+type prCheckRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion,omitempty"`
+	Title      string `json:"title,omitempty"`
+	DetailsURL string `json:"details_url,omitempty"`
+	HTMLURL    string `json:"html_url,omitempty"`
+}
+
+// apiPRHandler is responsible for emitting JSON summarizing the results of a
+// PR: the runs at its head and base SHAs and the pending runs for them, per
+// product, the diff of the pr_base and pr_head runs of each product, and the
+// state of the wpt.fyi check runs.
+//
+// URL Params:
+//
+//	product: Products to include (default: the default products)
+//	filter: Diff filter, as for /api/diff (default: ADC)
+//	path: Test paths to diff (default: all)
+func apiPRHandler(w http.ResponseWriter, r *http.Request) {
+	// Serve cached with 1 minute expiry, since each request makes several
+	// GitHub API calls. Delegate to prHandler on cache miss.
+	ctx := r.Context()
+	shared.NewCachingHandler(
+		ctx,
+		http.HandlerFunc(prHandler),
+		shared.NewGZReadWritable(shared.NewRedisReadWritable(ctx, time.Minute)),
+		shared.AlwaysCachable,
+		shared.URLAsCacheKey,
+		shared.CacheStatusOK,
+	).ServeHTTP(w, r)
+}
+
+func prHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := shared.GetLogger(ctx)
+	number, err := strconv.Atoi(mux.Vars(r)["number"])
+	if err != nil || number < 1 {
+		http.Error(w, "Invalid PR number: "+mux.Vars(r)["number"], http.StatusBadRequest)
+
+		return
+	}
+	q := r.URL.Query()
+	filter, err := shared.ParseTestRunFilterParams(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	diffFilter, paths, err := shared.ParseDiffFilterParams(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	store := shared.NewAppEngineDatastore(ctx, true)
+	results, err := loadPRResults(
+		checks.NewAPI(ctx),
+		shared.NewDiffAPI(ctx),
+		store,
+		number,
+		filter.GetProductsOrDefault(),
+		diffFilter,
+		paths,
+	)
+	if errors.Is(err, errPRNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+
+		return
+	} else if err != nil {
+		log.Errorf("Failed to load results of PR #%d: %s", number, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	emit(ctx, w, results)
+}
+
+func loadPRResults(
+	checksAPI checks.API,
+	diffAPI shared.DiffAPI,
+	store shared.Datastore,
+	number int,
+	products shared.ProductSpecs,
+	diffFilter shared.DiffFilterParam,
+	paths mapset.Set,
+) (*prResults, error) {
+	log := shared.GetLogger(checksAPI.Context())
+	pr, err := getPullRequest(checksAPI, number)
+	if err != nil {
+		return nil, err
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	results := prResults{
+		Number:           number,
+		HeadSHA:          pr.GetHead().GetSHA(),
+		BaseSHA:          pr.GetBase().GetSHA(),
+		Products:         []prProductResults{},
+		UnmatchedPending: []shared.PendingTestRun{},
+		CheckRuns:        []prCheckRun{},
+	}
+	shas := shared.SHAs{results.HeadSHA, results.BaseSHA}
+
+	runsByProduct, err := store.TestRunQuery().LoadTestRuns(products, nil, shas, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pending []shared.PendingTestRun
+	for _, sha := range shas {
+		var runs []shared.PendingTestRun
+		keys, err := store.GetAll(store.NewQuery("PendingTestRun").Filter("FullRevisionHash =", sha), &runs)
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range keys {
+			runs[i].ID = key.IntID()
+		}
+		pending = append(pending, runs...)
+	}
+
+	matched := make([]bool, len(pending))
+	for _, productRuns := range runsByProduct {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		product := prProductResults{
+			Product: productRuns.Product.String(),
+			Runs:    productRuns.TestRuns,
+			Pending: []shared.PendingTestRun{},
+		}
+		if product.Runs == nil {
+			product.Runs = shared.TestRuns{}
+		}
+		for i, run := range pending {
+			if productRuns.Product.BrowserName == run.BrowserName {
+				product.Pending = append(product.Pending, run)
+				matched[i] = true
+			}
+		}
+		if len(product.Runs) == 0 && len(product.Pending) == 0 {
+			continue
+		}
+
+		product.HeadRun, product.BaseRun = prRunsToCompare(product.Runs, results.HeadSHA, results.BaseSHA)
+		if product.HeadRun != nil && product.BaseRun != nil {
+			diff, err := diffAPI.GetRunsDiff(*product.BaseRun, *product.HeadRun, diffFilter, paths)
+			if err != nil {
+				log.Warningf("Failed to diff runs %d and %d: %s", product.BaseRun.ID, product.HeadRun.ID, err.Error())
+				product.DiffError = err.Error()
+			} else {
+				product.Diff = &diff
+			}
+		}
+		results.Products = append(results.Products, product)
+	}
+	for i, run := range pending {
+		if !matched[i] {
+			results.UnmatchedPending = append(results.UnmatchedPending, run)
+		}
+	}
+
+	checkRuns, err := checksAPI.GetCheckRunsForSHA(results.HeadSHA)
+	if err != nil {
+		log.Warningf("Failed to load check runs for %s: %s", shared.CropString(results.HeadSHA, 7), err.Error())
+		results.CheckRunsError = err.Error()
+	}
+	for _, run := range checkRuns {
+		results.CheckRuns = append(results.CheckRuns, prCheckRun{
+			ID:         run.GetID(),
+			Name:       run.GetName(),
+			Status:     run.GetStatus(),
+			Conclusion: run.GetConclusion(),
+			Title:      run.GetOutput().GetTitle(),
+			DetailsURL: run.GetDetailsURL(),
+			HTMLURL:    run.GetHTMLURL(),
+		})
+	}
+
+	return &results, nil
+}
+
+func getPullRequest(aeAPI shared.AppEngineAPI, number int) (*github.PullRequest, error) {
+	githubClient, err := aeAPI.GetGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+	pr, _, err := githubClient.PullRequests.Get(aeAPI.Context(), shared.WPTRepoOwner, shared.WPTRepoName, number)
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: #%d", errPRNotFound, number)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+// prRunsToCompare returns the latest pr_head run at the head SHA, and the
+// latest pr_base run of the same channel at the head SHA, falling back to the
+// latest master run of that channel at the base SHA.
+func prRunsToCompare(runs shared.TestRuns, headSHA, baseSHA string) (headRun, baseRun *shared.TestRun) {
+	latest := func(sha, label, channel string) *shared.TestRun {
+		var found *shared.TestRun
+		for i := range runs {
+			run := &runs[i]
+			if run.FullRevisionHash != sha || !run.LabelsSet().Contains(label) {
+				continue
+			} else if channel != "" && run.Channel() != channel {
+				continue
+			}
+			if found == nil || run.TimeStart.After(found.TimeStart) {
+				found = run
+			}
+		}
+
+		return found
+	}
+	headRun = latest(headSHA, shared.PRHeadLabel, "")
+	if headRun == nil {
+		return nil, nil
+	}
+	if baseRun = latest(headSHA, shared.PRBaseLabel, headRun.Channel()); baseRun == nil {
+		baseRun = latest(baseSHA, shared.MasterLabel, headRun.Channel())
+	}
+
+	return headRun, baseRun
+}
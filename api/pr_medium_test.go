@@ -0,0 +1,128 @@
+//go:build medium
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/checks/mock_checks"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestLoadPRResults(t *testing.T) {
+	head := "1111111111111111111111111111111111111111"
+	base := "2222222222222222222222222222222222222222"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number": 123, "head": {"sha": "` + head + `"}, "base": {"sha": "` + base + `"}}`))
+	}))
+	defer server.Close()
+	baseURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&baseURL, nil))
+	assert.Nil(t, err)
+
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	now := time.Now().Truncate(time.Second)
+	var runs shared.TestRuns
+	for _, run := range []struct {
+		sha   string
+		label string
+	}{
+		{head, shared.PRHeadLabel},
+		{head, shared.PRBaseLabel},
+		{base, shared.MasterLabel},
+	} {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		run := shared.TestRun{
+			// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+			ProductAtRevision: shared.ProductAtRevision{
+				Product:          shared.Product{BrowserName: "chrome"},
+				Revision:         run.sha[:10],
+				FullRevisionHash: run.sha,
+			},
+			TimeStart: now,
+			Labels:    []string{run.label, shared.ExperimentalLabel},
+		}
+		key, err := store.Put(store.NewIncompleteKey("TestRun"), &run)
+		assert.Nil(t, err)
+		run.ID = key.IntID()
+		runs = append(runs, run)
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	pending := shared.PendingTestRun{
+		ProductAtRevision: runs[0].ProductAtRevision,
+		Stage:             shared.StageWptFyiProcessing,
+	}
+	pending.BrowserName = "firefox"
+	_, err = store.Put(store.NewIncompleteKey("PendingTestRun"), &pending)
+	assert.Nil(t, err)
+	// The browser of a run isn't always known before it's processed.
+	unknown := pending
+	unknown.BrowserName = ""
+	unknownKey, err := store.Put(store.NewIncompleteKey("PendingTestRun"), &unknown)
+	assert.Nil(t, err)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	checksAPI := mock_checks.NewMockAPI(mockCtrl)
+	checksAPI.EXPECT().Context().AnyTimes().Return(ctx)
+	checksAPI.EXPECT().GetGitHubClient().Return(client, nil)
+	name, status, conclusion := "chrome[experimental]", "completed", "failure"
+	checksAPI.EXPECT().GetCheckRunsForSHA(head).Return([]*github.CheckRun{
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		{ID: github.Ptr(int64(1)), Name: &name, Status: &status, Conclusion: &conclusion},
+	}, nil)
+	diffAPI := sharedtest.NewMockDiffAPI(mockCtrl)
+	diffFilter := shared.DiffFilterParam{Added: true, Changed: true, Deleted: true}
+	diffAPI.EXPECT().GetRunsDiff(gomock.Any(), gomock.Any(), diffFilter, nil).DoAndReturn(
+		func(before, after shared.TestRun, _ shared.DiffFilterParam, _ interface{}) (shared.RunDiff, error) {
+			assert.Equal(t, runs[1].ID, before.ID)
+			assert.Equal(t, runs[0].ID, after.ID)
+
+			// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+			return shared.RunDiff{Differences: shared.ResultsDiff{"/a.html": {0, 1, 0}}}, nil
+		})
+
+	chrome, _ := shared.ParseProductSpec("chrome")
+	firefox, _ := shared.ParseProductSpec("firefox")
+	safari, _ := shared.ParseProductSpec("safari")
+	results, err := loadPRResults(
+		checksAPI, diffAPI, store, 123, shared.ProductSpecs{chrome, firefox, safari}, diffFilter, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, head, results.HeadSHA)
+	assert.Equal(t, base, results.BaseSHA)
+	// Safari has neither runs nor pending runs.
+	assert.Len(t, results.Products, 2)
+
+	assert.Equal(t, "chrome", results.Products[0].Product)
+	assert.Len(t, results.Products[0].Runs, 3)
+	assert.Empty(t, results.Products[0].Pending)
+	assert.Equal(t, runs[0].ID, results.Products[0].HeadRun.ID)
+	assert.Equal(t, runs[1].ID, results.Products[0].BaseRun.ID)
+	assert.Equal(t, shared.ResultsDiff{"/a.html": {0, 1, 0}}, results.Products[0].Diff.Differences)
+
+	assert.Equal(t, "firefox", results.Products[1].Product)
+	assert.Empty(t, results.Products[1].Runs)
+	assert.Len(t, results.Products[1].Pending, 1)
+	assert.Nil(t, results.Products[1].Diff)
+
+	assert.Len(t, results.UnmatchedPending, 1)
+	assert.Equal(t, unknownKey.IntID(), results.UnmatchedPending[0].ID)
+
+	assert.Equal(t, []prCheckRun{{ID: 1, Name: name, Status: status, Conclusion: conclusion}}, results.CheckRuns)
+}
@@ -0,0 +1,91 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newPRRun(id int64, sha string, start time.Time, labels ...string) shared.TestRun {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.TestRun{
+		ID: id,
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		ProductAtRevision: shared.ProductAtRevision{
+			Product:          shared.Product{BrowserName: "chrome"},
+			FullRevisionHash: sha,
+		},
+		TimeStart: start,
+		Labels:    labels,
+	}
+}
+
+func TestPRRunsToCompare(t *testing.T) {
+	head := "1111111111111111111111111111111111111111"
+	base := "2222222222222222222222222222222222222222"
+	now := time.Now()
+	runs := shared.TestRuns{
+		newPRRun(1, head, now.Add(-time.Hour), shared.PRHeadLabel, shared.ExperimentalLabel),
+		newPRRun(2, head, now, shared.PRHeadLabel, shared.ExperimentalLabel),
+		newPRRun(3, head, now, shared.PRBaseLabel, shared.StableLabel),
+		newPRRun(4, base, now, shared.MasterLabel, shared.ExperimentalLabel),
+	}
+
+	headRun, baseRun := prRunsToCompare(runs, head, base)
+	assert.Equal(t, int64(2), headRun.ID)
+	// The pr_base run is of another channel, so the master run is compared.
+	assert.Equal(t, int64(4), baseRun.ID)
+
+	runs = append(runs, newPRRun(5, head, now.Add(-time.Hour), shared.PRBaseLabel, shared.ExperimentalLabel))
+	headRun, baseRun = prRunsToCompare(runs, head, base)
+	assert.Equal(t, int64(2), headRun.ID)
+	assert.Equal(t, int64(5), baseRun.ID)
+
+	headRun, baseRun = prRunsToCompare(runs[2:], head, base)
+	assert.Nil(t, headRun)
+	assert.Nil(t, baseRun)
+}
+
+func TestGetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/web-platform-tests/wpt/pulls/123":
+			_, _ = w.Write([]byte(`{"number": 123, "head": {"sha": "abc"}, "base": {"sha": "def"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	baseURL := server.URL + "/"
+	client, err := github.NewClient(github.WithURLs(&baseURL, nil))
+	assert.Nil(t, err)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	aeAPI.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	aeAPI.EXPECT().GetGitHubClient().AnyTimes().Return(client, nil)
+
+	pr, err := getPullRequest(aeAPI, 123)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", pr.GetHead().GetSHA())
+	assert.Equal(t, "def", pr.GetBase().GetSHA())
+
+	_, err = getPullRequest(aeAPI, 456)
+	assert.True(t, errors.Is(err, errPRNotFound))
+}
@@ -8,49 +8,114 @@ package receiver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/web-platform-tests/wpt.fyi/api/webhooks"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
 // gcsPattern is the pattern for gs:// URI.
 var gcsPattern = regexp.MustCompile(`^gs://([^/]+)/(.+)$`)
 
-// AuthenticateUploader checks the HTTP basic auth against SecretManager, and returns the username if
-// it's valid or "" otherwise.
+// AuthenticateUploader authenticates the uploader of the request for the given
+// scope, and returns the username if it's valid or "" otherwise.
+//
+// Uploaders authenticate either with HTTP basic auth, which is checked against
+// SecretManager and valid for all scopes, or with an APIToken as a bearer
+// token, which is only valid for its own scopes. Use AuthenticateUploaderToken
+// to also check the product restrictions of the token.
 //
 // This function is not defined on API interface for easier reuse in other packages.
-func AuthenticateUploader(aeAPI shared.AppEngineAPI, r *http.Request) string {
+func AuthenticateUploader(aeAPI shared.AppEngineAPI, r *http.Request, scope shared.APITokenScope) string {
+	uploader, _ := AuthenticateUploaderToken(aeAPI, r, scope)
+
+	return uploader
+}
+
+// AuthenticateUploaderToken is AuthenticateUploader, but also returns the
+// APIToken the uploader was authenticated with, or nil for basic auth.
+func AuthenticateUploaderToken(
+	aeAPI shared.AppEngineAPI, r *http.Request, scope shared.APITokenScope) (string, *shared.APIToken) {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		token, err := aeAPI.GetAPIToken(strings.TrimSpace(bearer))
+		if err != nil {
+			if !errors.Is(err, shared.ErrInvalidAPIToken) {
+				shared.GetLogger(aeAPI.Context()).Errorf("Failed to load API token: %s", err.Error())
+			}
+
+			return "", nil
+		}
+		if !token.HasScope(scope) {
+			shared.GetLogger(aeAPI.Context()).Warningf("API token %d of %s lacks scope %s", token.ID, token.Uploader, scope)
+
+			return "", nil
+		}
+
+		return token.Uploader, token
+	}
+
 	username, password, ok := r.BasicAuth()
 	if !ok {
-		return ""
+		return "", nil
 	}
 	user, err := aeAPI.GetUploader(username)
 	if err != nil || user.Password != password {
-		return ""
+		return "", nil
 	}
 
-	return user.Username
+	return user.Username, nil
 }
 
 // API abstracts all AppEngine/GCP APIs used by the results receiver.
 type API interface {
 	shared.AppEngineAPI
 
-	AddTestRun(testRun *shared.TestRun) (shared.Key, error)
+	AddTestRun(testRun *shared.TestRun, policy shared.DuplicateRunPolicy) (shared.Key, error)
+	ComposeGCS(gcsPath string, sources []string, gzipped bool) error
+	CreateAPIToken(token *shared.APIToken) error
+	CreateUploadSession(session *shared.ResultsUploadSession) error
+	DeleteGCS(gcsPath string) error
+	DeliverCallback(run shared.PendingTestRun) error
+	GetCallbackSecret(uploader string) (string, error)
+	GetWebhookSecret(uploader string) (string, error)
+	GetCINotification(id int64) (*shared.CINotification, error)
+	GetPendingRunTimeouts() (shared.PendingRunTimeouts, error)
+	GetPendingTestRun(id int64) (*shared.PendingTestRun, error)
+	GetQuotaReport() ([]shared.UploaderQuotaReport, error)
+	GetUploadSession(id string) (*shared.ResultsUploadSession, error)
+	GetUser(r *http.Request) *shared.User
 	IsAdmin(*http.Request) bool
+	ListAPITokens(uploader string) ([]shared.APIToken, error)
+	ListCINotifications(outcome shared.CINotificationOutcome, limit int) ([]shared.CINotification, error)
+	ListCINotificationsOfBuild(uploader, buildID string) ([]shared.CINotification, error)
+	ListPendingTestRuns() ([]shared.PendingTestRun, error)
+	OpenGCS(gcsPath string) (io.ReadCloser, error)
+	PutCINotification(notification *shared.CINotification) error
+	RecordCallbackDelivery(id int64, delivery shared.CallbackDelivery) error
+	ReleaseRunQuota(runID int64) error
+	ReschedulePendingTestRun(run shared.PendingTestRun) error
+	ReserveRunQuota(runID int64) error
+	ReserveUploadQuota(uploader string, bytes int64) error
+	RevokeAPIToken(id int64) (*shared.APIToken, error)
 	ScheduleResultsTask(
 		uploader string,
 		results []string,
 		screenshots []string,
 		archives []string,
 		extraParams map[string]string) (string, error)
-	UpdatePendingTestRun(pendingRun shared.PendingTestRun) error
+	TimeOutPendingTestRun(run shared.PendingTestRun, message string) error
+	UpdatePendingTestRun(actor string, pendingRun shared.PendingTestRun) error
+	UpdateUploadSession(
+		id string,
+		mutator func(*shared.ResultsUploadSession) error) (*shared.ResultsUploadSession, error)
 	UploadToGCS(gcsPath string, f io.Reader, gzipped bool) error
 }
 
@@ -80,22 +145,67 @@ func NewAPI(ctx context.Context) API {
 	}
 }
 
+// AddTestRun adds the run to Datastore, applying the given policy if it
+// duplicates an existing run (see findDuplicateRun), and recording the
+// decision on the pending run. A DuplicateRunError is returned if the run is
+// rejected. Duplicates aren't looked for with shared.DuplicateRunAllow.
+//
 // nolint:ireturn // TODO: Fix ireturn lint error
-func (a apiImpl) AddTestRun(testRun *shared.TestRun) (shared.Key, error) {
+func (a apiImpl) AddTestRun(testRun *shared.TestRun, policy shared.DuplicateRunPolicy) (shared.Key, error) {
 	key := a.store.NewIDKey("TestRun", testRun.ID)
+	var existing shared.Key
 	var err error
-	if testRun.ID != 0 {
+	if policy != shared.DuplicateRunAllow {
+		if existing, err = a.findDuplicateRun(testRun); err != nil {
+			return nil, err
+		}
+	}
+	replace := false
+	if existing != nil {
+		a.recordDuplicateRun(testRun.ID, existing.IntID(), policy)
+		switch policy {
+		case shared.DuplicateRunReject:
+			return nil, &shared.DuplicateRunError{ExistingID: existing.IntID()}
+		case shared.DuplicateRunReplace:
+			key = existing
+			replace = true
+		case shared.DuplicateRunKeep:
+			testRun.Labels = append(testRun.Labels, shared.DuplicateLabel)
+		}
+	}
+
+	switch {
+	case replace:
+		_, err = a.store.Put(key, testRun)
+	case testRun.ID != 0:
 		err = a.store.Insert(key, testRun)
-	} else {
+	default:
 		key, err = a.store.Put(key, testRun)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if replace {
+		if err := shared.DeleteCachedTestRun(key.IntID()); err != nil {
+			shared.GetLogger(a.Context()).Warningf("Failed to delete cached run %d: %s", key.IntID(), err.Error())
+		}
+	}
+
+	created := *testRun
+	created.ID = key.IntID()
+	a.dispatchWebhooks(webhooks.EventTestRunCreated, created)
 
 	return key, nil
 }
 
+// dispatchWebhooks notifies outgoing webhooks of an event. Failures are
+// logged, but otherwise ignored.
+func (a apiImpl) dispatchWebhooks(event webhooks.Event, payload interface{}) {
+	if err := webhooks.Dispatch(a.AppEngineAPI, a.store, event, payload); err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to dispatch %s webhooks: %s", event, err.Error())
+	}
+}
+
 func (a apiImpl) IsAdmin(r *http.Request) bool {
 	logger := shared.GetLogger(a.Context())
 	acl, err := a.githubACLFactory(r)
@@ -117,13 +227,21 @@ func (a apiImpl) IsAdmin(r *http.Request) bool {
 	return admin
 }
 
-func (a apiImpl) UpdatePendingTestRun(newRun shared.PendingTestRun) error {
+// UpdatePendingTestRun updates the pending run with the non-empty fields of
+// newRun, recording a transition made by the given actor if its stage changed.
+func (a apiImpl) UpdatePendingTestRun(actor string, newRun shared.PendingTestRun) error {
 	var buffer shared.PendingTestRun
 	key := a.store.NewIDKey("PendingTestRun", newRun.ID)
 
-	return a.store.Update(key, &buffer, func(obj interface{}) error {
+	stageChanged := false
+	err := a.store.Update(key, &buffer, func(obj interface{}) error {
 		run := obj.(*shared.PendingTestRun)
 		if newRun.Stage != 0 {
+			stageChanged = run.Stage != newRun.Stage
+			if stageChanged && run.Stage == shared.StageTimedOut {
+				// The timeout no longer applies to a run which moved on.
+				run.Error = ""
+			}
 			if err := run.Transition(newRun.Stage); err != nil {
 				return err
 			}
@@ -137,6 +255,18 @@ func (a apiImpl) UpdatePendingTestRun(newRun shared.PendingTestRun) error {
 		if newRun.Uploader != "" {
 			run.Uploader = newRun.Uploader
 		}
+		if newRun.DuplicatePolicy != "" {
+			run.DuplicatePolicy = newRun.DuplicatePolicy
+		}
+		if newRun.DuplicateOf != 0 {
+			run.DuplicateOf = newRun.DuplicateOf
+		}
+		if newRun.NotifyURL != "" {
+			run.NotifyURL = newRun.NotifyURL
+		}
+		if newRun.ResultsTask != "" {
+			run.ResultsTask = newRun.ResultsTask
+		}
 		// ProductAtRevision
 		if newRun.BrowserName != "" {
 			run.BrowserName = newRun.BrowserName
@@ -160,27 +290,44 @@ func (a apiImpl) UpdatePendingTestRun(newRun shared.PendingTestRun) error {
 			run.FullRevisionHash = newRun.FullRevisionHash
 		}
 
+		now := time.Now()
 		if run.Created.IsZero() {
-			run.Created = time.Now()
+			run.Created = now
+		}
+		run.Updated = now
+		if stageChanged {
+			run.RecordTransition(actor, newRun.Error, now)
 		}
-		run.Updated = time.Now()
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if stageChanged {
+		buffer.ID = newRun.ID
+		a.pendingRunStageChanged(buffer)
+	}
+
+	return nil
 }
 
-func (a *apiImpl) UploadToGCS(gcsPath string, f io.Reader, gzipped bool) error {
-	matches := gcsPattern.FindStringSubmatch(gcsPath)
-	if len(matches) != 3 {
-		return fmt.Errorf("invalid GCS path: %s", gcsPath)
+// pendingRunStageChanged notifies webhooks, and the run's notify_url if it has
+// finished, that the stage of the run has changed.
+func (a apiImpl) pendingRunStageChanged(run shared.PendingTestRun) {
+	a.dispatchWebhooks(webhooks.EventPendingRunStage, run)
+	if run.Stage.IsTerminal() && run.NotifyURL != "" {
+		a.scheduleCallback(run.ID)
 	}
-	bucketName := matches[1]
-	fileName := matches[2]
+}
 
-	if a.gcs == nil {
-		// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
-		a.gcs = &gcsImpl{ctx: a.Context()}
+func (a *apiImpl) UploadToGCS(gcsPath string, f io.Reader, gzipped bool) error {
+	bucketName, fileName, err := parseGCSPath(gcsPath)
+	if err != nil {
+		return err
 	}
+	a.initGCS()
 
 	encoding := ""
 	if gzipped {
@@ -201,23 +348,120 @@ func (a *apiImpl) UploadToGCS(gcsPath string, f io.Reader, gzipped bool) error {
 	return err
 }
 
-func (a apiImpl) ScheduleResultsTask(
-	uploader string, results, screenshots []string, archives []string, extraParams map[string]string) (string, error) {
-	key, err := a.store.ReserveID("TestRun")
+// ComposeGCS concatenates the source objects, which must be in the same bucket
+// as gcsPath, into gcsPath.
+func (a *apiImpl) ComposeGCS(gcsPath string, sources []string, gzipped bool) error {
+	bucketName, fileName, err := parseGCSPath(gcsPath)
 	if err != nil {
-		return "", err
+		return err
+	}
+	sourceNames := make([]string, len(sources))
+	for i, source := range sources {
+		sourceBucket, sourceName, err := parseGCSPath(source)
+		if err != nil {
+			return err
+		}
+		if sourceBucket != bucketName {
+			return fmt.Errorf("cannot compose %s into a different bucket", source)
+		}
+		sourceNames[i] = sourceName
 	}
+	a.initGCS()
 
-	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
-	pendingRun := shared.PendingTestRun{
-		ID:       key.IntID(),
-		Stage:    shared.StageWptFyiReceived,
-		Uploader: uploader,
-		ProductAtRevision: shared.ProductAtRevision{
-			FullRevisionHash: extraParams["revision"],
-		},
+	encoding := ""
+	if gzipped {
+		encoding = "gzip"
+	}
+
+	return a.gcs.Compose(bucketName, fileName, sourceNames, encoding)
+}
+
+// OpenGCS opens the object at gcsPath for reading.
+func (a *apiImpl) OpenGCS(gcsPath string) (io.ReadCloser, error) {
+	bucketName, fileName, err := parseGCSPath(gcsPath)
+	if err != nil {
+		return nil, err
+	}
+	a.initGCS()
+
+	return a.gcs.NewReader(bucketName, fileName)
+}
+
+// DeleteGCS deletes the object at gcsPath.
+func (a *apiImpl) DeleteGCS(gcsPath string) error {
+	bucketName, fileName, err := parseGCSPath(gcsPath)
+	if err != nil {
+		return err
+	}
+	a.initGCS()
+
+	return a.gcs.Delete(bucketName, fileName)
+}
+
+func (a *apiImpl) initGCS() {
+	if a.gcs == nil {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
+		a.gcs = &gcsImpl{ctx: a.Context()}
+	}
+}
+
+func parseGCSPath(gcsPath string) (bucketName, fileName string, err error) {
+	matches := gcsPattern.FindStringSubmatch(gcsPath)
+	if len(matches) != 3 {
+		return "", "", fmt.Errorf("invalid GCS path: %s", gcsPath)
+	}
+
+	return matches[1], matches[2], nil
+}
+
+func (a apiImpl) CreateUploadSession(session *shared.ResultsUploadSession) error {
+	session.ID = uuid.New().String()
+	session.Created = time.Now()
+	session.Updated = session.Created
+	_, err := a.store.Put(a.store.NewNameKey(uploadSessionKind, session.ID), session)
+
+	return err
+}
+
+func (a apiImpl) GetUploadSession(id string) (*shared.ResultsUploadSession, error) {
+	var session shared.ResultsUploadSession
+	if err := a.store.Get(a.store.NewNameKey(uploadSessionKind, id), &session); err != nil {
+		return nil, err
+	}
+	session.ID = id
+
+	return &session, nil
+}
+
+func (a apiImpl) UpdateUploadSession(
+	id string,
+	mutator func(*shared.ResultsUploadSession) error) (*shared.ResultsUploadSession, error) {
+	var session shared.ResultsUploadSession
+	err := a.store.Update(a.store.NewNameKey(uploadSessionKind, id), &session, func(obj interface{}) error {
+		s := obj.(*shared.ResultsUploadSession)
+		if s.Uploader == "" {
+			// Update loads into an empty entity if it doesn't exist.
+			return shared.ErrNoSuchEntity
+		}
+		if err := mutator(s); err != nil {
+			return err
+		}
+		s.Updated = time.Now()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if err := a.UpdatePendingTestRun(pendingRun); err != nil {
+	session.ID = id
+
+	return &session, nil
+}
+
+func (a apiImpl) ScheduleResultsTask(
+	uploader string, results, screenshots []string, archives []string, extraParams map[string]string) (string, error) {
+	key, err := a.store.ReserveID("TestRun")
+	if err != nil {
 		return "", err
 	}
 
@@ -230,10 +474,27 @@ func (a apiImpl) ScheduleResultsTask(
 	payload.Set("uploader", uploader)
 
 	for k, v := range extraParams {
-		if v != "" {
+		// notify_url is handled by wpt.fyi, not the processor.
+		if v != "" && k != "notify_url" {
 			payload.Set(k, v)
 		}
 	}
 
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	pendingRun := shared.PendingTestRun{
+		ID:       key.IntID(),
+		Stage:    shared.StageWptFyiReceived,
+		Uploader: uploader,
+		ProductAtRevision: shared.ProductAtRevision{
+			FullRevisionHash: extraParams["revision"],
+		},
+		NotifyURL:       extraParams["notify_url"],
+		DuplicatePolicy: shared.DuplicateRunPolicy(extraParams["duplicate_policy"]),
+		ResultsTask:     payload.Encode(),
+	}
+	if err := a.UpdatePendingTestRun(uploader, pendingRun); err != nil {
+		return "", err
+	}
+
 	return a.ScheduleTask(ResultsQueue, fmt.Sprint(key.IntID()), ResultsTarget, payload)
 }
@@ -27,17 +27,17 @@ func TestAuthenticateUploader(t *testing.T) {
 	a := NewAPI(ctx)
 
 	req := httptest.NewRequest("", "/api/foo", &bytes.Buffer{})
-	assert.Equal(t, "", AuthenticateUploader(a, req))
+	assert.Equal(t, "", AuthenticateUploader(a, req, shared.ScopeUploadResults))
 
 	// Case 1: Try to get an uploader that does not exist
 	req.SetBasicAuth("bad-test-secret", "bad-value")
-	assert.Equal(t, "", AuthenticateUploader(a, req))
+	assert.Equal(t, "", AuthenticateUploader(a, req, shared.ScopeUploadResults))
 
 	// Case 2: Try with correct username and password
 	req.SetBasicAuth("test-secret", "test-secret-value")
-	assert.Equal(t, "test-secret", AuthenticateUploader(a, req))
+	assert.Equal(t, "test-secret", AuthenticateUploader(a, req, shared.ScopeUploadResults))
 
 	// Case 3: Try with correct username but bad password
 	req.SetBasicAuth("test-secret", "456")
-	assert.Equal(t, "", AuthenticateUploader(a, req))
+	assert.Equal(t, "", AuthenticateUploader(a, req, shared.ScopeUploadResults))
 }
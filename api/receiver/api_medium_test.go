@@ -9,6 +9,9 @@ package receiver
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -29,6 +33,12 @@ import (
 type mockGcs struct {
 	mockWriter mockGcsWriter
 	errOnNew   error
+
+	composedBucket   string
+	composedFile     string
+	composedSources  []string
+	composedEncoding string
+	deleted          []string
 }
 
 // mockGcsWriter implements io.WriteCloser
@@ -51,6 +61,23 @@ func (m *mockGcs) NewWriter(bucketName, fileName, contentType, contentEncoding s
 	return &m.mockWriter, m.errOnNew
 }
 
+func (m *mockGcs) Compose(bucketName, fileName string, sourceNames []string, contentEncoding string) error {
+	m.composedBucket = bucketName
+	m.composedFile = fileName
+	m.composedSources = sourceNames
+	m.composedEncoding = contentEncoding
+	return nil
+}
+
+func (m *mockGcs) NewReader(bucketName, fileName string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.mockWriter.finalContent)), nil
+}
+
+func (m *mockGcs) Delete(bucketName, fileName string) error {
+	m.deleted = append(m.deleted, bucketName+"/"+fileName)
+	return nil
+}
+
 func TestIsAdmin_failsToConstructACL(t *testing.T) {
 	ctx, done, err := sharedtest.NewAEContext(true)
 	assert.Nil(t, err)
@@ -143,6 +170,52 @@ func TestUploadToGCS_handlesErrors(t *testing.T) {
 	assert.EqualError(t, err, "invalid GCS path: /bucket/test.json")
 }
 
+func TestComposeGCS(t *testing.T) {
+	ctx := context.Background()
+	a := NewAPI(ctx).(*apiImpl)
+	mGcs := mockGcs{}
+	a.gcs = &mGcs
+
+	err := a.ComposeGCS("gs://bucket/id/0.json", []string{"gs://bucket/id/chunks/0", "gs://bucket/id/chunks/10"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "bucket", mGcs.composedBucket)
+	assert.Equal(t, "id/0.json", mGcs.composedFile)
+	assert.Equal(t, []string{"id/chunks/0", "id/chunks/10"}, mGcs.composedSources)
+	assert.Equal(t, "gzip", mGcs.composedEncoding)
+
+	err = a.ComposeGCS("gs://bucket/id/0.json", []string{"gs://other/id/chunks/0"}, false)
+	assert.EqualError(t, err, "cannot compose gs://other/id/chunks/0 into a different bucket")
+}
+
+func TestUploadSession(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+
+	_, err = a.GetUploadSession("missing")
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+	_, err = a.UpdateUploadSession("missing", func(*shared.ResultsUploadSession) error { return nil })
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+
+	session := shared.ResultsUploadSession{Uploader: "blade-runner"}
+	assert.Nil(t, a.CreateUploadSession(&session))
+	assert.NotEmpty(t, session.ID)
+
+	updated, err := a.UpdateUploadSession(session.ID, func(s *shared.ResultsUploadSession) error {
+		s.Offset = 10
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(10), updated.Offset)
+
+	loaded, err := a.GetUploadSession(session.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, session.ID, loaded.ID)
+	assert.Equal(t, "blade-runner", loaded.Uploader)
+	assert.Equal(t, int64(10), loaded.Offset)
+}
+
 func TestScheduleResultsTask(t *testing.T) {
 	ctx, done, err := sharedtest.NewAEContext(true)
 	assert.Nil(t, err)
@@ -163,10 +236,12 @@ func TestScheduleResultsTask(t *testing.T) {
 			assert.Equal(t, screenshots, params["screenshots"])
 			assert.Equal(t, "blade-runner", params.Get("uploader"))
 			assert.Equal(t, taskName, params.Get("id"))
+			assert.Equal(t, "reject", params.Get("duplicate_policy"))
 			id = taskName
 			return id, nil
 		})
-	task, err := a.ScheduleResultsTask("blade-runner", results, screenshots, nil, nil)
+	extraParams := map[string]string{"duplicate_policy": "reject"}
+	task, err := a.ScheduleResultsTask("blade-runner", results, screenshots, nil, extraParams)
 	assert.Equal(t, id, task)
 	assert.Nil(t, err)
 
@@ -177,6 +252,7 @@ func TestScheduleResultsTask(t *testing.T) {
 	store.Get(store.NewIDKey("PendingTestRun", intID), &pendingRun)
 	assert.Equal(t, "blade-runner", pendingRun.Uploader)
 	assert.Equal(t, shared.StageWptFyiReceived, pendingRun.Stage)
+	assert.Equal(t, shared.DuplicateRunReject, pendingRun.DuplicatePolicy)
 }
 
 func TestAddTestRun(t *testing.T) {
@@ -192,7 +268,7 @@ func TestAddTestRun(t *testing.T) {
 		},
 	}
 
-	key, err := a.AddTestRun(&testRun)
+	key, err := a.AddTestRun(&testRun, shared.DuplicateRunAllow)
 	assert.Nil(t, err)
 	assert.Equal(t, "TestRun", key.Kind())
 	assert.Equal(t, int64(123456), key.IntID())
@@ -221,7 +297,7 @@ func TestUpdatePendingTestRun(t *testing.T) {
 			FullRevisionHash: sha,
 		},
 	}
-	assert.Nil(t, a.UpdatePendingTestRun(run))
+	assert.Nil(t, a.UpdatePendingTestRun("blade-runner", run))
 	var run2 shared.PendingTestRun
 	store.Get(key, &run2)
 	assert.Equal(t, shared.StageWptFyiReceived, run2.Stage)
@@ -231,7 +307,7 @@ func TestUpdatePendingTestRun(t *testing.T) {
 	// CheckRunID should not be updated; Stage should be transitioned.
 	run.CheckRunID = 0
 	run.Stage = shared.StageValid
-	assert.Nil(t, a.UpdatePendingTestRun(run))
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, run))
 	var run3 shared.PendingTestRun
 	store.Get(key, &run3)
 	assert.Equal(t, int64(100), run3.CheckRunID)
@@ -240,6 +316,439 @@ func TestUpdatePendingTestRun(t *testing.T) {
 
 	// Stage cannot be transitioned backwards.
 	run.Stage = shared.StageWptFyiProcessing
-	assert.EqualError(t, a.UpdatePendingTestRun(run),
+	assert.EqualError(t, a.UpdatePendingTestRun(InternalUsername, run),
 		"cannot transition from VALID to WPTFYI_PROCESSING")
+
+	// Only stage changes are recorded as transitions.
+	run.Stage = shared.StageValid
+	run.Uploader = "blade-runner"
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, run))
+	var run4 shared.PendingTestRun
+	store.Get(key, &run4)
+	assert.Equal(t, "blade-runner", run4.Uploader)
+	assert.Len(t, run4.Transitions, 2)
+	assert.Equal(t, shared.StageWptFyiReceived, run4.Transitions[0].Stage)
+	assert.Equal(t, run2.Created, run4.Transitions[0].Time)
+	assert.Equal(t, shared.StageValid, run4.Transitions[1].Stage)
+	assert.Equal(t, run3.Updated, run4.Transitions[1].Time)
+	// Transitions are attributed to whoever made them, not to the uploader.
+	assert.Equal(t, "blade-runner", run4.Transitions[0].Actor)
+	assert.Equal(t, InternalUsername, run4.Transitions[1].Actor)
+}
+
+func TestReserveQuota(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	// Uploaders are unlimited without a quota.
+	assert.Nil(t, a.ReserveUploadQuota("blade-runner", 1<<40))
+
+	quota := shared.UploaderQuota{RunsPerDay: 1, BytesPerDay: 100, MaxPendingRuns: 1}
+	_, err = store.Put(store.NewNameKey("UploaderQuota", DefaultQuotaName), &quota)
+	assert.Nil(t, err)
+
+	var exceeded *QuotaExceededError
+	assert.Nil(t, a.ReserveUploadQuota("replicant", 60))
+	assert.ErrorAs(t, a.ReserveUploadQuota("replicant", 60), &exceeded)
+	assert.Equal(t, "100 bytes per day", exceeded.Reason)
+
+	pending := shared.PendingTestRun{ID: 1, Uploader: "replicant", Stage: shared.StageWptFyiReceived}
+	assert.Nil(t, a.UpdatePendingTestRun("replicant", pending))
+	assert.ErrorAs(t, a.ReserveUploadQuota("replicant", 0), &exceeded)
+	assert.Equal(t, pendingRunsRetryAfter, exceeded.RetryAfter)
+
+	// Pending runs count towards the daily runs.
+	quota.MaxPendingRuns = 0
+	_, err = store.Put(store.NewNameKey("UploaderQuota", DefaultQuotaName), &quota)
+	assert.Nil(t, err)
+	assert.ErrorAs(t, a.ReserveUploadQuota("replicant", 0), &exceeded)
+	assert.Equal(t, "1 runs per day (0 created, 1 pending)", exceeded.Reason)
+
+	assert.Nil(t, a.ReserveRunQuota(1))
+	// A run whose creation is retried is only counted once.
+	assert.Nil(t, a.ReserveRunQuota(1))
+	other := shared.PendingTestRun{ID: 3, Uploader: "replicant", Stage: shared.StageWptFyiReceived}
+	assert.Nil(t, a.UpdatePendingTestRun("replicant", other))
+	assert.ErrorAs(t, a.ReserveRunQuota(3), &exceeded)
+	assert.Equal(t, "1 runs per day", exceeded.Reason)
+	// Until a run which failed to be created is released.
+	assert.Nil(t, a.ReleaseRunQuota(1))
+	assert.Nil(t, a.ReserveRunQuota(3))
+	// Runs without a pending run aren't limited.
+	assert.Nil(t, a.ReserveRunQuota(2))
+
+	report, err := a.GetQuotaReport()
+	assert.Nil(t, err)
+	assert.Len(t, report, 3)
+	assert.Equal(t, "blade-runner", report[0].Uploader)
+	assert.Equal(t, int64(1<<40), report[0].Usage.Bytes)
+	assert.Equal(t, DefaultQuotaName, report[1].Uploader)
+	assert.Equal(t, "replicant", report[2].Uploader)
+	assert.Equal(t, 1, report[2].Quota.RunsPerDay)
+	assert.Equal(t, 1, report[2].Usage.Runs)
+	assert.Equal(t, int64(60), report[2].Usage.Bytes)
+	assert.Equal(t, 2, report[2].PendingRuns)
+}
+
+func TestAPITokens(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	value, hash, err := shared.NewAPITokenValue()
+	assert.Nil(t, err)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	token := shared.APIToken{
+		Hash:     hash,
+		Uploader: "blade-runner",
+		Scopes:   []string{string(shared.ScopeUploadResults)},
+		Expires:  time.Now().Add(time.Hour),
+	}
+	assert.Nil(t, a.CreateAPIToken(&token))
+	assert.NotZero(t, token.ID)
+
+	found, err := shared.LookupAPIToken(store, value)
+	assert.Nil(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	_, err = shared.LookupAPIToken(store, value+"x")
+	assert.ErrorIs(t, err, shared.ErrInvalidAPIToken)
+
+	tokens, err := a.ListAPITokens("blade-runner")
+	assert.Nil(t, err)
+	assert.Len(t, tokens, 1)
+	tokens, err = a.ListAPITokens("replicant")
+	assert.Nil(t, err)
+	assert.Empty(t, tokens)
+
+	revoked, err := a.RevokeAPIToken(token.ID)
+	assert.Nil(t, err)
+	assert.True(t, revoked.Revoked)
+	_, err = shared.LookupAPIToken(store, value)
+	assert.ErrorIs(t, err, shared.ErrInvalidAPIToken)
+	_, err = a.RevokeAPIToken(token.ID + 1)
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+}
+
+func TestCINotifications(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+
+	now := time.Now()
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	notifications := []shared.CINotification{
+		{Uploader: "taskcluster", BuildID: "group1", Outcome: shared.CINotificationFailed, Received: now.Add(-time.Hour)},
+		{Uploader: "taskcluster", BuildID: "group1", Outcome: shared.CINotificationSucceeded, Received: now},
+		{Uploader: "taskcluster", BuildID: "group2", Outcome: shared.CINotificationFailed, Received: now},
+		{Uploader: "github-actions", BuildID: "group1", Outcome: shared.CINotificationIgnored, Received: now},
+	}
+	for i := range notifications {
+		assert.Nil(t, a.PutCINotification(&notifications[i]))
+		assert.NotZero(t, notifications[i].ID)
+	}
+
+	notifications[0].Replays = 1
+	assert.Nil(t, a.PutCINotification(&notifications[0]))
+	stored, err := a.GetCINotification(notifications[0].ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stored.Replays)
+	_, err = a.GetCINotification(notifications[3].ID + 100)
+	assert.ErrorIs(t, err, shared.ErrNoSuchEntity)
+
+	failed, err := a.ListCINotifications(shared.CINotificationFailed, 1)
+	assert.Nil(t, err)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, notifications[2].ID, failed[0].ID)
+
+	ofBuild, err := a.ListCINotificationsOfBuild("taskcluster", "group1")
+	assert.Nil(t, err)
+	assert.Len(t, ofBuild, 2)
+	assert.Equal(t, notifications[0].ID, ofBuild[0].ID)
+	assert.Equal(t, notifications[1].ID, ofBuild[1].ID)
+}
+
+func TestAddTestRun_duplicates(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	sha := "0123456789012345678901234567890123456789"
+	newRun := func(id int64, labels ...string) *shared.TestRun {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		return &shared.TestRun{
+			ID: id,
+			ProductAtRevision: shared.ProductAtRevision{
+				Product:          shared.Product{BrowserName: "chrome", BrowserVersion: "120", OSName: "linux"},
+				Revision:         sha[:10],
+				FullRevisionHash: sha,
+			},
+			Labels: labels,
+		}
+	}
+	_, err = a.AddTestRun(newRun(1, "stable", "master"), shared.DuplicateRunReject)
+	assert.Nil(t, err)
+	// Runs with different labels aren't duplicates.
+	_, err = a.AddTestRun(newRun(2, "experimental", "master"), shared.DuplicateRunReject)
+	assert.Nil(t, err)
+
+	var duplicate *shared.DuplicateRunError
+	_, err = a.AddTestRun(newRun(3, "master", "stable"), shared.DuplicateRunReject)
+	assert.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, int64(1), duplicate.ExistingID)
+	var pending shared.PendingTestRun
+	assert.Nil(t, store.Get(store.NewIDKey("PendingTestRun", 3), &pending))
+	assert.Equal(t, shared.StageDuplicate, pending.Stage)
+	assert.Equal(t, int64(1), pending.DuplicateOf)
+	assert.Equal(t, shared.DuplicateRunReject, pending.DuplicatePolicy)
+
+	kept := newRun(4, "stable", "master")
+	key, err := a.AddTestRun(kept, shared.DuplicateRunKeep)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), key.IntID())
+	assert.Contains(t, kept.Labels, shared.DuplicateLabel)
+
+	replacement := newRun(5, "stable", "master")
+	replacement.ResultsURL = "https://example.com/replacement.json"
+	key, err = a.AddTestRun(replacement, shared.DuplicateRunReplace)
+	assert.Nil(t, err)
+	assert.Contains(t, []int64{1, 4}, key.IntID())
+	var replaced shared.TestRun
+	assert.Nil(t, store.Get(key, &replaced))
+	assert.Equal(t, replacement.ResultsURL, replaced.ResultsURL)
+	assert.Nil(t, store.Get(store.NewIDKey("PendingTestRun", 5), &pending))
+	assert.Equal(t, key.IntID(), pending.DuplicateOf)
+	assert.Equal(t, shared.DuplicateRunReplace, pending.DuplicatePolicy)
+}
+
+func TestUpdatePendingTestRun_schedulesCallback(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAE := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(ctx).AnyTimes()
+	a := NewAPI(ctx).(*apiImpl)
+	a.AppEngineAPI = mockAE
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.PendingTestRun{
+		ID:        1,
+		Stage:     shared.StageWptFyiReceived,
+		NotifyURL: "https://example.com/notify",
+	}
+	assert.Nil(t, a.UpdatePendingTestRun("blade-runner", run))
+
+	// The callback is only scheduled once the run reaches a terminal stage.
+	mockAE.EXPECT().ScheduleTask(CallbacksQueue, "", CallbackTarget, url.Values{"id": {"1"}}).Return("task", nil)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, shared.PendingTestRun{ID: 1, Stage: shared.StageInvalid}))
+
+	loaded, err := a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/notify", loaded.NotifyURL)
+	assert.False(t, loaded.Delivered())
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.RecordCallbackDelivery(1, shared.CallbackDelivery{Stage: shared.StageInvalid, Time: time.Now(), Error: "timeout"}))
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.RecordCallbackDelivery(1, shared.CallbackDelivery{Stage: shared.StageInvalid, Time: time.Now()}))
+	loaded, err = a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.Len(t, loaded.Deliveries, 2)
+	assert.True(t, loaded.Delivered())
+}
+
+func TestPendingRunCallbacks_timedOut(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAE := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(ctx).AnyTimes()
+	a := NewAPI(ctx).(*apiImpl)
+	a.AppEngineAPI = mockAE
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.PendingTestRun{
+		ID:        1,
+		Stage:     shared.StageWptFyiProcessing,
+		NotifyURL: "https://example.com/notify",
+	}
+	assert.Nil(t, a.UpdatePendingTestRun("blade-runner", run))
+
+	// The timed out run is delivered.
+	callback := url.Values{"id": {"1"}}
+	mockAE.EXPECT().ScheduleTask(CallbacksQueue, "", CallbackTarget, callback).Return("task", nil).Times(2)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.UpdatePendingTestRun(shared.SweeperActor, shared.PendingTestRun{ID: 1, Stage: shared.StageTimedOut}))
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.RecordCallbackDelivery(1, shared.CallbackDelivery{Stage: shared.StageTimedOut, Time: time.Now()}))
+	loaded, err := a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.True(t, loaded.Delivered())
+
+	// And delivered again when it finishes late.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, shared.PendingTestRun{ID: 1, Stage: shared.StageValid}))
+	loaded, err = a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.Equal(t, shared.StageValid, loaded.Stage)
+	assert.False(t, loaded.Delivered())
+	assert.Zero(t, loaded.DeliveryAttempts())
+}
+
+func TestDeliverCallback(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var body []byte
+	var signature, timestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get("X-WPT-Signature")
+		timestamp = r.Header.Get("X-WPT-Timestamp")
+	}))
+	defer server.Close()
+
+	mockAE := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(ctx).AnyTimes()
+	mockAE.EXPECT().GetHTTPClientWithTimeout(gomock.Any()).Return(server.Client())
+	a := NewAPI(ctx).(*apiImpl)
+	a.AppEngineAPI = mockAE
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.PendingTestRun{
+		ID:         1,
+		Uploader:   "blade-runner",
+		Stage:      shared.StageValid,
+		NotifyURL:  server.URL,
+		Deliveries: []shared.CallbackDelivery{{Error: "timeout"}},
+	}
+	// Deliveries fail without a secret.
+	assert.ErrorIs(t, a.DeliverCallback(run), errNoCallbackSecret)
+
+	store := shared.NewAppEngineDatastore(ctx, false)
+	_, err = store.Put(store.NewNameKey("Token", CallbackSecretName("blade-runner")), &shared.Token{Secret: "123"})
+	assert.Nil(t, err)
+	assert.Nil(t, a.DeliverCallback(run))
+	assert.Contains(t, string(body), `"stage":"VALID"`)
+	assert.NotContains(t, string(body), "deliveries")
+	mac := hmac.New(sha256.New, []byte("123"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	assert.NotEmpty(t, timestamp)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func TestGetWebhookSecret(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	_, err = a.GetWebhookSecret("blade-runner")
+	assert.ErrorIs(t, err, ErrNoWebhookSecret)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	_, err = store.Put(store.NewNameKey("Token", WebhookSecretName("blade-runner")), &shared.Token{})
+	assert.Nil(t, err)
+	_, err = a.GetWebhookSecret("blade-runner")
+	assert.ErrorIs(t, err, ErrNoWebhookSecret)
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	_, err = store.Put(store.NewNameKey("Token", WebhookSecretName("blade-runner")), &shared.Token{Secret: "123"})
+	assert.Nil(t, err)
+	secret, err := a.GetWebhookSecret("blade-runner")
+	assert.Nil(t, err)
+	assert.Equal(t, "123", secret)
+}
+
+func TestTimeOutPendingTestRun(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	a := NewAPI(ctx)
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, shared.PendingTestRun{ID: 1, Stage: shared.StageCIRunning}))
+	runs, err := a.ListPendingTestRuns()
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	stuck := runs[0]
+
+	assert.Nil(t, a.TimeOutPendingTestRun(stuck, "Timed out"))
+	run, err := a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.Equal(t, shared.StageTimedOut, run.Stage)
+	assert.Equal(t, "Timed out", run.Error)
+	assert.Len(t, run.Transitions, 2)
+	transition := run.Transitions[1]
+	assert.WithinDuration(t, run.Updated, transition.Time, time.Second)
+	assert.Equal(t, shared.StageTimedOut, transition.Stage)
+	assert.Equal(t, shared.SweeperActor, transition.Actor)
+	assert.Equal(t, "Timed out", transition.Error)
+
+	// The run is no longer in the stage it was stuck in.
+	assert.ErrorIs(t, a.TimeOutPendingTestRun(stuck, "Timed out"), ErrPendingRunChanged)
+	runs, err = a.ListPendingTestRuns()
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+
+	// A run which finishes after timing out is still recorded.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	assert.Nil(t, a.UpdatePendingTestRun(InternalUsername, shared.PendingTestRun{ID: 1, Stage: shared.StageValid}))
+	run, err = a.GetPendingTestRun(1)
+	assert.Nil(t, err)
+	assert.Equal(t, shared.StageValid, run.Stage)
+	assert.Empty(t, run.Error)
+}
+
+func TestReschedulePendingTestRun(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAE := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(ctx).AnyTimes()
+	a := NewAPI(ctx).(*apiImpl)
+	a.AppEngineAPI = mockAE
+
+	var payload url.Values
+	mockAE.EXPECT().ScheduleTask(ResultsQueue, gomock.Any(), ResultsTarget, gomock.Any()).DoAndReturn(
+		func(queueName, taskName, target string, params url.Values) (string, error) {
+			payload = params
+
+			return taskName, nil
+		})
+	task, err := a.ScheduleResultsTask("blade-runner", []string{"gs://blade-runner/test.json"}, nil, nil, nil)
+	assert.Nil(t, err)
+	id, err := strconv.ParseInt(task, 10, 64)
+	assert.Nil(t, err)
+
+	run, err := a.GetPendingTestRun(id)
+	assert.Nil(t, err)
+	assert.Equal(t, payload.Encode(), run.ResultsTask)
+
+	// The same payload is scheduled again, in an unnamed task.
+	mockAE.EXPECT().ScheduleTask(ResultsQueue, "", ResultsTarget, payload).Return("task", nil)
+	assert.Nil(t, a.ReschedulePendingTestRun(*run))
+	rescheduled, err := a.GetPendingTestRun(id)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, rescheduled.Reschedules)
+	assert.Equal(t, shared.StageWptFyiReceived, rescheduled.Stage)
+	assert.ErrorIs(t, a.ReschedulePendingTestRun(*run), ErrPendingRunChanged)
 }
@@ -0,0 +1,210 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/api/webhooks"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// CallbacksQueue is the name of the TaskQueue that POSTs pending runs to their
+// notify_url once they reach a terminal stage. Failed deliveries are retried
+// with exponential backoff by the queue.
+const CallbacksQueue = "results-callbacks"
+
+// CallbackTarget is the handler path for delivering a pending run to its
+// notify_url.
+const CallbackTarget = "/api/results/notify"
+
+// maxCallbackAttempts is the number of attempts after which the delivery of a
+// pending run to its notify_url is given up.
+const maxCallbackAttempts = 10
+
+// callbackTimeout is the timeout of a single delivery attempt.
+const callbackTimeout = 30 * time.Second
+
+// errNoCallbackSecret is returned when an uploader has no secret to sign the
+// deliveries to its notify_urls with.
+var errNoCallbackSecret = errors.New("no callback signing secret is configured")
+
+// CallbackSecretName returns the name of the secret (see shared.GetSecret)
+// which signs the deliveries to the notify_urls of the uploader's runs.
+func CallbackSecretName(uploader string) string {
+	return "callback-secret-" + uploader
+}
+
+// checkNotifyURL checks the (optional) notify_url param of an upload, writing
+// a 400 and returning false if it isn't an absolute HTTP(S) URL.
+func checkNotifyURL(w http.ResponseWriter, extraParams map[string]string) bool {
+	notifyURL := extraParams["notify_url"]
+	if notifyURL == "" {
+		return true
+	}
+	u, err := url.Parse(notifyURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		http.Error(w, fmt.Sprintf("Invalid notify_url %q", notifyURL), http.StatusBadRequest)
+
+		return false
+	}
+
+	return true
+}
+
+// checkCallbackSecret checks that the uploader has a callback signing secret
+// if the upload has a notify_url, writing an error and returning false if not,
+// rather than accepting notifications which can't be delivered.
+func checkCallbackSecret(a API, w http.ResponseWriter, uploader string, extraParams map[string]string) bool {
+	if extraParams["notify_url"] == "" {
+		return true
+	}
+	_, err := a.GetCallbackSecret(uploader)
+	if errors.Is(err, errNoCallbackSecret) {
+		http.Error(w, fmt.Sprintf("notify_url requires a callback signing secret for %s", uploader), http.StatusBadRequest)
+
+		return false
+	} else if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to get the callback secret of %s: %s", uploader, err.Error())
+		http.Error(w, "Failed to get the callback secret", http.StatusInternalServerError)
+
+		return false
+	}
+
+	return true
+}
+
+// GetCallbackSecret gets the secret of the uploader which signs the deliveries
+// to its notify_urls, or errNoCallbackSecret if there is none.
+func (a apiImpl) GetCallbackSecret(uploader string) (string, error) {
+	secret, err := shared.GetSecret(a.store, CallbackSecretName(uploader))
+	if errors.Is(err, shared.ErrNoSuchEntity) || (err == nil && secret == "") {
+		return "", fmt.Errorf("%w for %s", errNoCallbackSecret, uploader)
+	}
+
+	return secret, err
+}
+
+// scheduleCallback schedules the delivery of the pending run to its
+// notify_url. Failures are logged, but otherwise ignored.
+func (a apiImpl) scheduleCallback(id int64) {
+	params := url.Values{"id": {strconv.FormatInt(id, 10)}}
+	if _, err := a.ScheduleTask(CallbacksQueue, "", CallbackTarget, params); err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to schedule callback of pending run %d: %s", id, err.Error())
+	}
+}
+
+func (a apiImpl) GetPendingTestRun(id int64) (*shared.PendingTestRun, error) {
+	var run shared.PendingTestRun
+	if err := a.store.Get(a.store.NewIDKey("PendingTestRun", id), &run); err != nil {
+		return nil, err
+	}
+	run.ID = id
+
+	return &run, nil
+}
+
+// DeliverCallback POSTs the JSON of the pending run to its notify_url, signed
+// with the callback secret of its uploader in the same way as outgoing
+// webhooks.
+func (a apiImpl) DeliverCallback(run shared.PendingTestRun) error {
+	secret, err := a.GetCallbackSecret(run.Uploader)
+	if err != nil {
+		return err
+	}
+	run.Deliveries = nil
+	body, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	hook := webhooks.OutgoingWebhook{URL: run.NotifyURL, Secret: secret}
+
+	return webhooks.Deliver(a.GetHTTPClientWithTimeout(callbackTimeout), hook, webhooks.EventPendingRunFinished, body)
+}
+
+func (a apiImpl) RecordCallbackDelivery(id int64, delivery shared.CallbackDelivery) error {
+	var run shared.PendingTestRun
+
+	return a.store.Update(a.store.NewIDKey("PendingTestRun", id), &run, func(obj interface{}) error {
+		run := obj.(*shared.PendingTestRun)
+		run.Deliveries = append(run.Deliveries, delivery)
+
+		return nil
+	})
+}
+
+// HandleCallbackDelivery handles the POST requests from the results-callbacks
+// TaskQueue, delivering a pending run to its notify_url and recording the
+// attempt. A non-2XX response causes the queue to retry. Only runs which
+// reached a terminal stage are delivered, once per terminal stage, so that
+// a run which timed out is delivered again if it finishes late.
+func HandleCallbackDelivery(a API, w http.ResponseWriter, r *http.Request) {
+	log := shared.GetLogger(a.Context())
+	if r.Header.Get(shared.QueueNameHeader) != CallbacksQueue {
+		http.Error(w, "Callbacks can only be delivered from the "+CallbacksQueue+" queue", http.StatusForbidden)
+
+		return
+	}
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid id param", http.StatusBadRequest)
+
+		return
+	}
+	run, err := a.GetPendingTestRun(id)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		log.Warningf("Pending run %d no longer exists", id)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	switch {
+	case run.NotifyURL == "" || !run.Stage.IsTerminal() || run.Delivered():
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	case run.DeliveryAttempts() >= maxCallbackAttempts:
+		log.Warningf("Giving up delivering pending run %d to %s after %d attempts", id, run.NotifyURL, run.DeliveryAttempts())
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	delivery := shared.CallbackDelivery{Stage: run.Stage, Time: time.Now().UTC()}
+	err = a.DeliverCallback(*run)
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if err := a.RecordCallbackDelivery(id, delivery); err != nil {
+		log.Errorf("Failed to record callback delivery of pending run %d: %s", id, err.Error())
+	}
+	if errors.Is(err, errNoCallbackSecret) {
+		// Retrying won't help until the secret is configured.
+		log.Errorf("Giving up delivering pending run %d to %s: %s", id, run.NotifyURL, err.Error())
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	} else if err != nil {
+		log.Warningf("Failed to deliver pending run %d to %s: %s", id, run.NotifyURL, err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+
+		return
+	}
+	log.Infof("Delivered pending run %d to %s", id, run.NotifyURL)
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,268 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newCallbackRequest() *http.Request {
+	payload := url.Values{"id": {"123"}}
+	req := httptest.NewRequest("POST", CallbackTarget, strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(shared.QueueNameHeader, CallbacksQueue)
+
+	return req
+}
+
+func newNotifiedPendingRun(deliveries ...shared.CallbackDelivery) *shared.PendingTestRun {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &shared.PendingTestRun{
+		ID:         123,
+		Uploader:   "blade-runner",
+		Stage:      shared.StageValid,
+		NotifyURL:  "https://example.com/notify",
+		Deliveries: deliveries,
+	}
+}
+
+func TestCheckNotifyURL(t *testing.T) {
+	for _, valid := range []string{"", "https://example.com/notify", "http://localhost:8080/"} {
+		assert.True(t, checkNotifyURL(httptest.NewRecorder(), map[string]string{"notify_url": valid}), valid)
+	}
+	for _, invalid := range []string{"example.com/notify", "ftp://example.com/", "https://", ":"} {
+		resp := httptest.NewRecorder()
+		assert.False(t, checkNotifyURL(resp, map[string]string{"notify_url": invalid}), invalid)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	}
+}
+
+func TestHandleCallbackDelivery(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	run := newNotifiedPendingRun()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil),
+		mockAE.EXPECT().DeliverCallback(*run).Return(nil),
+		mockAE.EXPECT().RecordCallbackDelivery(int64(123), gomock.Any()).DoAndReturn(
+			func(_ int64, delivery shared.CallbackDelivery) error {
+				assert.Equal(t, shared.StageValid, delivery.Stage)
+				assert.Empty(t, delivery.Error)
+				assert.False(t, delivery.Time.IsZero())
+
+				return nil
+			}),
+	)
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestHandleCallbackDelivery_failure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := newNotifiedPendingRun(shared.CallbackDelivery{Stage: shared.StageValid, Error: "timeout"})
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil),
+		mockAE.EXPECT().DeliverCallback(*run).Return(errors.New("HTTP status 500")),
+		mockAE.EXPECT().RecordCallbackDelivery(int64(123), gomock.Any()).DoAndReturn(
+			func(_ int64, delivery shared.CallbackDelivery) error {
+				assert.Equal(t, "HTTP status 500", delivery.Error)
+
+				return nil
+			}),
+	)
+
+	// The queue retries the delivery on a non-2XX response.
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadGateway, resp.Code)
+}
+
+func TestHandleCallbackDelivery_noSecret(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	run := newNotifiedPendingRun()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil),
+		mockAE.EXPECT().DeliverCallback(*run).Return(fmt.Errorf("%w for blade-runner", errNoCallbackSecret)),
+		mockAE.EXPECT().RecordCallbackDelivery(int64(123), gomock.Any()).Return(nil),
+	)
+
+	// The delivery isn't retried, since it can't succeed.
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestHandleCallbackDelivery_delivered(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := newNotifiedPendingRun(shared.CallbackDelivery{Stage: shared.StageValid})
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil)
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestHandleCallbackDelivery_notFromQueue(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	req.Header.Del(shared.QueueNameHeader)
+	resp := httptest.NewRecorder()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestHandleCallbackDelivery_notTerminal(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	run := newNotifiedPendingRun()
+	run.Stage = shared.StageCIRunning
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	// Nothing is delivered (or recorded) until the run is processed.
+	mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil)
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestHandleCallbackDelivery_timedOut(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	// The run was delivered when it timed out, then finished late.
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := newNotifiedPendingRun(shared.CallbackDelivery{Stage: shared.StageTimedOut})
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil),
+		mockAE.EXPECT().DeliverCallback(*run).Return(nil),
+		mockAE.EXPECT().RecordCallbackDelivery(int64(123), gomock.Any()).DoAndReturn(
+			func(_ int64, delivery shared.CallbackDelivery) error {
+				assert.Equal(t, shared.StageValid, delivery.Stage)
+
+				return nil
+			}),
+	)
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestHandleCallbackDelivery_givesUp(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newCallbackRequest()
+	resp := httptest.NewRecorder()
+	run := newNotifiedPendingRun()
+	for i := 0; i < maxCallbackAttempts; i++ {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		run.Deliveries = append(run.Deliveries, shared.CallbackDelivery{Stage: shared.StageValid, Error: "timeout"})
+	}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockAE.EXPECT().GetPendingTestRun(int64(123)).Return(run, nil)
+
+	HandleCallbackDelivery(mockAE, resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+}
+
+func TestHandleResultsUpload_invalidNotifyURL(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{
+		"result_url": {"https://wpt.fyi/test.json.gz"},
+		"notify_url": {"ftp://example.com/notify"},
+	}
+	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{Username: "blade-runner", Password: "123"}, nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestHandleResultsUpload_notifyURLWithoutSecret(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{
+		"result_url": {"https://wpt.fyi/test.json.gz"},
+		"notify_url": {"https://example.com/notify"},
+	}
+	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{Username: "blade-runner", Password: "123"}, nil),
+		mockAE.EXPECT().GetCallbackSecret("blade-runner").Return("", errNoCallbackSecret),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"sort"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+func (a apiImpl) PutCINotification(notification *shared.CINotification) error {
+	key := a.store.NewIncompleteKey(shared.CINotificationKind)
+	if notification.ID != 0 {
+		key = a.store.NewIDKey(shared.CINotificationKind, notification.ID)
+	}
+	notification.Updated = time.Now()
+	key, err := a.store.Put(key, notification)
+	if err != nil {
+		return err
+	}
+	notification.ID = key.IntID()
+
+	return nil
+}
+
+func (a apiImpl) GetCINotification(id int64) (*shared.CINotification, error) {
+	var notification shared.CINotification
+	if err := a.store.Get(a.store.NewIDKey(shared.CINotificationKind, id), &notification); err != nil {
+		return nil, err
+	}
+	notification.ID = id
+
+	return &notification, nil
+}
+
+func (a apiImpl) ListCINotifications(outcome shared.CINotificationOutcome, limit int) ([]shared.CINotification, error) {
+	q := a.store.NewQuery(shared.CINotificationKind).
+		Filter("Outcome =", string(outcome)).
+		Order("-Received").
+		Limit(limit)
+
+	return a.getCINotifications(q)
+}
+
+func (a apiImpl) ListCINotificationsOfBuild(uploader, buildID string) ([]shared.CINotification, error) {
+	q := a.store.NewQuery(shared.CINotificationKind).
+		Filter("Uploader =", uploader).
+		Filter("BuildID =", buildID)
+	notifications, err := a.getCINotifications(q)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].Received.Before(notifications[j].Received)
+	})
+
+	return notifications, nil
+}
+
+func (a apiImpl) getCINotifications(q shared.Query) ([]shared.CINotification, error) {
+	var notifications []shared.CINotification
+	keys, err := a.store.GetAll(q, &notifications)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		notifications[i].ID = key.IntID()
+	}
+
+	return notifications, nil
+}
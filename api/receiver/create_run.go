@@ -6,6 +6,7 @@ package receiver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	mapset "github.com/deckarep/golang-set"
+	"github.com/web-platform-tests/wpt.fyi/api/alerts"
 	"github.com/web-platform-tests/wpt.fyi/api/checks"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
@@ -21,15 +23,24 @@ import (
 // only be accessed by services in this AppEngine project via Datastore.
 const InternalUsername = "_processor"
 
-// HandleResultsCreate handles the POST requests for creating test runs.
+// HandleResultsCreate handles the POST requests for creating test runs. The
+// "duplicate_policy" param decides what happens to runs which duplicate an
+// existing run; see shared.DuplicateRunPolicy.
 func HandleResultsCreate(a API, s checks.API, w http.ResponseWriter, r *http.Request) {
 	logger := shared.GetLogger(a.Context())
 
-	if AuthenticateUploader(a, r) != InternalUsername {
+	uploader, token := AuthenticateUploaderToken(a, r, shared.ScopeCreateRuns)
+	if uploader != InternalUsername {
 		http.Error(w, "This is a private API.", http.StatusUnauthorized)
 
 		return
 	}
+	policy, err := shared.ParseDuplicateRunPolicy(r.URL.Query().Get("duplicate_policy"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -44,6 +55,10 @@ func HandleResultsCreate(a API, s checks.API, w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if !checkTokenProducts(w, token, testRun.BrowserName) {
+		return
+	}
+
 	if testRun.TimeStart.IsZero() {
 		testRun.TimeStart = time.Now()
 	}
@@ -71,8 +86,31 @@ func HandleResultsCreate(a API, s checks.API, w http.ResponseWriter, r *http.Req
 	// nolint:staticcheck // TODO: Fix staticcheck lint error (SA1019).
 	testRun.Revision = testRun.FullRevisionHash[:10]
 
-	key, err := a.AddTestRun(&testRun)
-	if err != nil {
+	if err := a.ReserveRunQuota(testRun.ID); err != nil {
+		writeQuotaError(a, w, err)
+
+		return
+	}
+
+	// The run may replace an existing run, and take its ID, so keep the ID of
+	// its pending run.
+	pendingID := testRun.ID
+	key, err := a.AddTestRun(&testRun, policy)
+	var duplicate *shared.DuplicateRunError
+	isDuplicate := errors.As(err, &duplicate)
+	// The quota of a run which wasn't created is released, unless an earlier
+	// attempt to create it did.
+	created := errors.Is(err, shared.ErrEntityAlreadyExists) || (isDuplicate && duplicate.ExistingID == pendingID)
+	if err != nil && !created {
+		if err := a.ReleaseRunQuota(pendingID); err != nil {
+			logger.Errorf("Failed to release quota of run %v: %s", pendingID, err.Error())
+		}
+	}
+	if isDuplicate {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -80,22 +118,32 @@ func HandleResultsCreate(a API, s checks.API, w http.ResponseWriter, r *http.Req
 	// Copy int64 representation of key into TestRun.ID so that clients can
 	// inspect/use key value.
 	testRun.ID = key.IntID()
+	if pendingID == 0 {
+		pendingID = testRun.ID
+	}
 
 	spec := shared.ProductSpec{} // nolint:exhaustruct // TODO: Fix exhaustruct lint error
 	spec.BrowserName = testRun.BrowserName
 	spec.Labels = mapset.NewSet(testRun.Channel())
+	if repoLabel := testRun.RepoLabel(); repoLabel != "" {
+		// Only the runs of the same repo are compared.
+		spec.Labels.Add(repoLabel)
+	}
 	err = s.ScheduleResultsProcessing(testRun.FullRevisionHash, spec)
 	if err != nil {
 		logger.Warningf("Failed to schedule results: %s", err.Error())
 	}
+	if err := alerts.ScheduleEvaluation(a, testRun); err != nil {
+		logger.Warningf("Failed to schedule regression alerts: %s", err.Error())
+	}
 
 	// nolint:exhaustruct // TODO: Fix exhaustruct lint error.
 	pendingRun := shared.PendingTestRun{
-		ID:                testRun.ID,
+		ID:                pendingID,
 		Stage:             shared.StageValid,
 		ProductAtRevision: testRun.ProductAtRevision,
 	}
-	if err := a.UpdatePendingTestRun(pendingRun); err != nil {
+	if err := a.UpdatePendingTestRun(uploader, pendingRun); err != nil {
 		// This is a non-fatal error; don't return.
 		logger.Errorf("Failed to update pending test run: %s", err.Error())
 	}
@@ -8,14 +8,17 @@ package receiver
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/api/alerts"
 	"github.com/web-platform-tests/wpt.fyi/api/checks/mock_checks"
 	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
 	"github.com/web-platform-tests/wpt.fyi/shared"
@@ -74,9 +77,10 @@ func TestHandleResultsCreate(t *testing.T) {
 	mockS := mock_checks.NewMockAPI(mockCtrl)
 	gomock.InOrder(
 		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
-		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String())).Return(testKey, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(12345)).Return(nil),
+		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String()), shared.DuplicateRunAllow).Return(testKey, nil),
 		mockS.EXPECT().ScheduleResultsProcessing(sha, sharedtest.SameProductSpec("firefox")).Return(nil),
-		mockAE.EXPECT().UpdatePendingTestRun(pendingRun).Return(nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, pendingRun).Return(nil),
 	)
 
 	w := httptest.NewRecorder()
@@ -145,9 +149,50 @@ func TestHandleResultsCreate_PRBase(t *testing.T) {
 	mockS := mock_checks.NewMockAPI(mockCtrl)
 	gomock.InOrder(
 		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
-		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String())).Return(testKey, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(12346)).Return(nil),
+		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String()), shared.DuplicateRunAllow).Return(testKey, nil),
 		mockS.EXPECT().ScheduleResultsProcessing(sha, sharedtest.SameProductSpec("safari[experimental]")).Return(nil),
-		mockAE.EXPECT().UpdatePendingTestRun(pendingRun).Return(nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, pendingRun).Return(nil),
+	)
+
+	w := httptest.NewRecorder()
+	HandleResultsCreate(mockAE, mockS, w, req)
+	resp := w.Result()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestHandleResultsCreate_MasterSchedulesAlerts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha := "0123456789012345678901234567890123456789"
+	payload := map[string]interface{}{
+		"id":                 12347,
+		"browser_name":       "chrome",
+		"browser_version":    "140.0",
+		"os_name":            "linux",
+		"revision":           sha[:10],
+		"full_revision_hash": sha,
+		"labels":             []string{shared.MasterLabel, shared.StableLabel, "chrome"},
+	}
+	body, err := json.Marshal(payload)
+	assert.Nil(t, err)
+	req := httptest.NewRequest("POST", "/api/results/create", strings.NewReader(string(body)))
+	req.SetBasicAuth("_processor", "secret-token")
+	testKey := &sharedtest.MockKey{TypeName: "TestRun", ID: 12347}
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(12347)).Return(nil),
+		mockAE.EXPECT().AddTestRun(gomock.Any(), shared.DuplicateRunAllow).Return(testKey, nil),
+		mockS.EXPECT().ScheduleResultsProcessing(sha, sharedtest.SameProductSpec("chrome[stable]")).Return(nil),
+		mockAE.EXPECT().ScheduleTask(
+			alerts.AlertsQueue, "", alerts.EvaluateTarget, url.Values{"run_id": []string{"12347"}},
+		).Return("task", nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, gomock.Any()).Return(nil),
 	)
 
 	w := httptest.NewRecorder()
@@ -194,9 +239,10 @@ func TestHandleResultsCreate_NoTimestamps(t *testing.T) {
 	mockS := mock_checks.NewMockAPI(mockCtrl)
 	gomock.InOrder(
 		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
-		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String())).Return(testKey, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(0)).Return(nil),
+		mockAE.EXPECT().AddTestRun(sharedtest.SameProductSpec(testRunIn.String()), shared.DuplicateRunAllow).Return(testKey, nil),
 		mockS.EXPECT().ScheduleResultsProcessing(sha, sharedtest.SameProductSpec("firefox")).Return(nil),
-		mockAE.EXPECT().UpdatePendingTestRun(pendingRun).Return(nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, pendingRun).Return(nil),
 	)
 
 	w := httptest.NewRecorder()
@@ -298,3 +344,128 @@ func TestHandleResultsCreate_WrongPassword(t *testing.T) {
 	HandleResultsCreate(mockAE, mockS, resp, req)
 	assert.Equal(t, http.StatusUnauthorized, resp.Code)
 }
+
+func TestHandleResultsCreate_DuplicateRejected(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	body := `{"id": 123, "browser_name": "firefox", "full_revision_hash": "0123456789012345678901234567890123456789"}`
+	req := httptest.NewRequest("POST", "/api/results/create?duplicate_policy=reject", strings.NewReader(body))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+
+	// Neither ScheduleResultsProcessing nor UpdatePendingTestRun are expected.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(123)).Return(nil),
+		mockAE.EXPECT().AddTestRun(gomock.Any(), shared.DuplicateRunReject).
+			Return(nil, &shared.DuplicateRunError{ExistingID: 100}),
+		// The run wasn't created, so it doesn't count towards the quota.
+		mockAE.EXPECT().ReleaseRunQuota(int64(123)).Return(nil),
+	)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Contains(t, resp.Body.String(), "duplicate of existing run 100")
+}
+
+func TestHandleResultsCreate_Retried(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	body := `{"id": 123, "browser_name": "firefox", "full_revision_hash": "0123456789012345678901234567890123456789"}`
+	req := httptest.NewRequest("POST", "/api/results/create?duplicate_policy=reject", strings.NewReader(body))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+
+	// The run was created by an earlier attempt, so its quota isn't released.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(123)).Return(nil),
+		mockAE.EXPECT().AddTestRun(gomock.Any(), shared.DuplicateRunReject).
+			Return(nil, &shared.DuplicateRunError{ExistingID: 123}),
+	)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
+func TestHandleResultsCreate_AddFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	body := `{"id": 123, "browser_name": "firefox", "full_revision_hash": "0123456789012345678901234567890123456789"}`
+	req := httptest.NewRequest("POST", "/api/results/create", strings.NewReader(body))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(123)).Return(nil),
+		mockAE.EXPECT().AddTestRun(gomock.Any(), gomock.Any()).Return(nil, errors.New("datastore unavailable")),
+		mockAE.EXPECT().ReleaseRunQuota(int64(123)).Return(nil),
+	)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestHandleResultsCreate_DuplicateReplaced(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	sha := "0123456789012345678901234567890123456789"
+	body := `{"id": 123, "browser_name": "firefox", "full_revision_hash": "` + sha + `"}`
+	req := httptest.NewRequest("POST", "/api/results/create?duplicate_policy=replace", strings.NewReader(body))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+
+	// The run takes the ID of the run it replaces.
+	testKey := &sharedtest.MockKey{TypeName: "TestRun", ID: 100}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(123)).Return(nil),
+		mockAE.EXPECT().AddTestRun(gomock.Any(), shared.DuplicateRunReplace).Return(testKey, nil),
+		mockS.EXPECT().ScheduleResultsProcessing(sha, sharedtest.SameProductSpec("firefox")).Return(nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, gomock.Any()).DoAndReturn(func(_ string, run shared.PendingTestRun) error {
+			assert.Equal(t, int64(123), run.ID)
+			assert.Equal(t, shared.StageValid, run.Stage)
+
+			return nil
+		}),
+	)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var testRun shared.TestRun
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &testRun))
+	assert.Equal(t, int64(100), testRun.ID)
+}
+
+func TestHandleResultsCreate_InvalidDuplicatePolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("POST", "/api/results/create?duplicate_policy=ignore", strings.NewReader("{}"))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+	mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil)
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,84 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"fmt"
+	"net/http"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// checkDuplicatePolicy checks the (optional) duplicate_policy param of an
+// upload, writing a 400 and returning false if it isn't a known policy.
+func checkDuplicatePolicy(w http.ResponseWriter, extraParams map[string]string) bool {
+	if _, err := shared.ParseDuplicateRunPolicy(extraParams["duplicate_policy"]); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return false
+	}
+
+	return true
+}
+
+// findDuplicateRun returns the key of an existing run (other than the run
+// itself) of the same revision, browser and version, OS and version, and set
+// of labels as the given run, or nil if there is none. The duplicate label
+// itself is ignored.
+//
+// nolint:ireturn // TODO: Fix ireturn lint error
+func (a apiImpl) findDuplicateRun(testRun *shared.TestRun) (shared.Key, error) {
+	q := a.store.NewQuery("TestRun").
+		Filter("FullRevisionHash =", testRun.FullRevisionHash).
+		Filter("BrowserName =", testRun.BrowserName).
+		Filter("BrowserVersion =", testRun.BrowserVersion).
+		Filter("OSName =", testRun.OSName).
+		Filter("OSVersion =", testRun.OSVersion)
+	var runs shared.TestRuns
+	keys, err := a.store.GetAll(q, &runs)
+	if err != nil {
+		return nil, err
+	}
+	labels := runLabels(testRun.Labels)
+	for i, run := range runs {
+		if keys[i].IntID() != testRun.ID && runLabels(run.Labels).Equal(labels) {
+			return keys[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// runLabels returns the set of labels which make runs distinct.
+func runLabels(labels []string) mapset.Set {
+	set := shared.NewSetFromStringSlice(labels)
+	set.Remove(shared.DuplicateLabel)
+
+	return set
+}
+
+// recordDuplicateRun records on the pending run with the given ID that it
+// duplicated the existing run, and what was done about it. Failures are
+// logged, but otherwise ignored.
+func (a apiImpl) recordDuplicateRun(pendingID, existingID int64, policy shared.DuplicateRunPolicy) {
+	shared.GetLogger(a.Context()).Infof("Run %d duplicates run %d (policy: %s)", pendingID, existingID, policy)
+	if pendingID == 0 {
+		return
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	pending := shared.PendingTestRun{
+		ID:              pendingID,
+		DuplicateOf:     existingID,
+		DuplicatePolicy: policy,
+	}
+	if policy == shared.DuplicateRunReject {
+		pending.Stage = shared.StageDuplicate
+		pending.Error = fmt.Sprintf("Rejected as a duplicate of run %d", existingID)
+	}
+	if err := a.UpdatePendingTestRun(InternalUsername, pending); err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to record duplicate on pending run %d: %s", pendingID, err.Error())
+	}
+}
@@ -6,6 +6,7 @@ package receiver
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"cloud.google.com/go/storage"
@@ -15,21 +16,35 @@ import (
 
 type gcs interface {
 	NewWriter(bucketName, fileName, contentType, contentEncoding string) (io.WriteCloser, error)
+	Compose(bucketName, fileName string, sourceNames []string, contentEncoding string) error
+	NewReader(bucketName, fileName string) (io.ReadCloser, error)
+	Delete(bucketName, fileName string) error
 }
 
+// maxComposeSources is the maximum number of objects GCS can compose at once.
+const maxComposeSources = 32
+
 type gcsImpl struct {
 	ctx    context.Context // nolint:containedctx // TODO: Fix containedctx lint error
 	client *storage.Client
 }
 
-func (g *gcsImpl) NewWriter(bucketName, fileName, contentType, contentEncoding string) (io.WriteCloser, error) {
+func (g *gcsImpl) initClient() error {
 	if g.client == nil {
 		var err error
 		g.client, err = storage.NewClient(g.ctx)
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
+
+	return nil
+}
+
+func (g *gcsImpl) NewWriter(bucketName, fileName, contentType, contentEncoding string) (io.WriteCloser, error) {
+	if err := g.initClient(); err != nil {
+		return nil, err
+	}
 	bucket := g.client.Bucket(bucketName)
 	w := bucket.Object(fileName).NewWriter(g.ctx)
 	if contentType != "" {
@@ -41,3 +56,52 @@ func (g *gcsImpl) NewWriter(bucketName, fileName, contentType, contentEncoding s
 
 	return w, nil
 }
+
+// Compose concatenates the source objects into fileName, in batches of at most
+// maxComposeSources objects (the destination being the first source of each
+// subsequent batch).
+func (g *gcsImpl) Compose(bucketName, fileName string, sourceNames []string, contentEncoding string) error {
+	if len(sourceNames) == 0 {
+		return errors.New("no objects to compose")
+	}
+	if err := g.initClient(); err != nil {
+		return err
+	}
+	bucket := g.client.Bucket(bucketName)
+	dst := bucket.Object(fileName)
+	var composed []*storage.ObjectHandle
+	for len(sourceNames) > 0 {
+		n := min(len(sourceNames), maxComposeSources-len(composed))
+		sources := composed
+		for _, name := range sourceNames[:n] {
+			sources = append(sources, bucket.Object(name))
+		}
+		sourceNames = sourceNames[n:]
+		composer := dst.ComposerFrom(sources...)
+		if contentEncoding != "" {
+			composer.ContentEncoding = contentEncoding
+		}
+		if _, err := composer.Run(g.ctx); err != nil {
+			return err
+		}
+		composed = []*storage.ObjectHandle{dst}
+	}
+
+	return nil
+}
+
+func (g *gcsImpl) NewReader(bucketName, fileName string) (io.ReadCloser, error) {
+	if err := g.initClient(); err != nil {
+		return nil, err
+	}
+
+	return g.client.Bucket(bucketName).Object(fileName).NewReader(g.ctx)
+}
+
+func (g *gcsImpl) Delete(bucketName, fileName string) error {
+	if err := g.initClient(); err != nil {
+		return err
+	}
+
+	return g.client.Bucket(bucketName).Object(fileName).Delete(g.ctx)
+}
@@ -36,13 +36,128 @@ func apiResultsCreateHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func apiPendingTestRunUpdateHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
-		http.Error(w, "Only PATCH is supported", http.StatusMethodNotAllowed)
+	if r.Method != http.MethodGet && r.Method != http.MethodPatch {
+		http.Error(w, "Only GET and PATCH are supported", http.StatusMethodNotAllowed)
 
 		return
 	}
 
 	ctx := r.Context()
 	a := NewAPI(ctx)
+	if r.Method == http.MethodGet {
+		HandleGetPendingTestRun(a, w, r)
+
+		return
+	}
 	HandleUpdatePendingTestRun(a, w, r)
 }
+
+func apiUploadSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleUploadSessionCreate(a, w, r)
+}
+
+func apiUploadSessionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		HandleUploadSessionStatus(a, w, r)
+	case http.MethodPut:
+		HandleUploadSessionChunk(a, w, r)
+	default:
+		http.Error(w, "Only GET and PUT are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func apiUploadSessionFinalizeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleUploadSessionFinalize(a, w, r)
+}
+
+func apiQuotasHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleQuotaReport(a, w, r)
+}
+
+func apiTokensHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	switch r.Method {
+	case http.MethodGet:
+		HandleAPITokenList(a, w, r)
+	case http.MethodPost:
+		HandleAPITokenCreate(a, w, r)
+	default:
+		http.Error(w, "Only GET and POST are supported", http.StatusMethodNotAllowed)
+	}
+}
+
+func apiTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleAPITokenRevoke(a, w, r)
+}
+
+func apiResultsMergeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleResultsMerge(a, w, r)
+}
+
+func apiResultsNotifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandleCallbackDelivery(a, w, r)
+}
+
+func apiPendingTestRunsSweepHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	a := NewAPI(ctx)
+	HandlePendingRunSweep(a, w, r)
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/metrics"
+)
+
+// HandleResultsMerge handles the POST requests for merging the (chunk)
+// result_files of a sharded run. It responds with the merged wptreport, or a
+// JSON list of conflicts between the chunks, without storing anything; it lets
+// uploaders check a set of chunks before uploading them.
+func HandleResultsMerge(a API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) && AuthenticateUploader(a, r, shared.ScopeUploadResults) == "" {
+		http.Error(w, "Authentication error", http.StatusUnauthorized)
+
+		return
+	}
+
+	var files []*multipart.FileHeader
+	if err := r.ParseMultipartForm(32 << 20); err == nil && r.MultipartForm.File != nil {
+		files = r.MultipartForm.File["result_file"]
+	}
+	if len(files) == 0 {
+		http.Error(w, "No result_file found", http.StatusBadRequest)
+
+		return
+	}
+
+	chunks := make([][]byte, len(files))
+	for i, header := range files {
+		report, err := readReportFile(header)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to read %s: %s", header.Filename, err.Error()), http.StatusBadRequest)
+
+			return
+		}
+		chunks[i] = report
+	}
+
+	merged, err := metrics.MergeReports(chunks)
+	var conflicts metrics.MergeConflictError
+	if errors.As(err, &conflicts) {
+		shared.GetLogger(a.Context()).Infof("Found %d conflicts merging %d chunks", len(conflicts.Conflicts), len(files))
+		writeJSON(w, http.StatusConflict, conflicts)
+
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(merged)
+}
+
+// readReportFile reads an uploaded (possibly gzipped) wptreport.
+func readReportFile(header *multipart.FileHeader) ([]byte, error) {
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	var reader io.Reader = br
+	if prefix, _ := br.Peek(2); isGzip(prefix) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,123 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/metrics"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newMergeRequest(t *testing.T, reports ...string) *http.Request {
+	buffer := new(bytes.Buffer)
+	writer := multipart.NewWriter(buffer)
+	for _, report := range reports {
+		writeResultFile(t, writer, "chunk.json.gz", report)
+	}
+	writer.Close()
+	req := httptest.NewRequest("POST", "/api/results/merge", buffer)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth("blade-runner", "123")
+
+	return req
+}
+
+func TestHandleResultsMerge(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newMergeRequest(t, validReport("/a.html"), validReport("/b.html", "/c.html"))
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsMerge(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var merged struct {
+		Results []metrics.TestResults `json:"results"`
+		RunInfo map[string]string     `json:"run_info"`
+	}
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &merged))
+	assert.Len(t, merged.Results, 3)
+	assert.Equal(t, "chrome", merged.RunInfo["product"])
+}
+
+func TestHandleResultsMerge_conflicts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newMergeRequest(t, validReport("/a.html"), validReport("/b.html", "/a.html"))
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsMerge(mockAE, resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	var conflicts metrics.MergeConflictError
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &conflicts))
+	assert.Len(t, conflicts.Conflicts, 1)
+	assert.Equal(t, metrics.ConflictDuplicateTest, conflicts.Conflicts[0].Kind)
+	assert.Equal(t, "/a.html", conflicts.Conflicts[0].Test)
+	assert.Equal(t, []int{0, 1}, conflicts.Conflicts[0].Chunks)
+}
+
+func TestHandleResultsMerge_invalidReport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newMergeRequest(t, validReport("/a.html"), `{"results": [`)
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsMerge(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestHandleResultsMerge_unauthenticated(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newMergeRequest(t, validReport("/a.html"))
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "456"}, nil),
+	)
+
+	HandleResultsMerge(mockAE, resp, req)
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+}
@@ -47,18 +47,32 @@ func (m *MockAPI) EXPECT() *MockAPIMockRecorder {
 }
 
 // AddTestRun mocks base method.
-func (m *MockAPI) AddTestRun(testRun *shared.TestRun) (shared.Key, error) {
+func (m *MockAPI) AddTestRun(testRun *shared.TestRun, policy shared.DuplicateRunPolicy) (shared.Key, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddTestRun", testRun)
+	ret := m.ctrl.Call(m, "AddTestRun", testRun, policy)
 	ret0, _ := ret[0].(shared.Key)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // AddTestRun indicates an expected call of AddTestRun.
-func (mr *MockAPIMockRecorder) AddTestRun(testRun any) *gomock.Call {
+func (mr *MockAPIMockRecorder) AddTestRun(testRun, policy any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTestRun", reflect.TypeOf((*MockAPI)(nil).AddTestRun), testRun)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTestRun", reflect.TypeOf((*MockAPI)(nil).AddTestRun), testRun, policy)
+}
+
+// ComposeGCS mocks base method.
+func (m *MockAPI) ComposeGCS(gcsPath string, sources []string, gzipped bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ComposeGCS", gcsPath, sources, gzipped)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ComposeGCS indicates an expected call of ComposeGCS.
+func (mr *MockAPIMockRecorder) ComposeGCS(gcsPath, sources, gzipped any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComposeGCS", reflect.TypeOf((*MockAPI)(nil).ComposeGCS), gcsPath, sources, gzipped)
 }
 
 // Context mocks base method.
@@ -75,6 +89,107 @@ func (mr *MockAPIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAPI)(nil).Context))
 }
 
+// CreateAPIToken mocks base method.
+func (m *MockAPI) CreateAPIToken(token *shared.APIToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIToken indicates an expected call of CreateAPIToken.
+func (mr *MockAPIMockRecorder) CreateAPIToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIToken", reflect.TypeOf((*MockAPI)(nil).CreateAPIToken), token)
+}
+
+// CreateUploadSession mocks base method.
+func (m *MockAPI) CreateUploadSession(session *shared.ResultsUploadSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUploadSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUploadSession indicates an expected call of CreateUploadSession.
+func (mr *MockAPIMockRecorder) CreateUploadSession(session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUploadSession", reflect.TypeOf((*MockAPI)(nil).CreateUploadSession), session)
+}
+
+// DeleteGCS mocks base method.
+func (m *MockAPI) DeleteGCS(gcsPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGCS", gcsPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGCS indicates an expected call of DeleteGCS.
+func (mr *MockAPIMockRecorder) DeleteGCS(gcsPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGCS", reflect.TypeOf((*MockAPI)(nil).DeleteGCS), gcsPath)
+}
+
+// DeliverCallback mocks base method.
+func (m *MockAPI) DeliverCallback(run shared.PendingTestRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeliverCallback", run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeliverCallback indicates an expected call of DeliverCallback.
+func (mr *MockAPIMockRecorder) DeliverCallback(run any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeliverCallback", reflect.TypeOf((*MockAPI)(nil).DeliverCallback), run)
+}
+
+// GetAPIToken mocks base method.
+func (m *MockAPI) GetAPIToken(token string) (*shared.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", token)
+	ret0, _ := ret[0].(*shared.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockAPIMockRecorder) GetAPIToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockAPI)(nil).GetAPIToken), token)
+}
+
+// GetCINotification mocks base method.
+func (m *MockAPI) GetCINotification(id int64) (*shared.CINotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCINotification", id)
+	ret0, _ := ret[0].(*shared.CINotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCINotification indicates an expected call of GetCINotification.
+func (mr *MockAPIMockRecorder) GetCINotification(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCINotification", reflect.TypeOf((*MockAPI)(nil).GetCINotification), id)
+}
+
+// GetCallbackSecret mocks base method.
+func (m *MockAPI) GetCallbackSecret(uploader string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCallbackSecret", uploader)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCallbackSecret indicates an expected call of GetCallbackSecret.
+func (mr *MockAPIMockRecorder) GetCallbackSecret(uploader any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCallbackSecret", reflect.TypeOf((*MockAPI)(nil).GetCallbackSecret), uploader)
+}
+
 // GetGitHubClient mocks base method.
 func (m *MockAPI) GetGitHubClient() (*github.Client, error) {
 	m.ctrl.T.Helper()
@@ -132,6 +247,51 @@ func (mr *MockAPIMockRecorder) GetHostname() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHostname", reflect.TypeOf((*MockAPI)(nil).GetHostname))
 }
 
+// GetPendingRunTimeouts mocks base method.
+func (m *MockAPI) GetPendingRunTimeouts() (shared.PendingRunTimeouts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingRunTimeouts")
+	ret0, _ := ret[0].(shared.PendingRunTimeouts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingRunTimeouts indicates an expected call of GetPendingRunTimeouts.
+func (mr *MockAPIMockRecorder) GetPendingRunTimeouts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingRunTimeouts", reflect.TypeOf((*MockAPI)(nil).GetPendingRunTimeouts))
+}
+
+// GetPendingTestRun mocks base method.
+func (m *MockAPI) GetPendingTestRun(id int64) (*shared.PendingTestRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingTestRun", id)
+	ret0, _ := ret[0].(*shared.PendingTestRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingTestRun indicates an expected call of GetPendingTestRun.
+func (mr *MockAPIMockRecorder) GetPendingTestRun(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingTestRun", reflect.TypeOf((*MockAPI)(nil).GetPendingTestRun), id)
+}
+
+// GetQuotaReport mocks base method.
+func (m *MockAPI) GetQuotaReport() ([]shared.UploaderQuotaReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaReport")
+	ret0, _ := ret[0].([]shared.UploaderQuotaReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaReport indicates an expected call of GetQuotaReport.
+func (mr *MockAPIMockRecorder) GetQuotaReport() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaReport", reflect.TypeOf((*MockAPI)(nil).GetQuotaReport))
+}
+
 // GetResultsURL mocks base method.
 func (m *MockAPI) GetResultsURL(filter shared.TestRunFilter) *url.URL {
 	m.ctrl.T.Helper()
@@ -188,6 +348,21 @@ func (mr *MockAPIMockRecorder) GetServiceHostname(service any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceHostname", reflect.TypeOf((*MockAPI)(nil).GetServiceHostname), service)
 }
 
+// GetUploadSession mocks base method.
+func (m *MockAPI) GetUploadSession(id string) (*shared.ResultsUploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadSession", id)
+	ret0, _ := ret[0].(*shared.ResultsUploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadSession indicates an expected call of GetUploadSession.
+func (mr *MockAPIMockRecorder) GetUploadSession(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadSession", reflect.TypeOf((*MockAPI)(nil).GetUploadSession), id)
+}
+
 // GetUploader mocks base method.
 func (m *MockAPI) GetUploader(uploader string) (shared.Uploader, error) {
 	m.ctrl.T.Helper()
@@ -203,6 +378,20 @@ func (mr *MockAPIMockRecorder) GetUploader(uploader any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploader", reflect.TypeOf((*MockAPI)(nil).GetUploader), uploader)
 }
 
+// GetUser mocks base method.
+func (m *MockAPI) GetUser(r *http.Request) *shared.User {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", r)
+	ret0, _ := ret[0].(*shared.User)
+	return ret0
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockAPIMockRecorder) GetUser(r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAPI)(nil).GetUser), r)
+}
+
 // GetVersion mocks base method.
 func (m *MockAPI) GetVersion() string {
 	m.ctrl.T.Helper()
@@ -231,6 +420,21 @@ func (mr *MockAPIMockRecorder) GetVersionedHostname() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersionedHostname", reflect.TypeOf((*MockAPI)(nil).GetVersionedHostname))
 }
 
+// GetWebhookSecret mocks base method.
+func (m *MockAPI) GetWebhookSecret(uploader string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookSecret", uploader)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookSecret indicates an expected call of GetWebhookSecret.
+func (mr *MockAPIMockRecorder) GetWebhookSecret(uploader any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookSecret", reflect.TypeOf((*MockAPI)(nil).GetWebhookSecret), uploader)
+}
+
 // IsAdmin mocks base method.
 func (m *MockAPI) IsAdmin(arg0 *http.Request) bool {
 	m.ctrl.T.Helper()
@@ -259,6 +463,180 @@ func (mr *MockAPIMockRecorder) IsFeatureEnabled(featureName any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsFeatureEnabled", reflect.TypeOf((*MockAPI)(nil).IsFeatureEnabled), featureName)
 }
 
+// ListAPITokens mocks base method.
+func (m *MockAPI) ListAPITokens(uploader string) ([]shared.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPITokens", uploader)
+	ret0, _ := ret[0].([]shared.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPITokens indicates an expected call of ListAPITokens.
+func (mr *MockAPIMockRecorder) ListAPITokens(uploader any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPITokens", reflect.TypeOf((*MockAPI)(nil).ListAPITokens), uploader)
+}
+
+// ListCINotifications mocks base method.
+func (m *MockAPI) ListCINotifications(outcome shared.CINotificationOutcome, limit int) ([]shared.CINotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCINotifications", outcome, limit)
+	ret0, _ := ret[0].([]shared.CINotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCINotifications indicates an expected call of ListCINotifications.
+func (mr *MockAPIMockRecorder) ListCINotifications(outcome, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCINotifications", reflect.TypeOf((*MockAPI)(nil).ListCINotifications), outcome, limit)
+}
+
+// ListCINotificationsOfBuild mocks base method.
+func (m *MockAPI) ListCINotificationsOfBuild(uploader string, buildID string) ([]shared.CINotification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCINotificationsOfBuild", uploader, buildID)
+	ret0, _ := ret[0].([]shared.CINotification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCINotificationsOfBuild indicates an expected call of ListCINotificationsOfBuild.
+func (mr *MockAPIMockRecorder) ListCINotificationsOfBuild(uploader, buildID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCINotificationsOfBuild", reflect.TypeOf((*MockAPI)(nil).ListCINotificationsOfBuild), uploader, buildID)
+}
+
+// ListPendingTestRuns mocks base method.
+func (m *MockAPI) ListPendingTestRuns() ([]shared.PendingTestRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingTestRuns")
+	ret0, _ := ret[0].([]shared.PendingTestRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingTestRuns indicates an expected call of ListPendingTestRuns.
+func (mr *MockAPIMockRecorder) ListPendingTestRuns() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingTestRuns", reflect.TypeOf((*MockAPI)(nil).ListPendingTestRuns))
+}
+
+// OpenGCS mocks base method.
+func (m *MockAPI) OpenGCS(gcsPath string) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenGCS", gcsPath)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenGCS indicates an expected call of OpenGCS.
+func (mr *MockAPIMockRecorder) OpenGCS(gcsPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenGCS", reflect.TypeOf((*MockAPI)(nil).OpenGCS), gcsPath)
+}
+
+// PutCINotification mocks base method.
+func (m *MockAPI) PutCINotification(notification *shared.CINotification) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutCINotification", notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutCINotification indicates an expected call of PutCINotification.
+func (mr *MockAPIMockRecorder) PutCINotification(notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutCINotification", reflect.TypeOf((*MockAPI)(nil).PutCINotification), notification)
+}
+
+// RecordCallbackDelivery mocks base method.
+func (m *MockAPI) RecordCallbackDelivery(id int64, delivery shared.CallbackDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCallbackDelivery", id, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCallbackDelivery indicates an expected call of RecordCallbackDelivery.
+func (mr *MockAPIMockRecorder) RecordCallbackDelivery(id, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCallbackDelivery", reflect.TypeOf((*MockAPI)(nil).RecordCallbackDelivery), id, delivery)
+}
+
+// ReleaseRunQuota mocks base method.
+func (m *MockAPI) ReleaseRunQuota(runID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRunQuota", runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRunQuota indicates an expected call of ReleaseRunQuota.
+func (mr *MockAPIMockRecorder) ReleaseRunQuota(runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRunQuota", reflect.TypeOf((*MockAPI)(nil).ReleaseRunQuota), runID)
+}
+
+// ReschedulePendingTestRun mocks base method.
+func (m *MockAPI) ReschedulePendingTestRun(run shared.PendingTestRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReschedulePendingTestRun", run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReschedulePendingTestRun indicates an expected call of ReschedulePendingTestRun.
+func (mr *MockAPIMockRecorder) ReschedulePendingTestRun(run any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReschedulePendingTestRun", reflect.TypeOf((*MockAPI)(nil).ReschedulePendingTestRun), run)
+}
+
+// ReserveRunQuota mocks base method.
+func (m *MockAPI) ReserveRunQuota(runID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveRunQuota", runID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveRunQuota indicates an expected call of ReserveRunQuota.
+func (mr *MockAPIMockRecorder) ReserveRunQuota(runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveRunQuota", reflect.TypeOf((*MockAPI)(nil).ReserveRunQuota), runID)
+}
+
+// ReserveUploadQuota mocks base method.
+func (m *MockAPI) ReserveUploadQuota(uploader string, bytes int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveUploadQuota", uploader, bytes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveUploadQuota indicates an expected call of ReserveUploadQuota.
+func (mr *MockAPIMockRecorder) ReserveUploadQuota(uploader, bytes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveUploadQuota", reflect.TypeOf((*MockAPI)(nil).ReserveUploadQuota), uploader, bytes)
+}
+
+// RevokeAPIToken mocks base method.
+func (m *MockAPI) RevokeAPIToken(id int64) (*shared.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIToken", id)
+	ret0, _ := ret[0].(*shared.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeAPIToken indicates an expected call of RevokeAPIToken.
+func (mr *MockAPIMockRecorder) RevokeAPIToken(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIToken", reflect.TypeOf((*MockAPI)(nil).RevokeAPIToken), id)
+}
+
 // ScheduleResultsTask mocks base method.
 func (m *MockAPI) ScheduleResultsTask(uploader string, results, screenshots, archives []string, extraParams map[string]string) (string, error) {
 	m.ctrl.T.Helper()
@@ -289,18 +667,47 @@ func (mr *MockAPIMockRecorder) ScheduleTask(queueName, taskName, target, params
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleTask", reflect.TypeOf((*MockAPI)(nil).ScheduleTask), queueName, taskName, target, params)
 }
 
+// TimeOutPendingTestRun mocks base method.
+func (m *MockAPI) TimeOutPendingTestRun(run shared.PendingTestRun, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TimeOutPendingTestRun", run, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TimeOutPendingTestRun indicates an expected call of TimeOutPendingTestRun.
+func (mr *MockAPIMockRecorder) TimeOutPendingTestRun(run, message any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeOutPendingTestRun", reflect.TypeOf((*MockAPI)(nil).TimeOutPendingTestRun), run, message)
+}
+
 // UpdatePendingTestRun mocks base method.
-func (m *MockAPI) UpdatePendingTestRun(pendingRun shared.PendingTestRun) error {
+func (m *MockAPI) UpdatePendingTestRun(actor string, pendingRun shared.PendingTestRun) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePendingTestRun", pendingRun)
+	ret := m.ctrl.Call(m, "UpdatePendingTestRun", actor, pendingRun)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePendingTestRun indicates an expected call of UpdatePendingTestRun.
-func (mr *MockAPIMockRecorder) UpdatePendingTestRun(pendingRun any) *gomock.Call {
+func (mr *MockAPIMockRecorder) UpdatePendingTestRun(actor, pendingRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingTestRun", reflect.TypeOf((*MockAPI)(nil).UpdatePendingTestRun), actor, pendingRun)
+}
+
+// UpdateUploadSession mocks base method.
+func (m *MockAPI) UpdateUploadSession(id string, mutator func(*shared.ResultsUploadSession) error) (*shared.ResultsUploadSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUploadSession", id, mutator)
+	ret0, _ := ret[0].(*shared.ResultsUploadSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUploadSession indicates an expected call of UpdateUploadSession.
+func (mr *MockAPIMockRecorder) UpdateUploadSession(id, mutator any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingTestRun", reflect.TypeOf((*MockAPI)(nil).UpdatePendingTestRun), pendingRun)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUploadSession", reflect.TypeOf((*MockAPI)(nil).UpdateUploadSession), id, mutator)
 }
 
 // UploadToGCS mocks base method.
@@ -0,0 +1,309 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// uploaderQuotaKind is the Datastore kind of UploaderQuota entities, which are
+// keyed by the uploader's name.
+const uploaderQuotaKind = "UploaderQuota"
+
+// uploaderUsageKind is the Datastore kind of UploaderUsage entities, which are
+// keyed by the uploader's name and date; see usageKeyName.
+const uploaderUsageKind = "UploaderUsage"
+
+// DefaultQuotaName is the name of the UploaderQuota which applies to uploaders
+// without a quota of their own.
+const DefaultQuotaName = "default"
+
+// pendingRunsRetryAfter is how long an uploader is asked to wait when it has
+// too many pending runs.
+const pendingRunsRetryAfter = 10 * time.Minute
+
+// QuotaExceededError is returned when an uploader has exceeded its quota.
+type QuotaExceededError struct {
+	Uploader string
+	Reason   string
+	// RetryAfter is how long until the uploader may be within its quota again.
+	RetryAfter time.Duration
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("uploader %s has exceeded its quota: %s", e.Uploader, e.Reason)
+}
+
+// writeQuotaError writes a 429 response with a Retry-After header if err is a
+// QuotaExceededError, or a 500 otherwise.
+func writeQuotaError(a API, w http.ResponseWriter, err error) {
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		shared.GetLogger(a.Context()).Errorf("Failed to check quota: %s", err.Error())
+		http.Error(w, "Failed to check quota", http.StatusInternalServerError)
+
+		return
+	}
+	shared.GetLogger(a.Context()).Warningf("%s", exceeded.Error())
+	seconds := int64(math.Ceil(exceeded.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	http.Error(w, exceeded.Error(), http.StatusTooManyRequests)
+}
+
+func usageKeyName(uploader, date string) string {
+	return uploader + "/" + date
+}
+
+// untilTomorrow returns the duration until the next (UTC) day, when daily
+// quotas are reset.
+func untilTomorrow(now time.Time) time.Duration {
+	y, m, d := now.UTC().Date()
+
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
+
+// getQuota loads the quota of the given uploader, falling back to the default
+// quota. Uploaders are unlimited if neither exists.
+func (a apiImpl) getQuota(uploader string) (shared.UploaderQuota, error) {
+	var quota shared.UploaderQuota
+	for _, name := range []string{uploader, DefaultQuotaName} {
+		err := a.store.Get(a.store.NewNameKey(uploaderQuotaKind, name), &quota)
+		if err == nil {
+			quota.Uploader = uploader
+
+			return quota, nil
+		} else if !errors.Is(err, shared.ErrNoSuchEntity) {
+			return quota, err
+		}
+	}
+	quota.Uploader = uploader
+
+	return quota, nil
+}
+
+// countPendingRuns counts the uploader's runs which have been received but
+// not yet processed.
+func (a apiImpl) countPendingRuns(uploader string) (int, error) {
+	q := a.store.NewQuery("PendingTestRun").
+		Filter("Uploader =", uploader).
+		Filter("Stage >=", int(shared.StageWptFyiReceived)).
+		Filter("Stage <", int(shared.StageValid)).
+		KeysOnly()
+	keys, err := a.store.GetAll(q, nil)
+
+	return len(keys), err
+}
+
+// ReserveUploadQuota checks that the uploader can upload the given number of
+// bytes, and counts them towards its daily quota. A QuotaExceededError is
+// returned if the uploader has too many pending runs, would exceed its daily
+// runs once its pending runs are created, or would exceed its daily bytes.
+// The size of URL payloads isn't known, so they're reserved with 0 bytes, and
+// only count towards the runs and pending runs quotas.
+func (a apiImpl) ReserveUploadQuota(uploader string, bytes int64) error {
+	quota, err := a.getQuota(uploader)
+	if err != nil {
+		return err
+	}
+	// Pending runs count towards the daily runs too, so that a burst of
+	// uploads can't exceed it before their runs are created.
+	var pending int
+	if quota.MaxPendingRuns > 0 || quota.RunsPerDay > 0 {
+		if pending, err = a.countPendingRuns(uploader); err != nil {
+			return err
+		}
+	}
+	if quota.MaxPendingRuns > 0 && pending >= quota.MaxPendingRuns {
+		return &QuotaExceededError{
+			Uploader:   uploader,
+			Reason:     fmt.Sprintf("%d runs are pending (max %d)", pending, quota.MaxPendingRuns),
+			RetryAfter: pendingRunsRetryAfter,
+		}
+	}
+
+	return a.updateUsage(uploader, func(usage *shared.UploaderUsage, retryAfter time.Duration) error {
+		if quota.RunsPerDay > 0 && usage.Runs+pending >= quota.RunsPerDay {
+			return &QuotaExceededError{
+				Uploader: uploader,
+				Reason: fmt.Sprintf(
+					"%d runs per day (%d created, %d pending)", quota.RunsPerDay, usage.Runs, pending),
+				RetryAfter: retryAfter,
+			}
+		}
+		if quota.BytesPerDay > 0 && usage.Bytes+bytes > quota.BytesPerDay {
+			return &QuotaExceededError{
+				Uploader:   uploader,
+				Reason:     fmt.Sprintf("%d bytes per day", quota.BytesPerDay),
+				RetryAfter: retryAfter,
+			}
+		}
+		usage.Bytes += bytes
+
+		return nil
+	})
+}
+
+// ReserveRunQuota counts the creation of the run with the given ID towards the
+// daily quota of the uploader of its PendingTestRun, returning a
+// QuotaExceededError if the uploader has already created its daily runs. A
+// run is only counted once, however many times its creation is retried. Runs
+// without a known uploader are not limited.
+func (a apiImpl) ReserveRunQuota(runID int64) error {
+	uploader, err := a.getRunUploader(runID)
+	if err != nil || uploader == "" {
+		return err
+	}
+	quota, err := a.getQuota(uploader)
+	if err != nil {
+		return err
+	}
+
+	return a.updateUsage(uploader, func(usage *shared.UploaderUsage, retryAfter time.Duration) error {
+		if slices.Contains(usage.RunIDs, runID) {
+			return nil
+		}
+		if quota.RunsPerDay > 0 && usage.Runs >= quota.RunsPerDay {
+			return &QuotaExceededError{
+				Uploader:   uploader,
+				Reason:     fmt.Sprintf("%d runs per day", quota.RunsPerDay),
+				RetryAfter: retryAfter,
+			}
+		}
+		usage.Runs++
+		usage.RunIDs = append(usage.RunIDs, runID)
+
+		return nil
+	})
+}
+
+// ReleaseRunQuota undoes ReserveRunQuota for a run which failed to be created,
+// if it was counted today.
+func (a apiImpl) ReleaseRunQuota(runID int64) error {
+	uploader, err := a.getRunUploader(runID)
+	if err != nil || uploader == "" {
+		return err
+	}
+
+	return a.updateUsage(uploader, func(usage *shared.UploaderUsage, _ time.Duration) error {
+		if i := slices.Index(usage.RunIDs, runID); i >= 0 {
+			usage.RunIDs = slices.Delete(usage.RunIDs, i, i+1)
+			usage.Runs--
+		}
+
+		return nil
+	})
+}
+
+// getRunUploader returns the uploader of the PendingTestRun with the given ID,
+// or "" if it's unknown.
+func (a apiImpl) getRunUploader(runID int64) (string, error) {
+	if runID == 0 {
+		return "", nil
+	}
+	var pending shared.PendingTestRun
+	err := a.store.Get(a.store.NewIDKey("PendingTestRun", runID), &pending)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		return "", nil
+	}
+
+	return pending.Uploader, err
+}
+
+// updateUsage transactionally updates the uploader's usage of today. The
+// mutator is given the time until the usage is reset.
+func (a apiImpl) updateUsage(uploader string, mutator func(*shared.UploaderUsage, time.Duration) error) error {
+	now := time.Now().UTC()
+	date := now.Format("2006-01-02")
+	key := a.store.NewNameKey(uploaderUsageKind, usageKeyName(uploader, date))
+	var usage shared.UploaderUsage
+
+	return a.store.Update(key, &usage, func(obj interface{}) error {
+		u := obj.(*shared.UploaderUsage)
+		u.Uploader = uploader
+		u.Date = date
+
+		return mutator(u, untilTomorrow(now))
+	})
+}
+
+// GetQuotaReport reports the quota and today's usage of every uploader which
+// either has a quota of its own or has uploaded today, as well as the default
+// quota.
+func (a apiImpl) GetQuotaReport() ([]shared.UploaderQuotaReport, error) {
+	var quotas []shared.UploaderQuota
+	keys, err := a.store.GetAll(a.store.NewQuery(uploaderQuotaKind), &quotas)
+	if err != nil {
+		return nil, err
+	}
+	var usages []shared.UploaderUsage
+	date := time.Now().UTC().Format("2006-01-02")
+	if _, err := a.store.GetAll(a.store.NewQuery(uploaderUsageKind).Filter("Date =", date), &usages); err != nil {
+		return nil, err
+	}
+
+	reports := make(map[string]*shared.UploaderQuotaReport)
+	for _, usage := range usages {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		reports[usage.Uploader] = &shared.UploaderQuotaReport{Uploader: usage.Uploader, Usage: usage}
+	}
+	for i, key := range keys {
+		uploader := key.StringID()
+		if _, ok := reports[uploader]; !ok {
+			// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+			reports[uploader] = &shared.UploaderQuotaReport{
+				Uploader: uploader,
+				Usage:    shared.UploaderUsage{Uploader: uploader, Date: date},
+			}
+		}
+		quotas[i].Uploader = uploader
+		reports[uploader].Quota = quotas[i]
+	}
+
+	result := make([]shared.UploaderQuotaReport, 0, len(reports))
+	for uploader, report := range reports {
+		if report.Quota.Uploader == "" {
+			// Uploaders without a quota of their own have the default quota.
+			if report.Quota, err = a.getQuota(uploader); err != nil {
+				return nil, err
+			}
+		}
+		if uploader != DefaultQuotaName {
+			if report.PendingRuns, err = a.countPendingRuns(uploader); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Uploader < result[j].Uploader })
+
+	return result, nil
+}
+
+// HandleQuotaReport responds with the quota report of all uploaders. It is
+// only available to admins.
+func HandleQuotaReport(a API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	report, err := a.GetQuotaReport()
+	if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to load quota report: %s", err.Error())
+		http.Error(w, "Failed to load quota report", http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,152 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/checks/mock_checks"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestUntilTomorrow(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 22, 30, 0, 0, time.UTC)
+	assert.Equal(t, 90*time.Minute, untilTomorrow(now))
+}
+
+func TestWriteQuotaError(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+
+	resp := httptest.NewRecorder()
+	writeQuotaError(mockAE, resp, &QuotaExceededError{
+		Uploader:   "blade-runner",
+		Reason:     "10 runs per day",
+		RetryAfter: 90*time.Second + time.Millisecond,
+	})
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "91", resp.Header().Get("Retry-After"))
+	assert.Contains(t, resp.Body.String(), "uploader blade-runner has exceeded its quota: 10 runs per day")
+
+	resp = httptest.NewRecorder()
+	writeQuotaError(mockAE, resp, errors.New("datastore error"))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Empty(t, resp.Header().Get("Retry-After"))
+}
+
+func TestHandleResultsUpload_quotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{"result_url": {"https://wpt.fyi/test.json.gz"}}
+	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	// Neither UploadToGCS nor ScheduleResultsTask are expected.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(&QuotaExceededError{
+			Uploader:   "blade-runner",
+			Reason:     "5 runs are pending (max 5)",
+			RetryAfter: pendingRunsRetryAfter,
+		}),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "600", resp.Header().Get("Retry-After"))
+}
+
+func TestHandleResultsCreate_quotaExceeded(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	body := `{"id": 123, "browser_name": "firefox", "full_revision_hash": "0123456789012345678901234567890123456789"}`
+	req := httptest.NewRequest("POST", "/api/results/create", strings.NewReader(body))
+	req.SetBasicAuth("_processor", "secret-token")
+	resp := httptest.NewRecorder()
+
+	// Neither AddTestRun nor ScheduleResultsProcessing are expected.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockS := mock_checks.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
+		mockAE.EXPECT().ReserveRunQuota(int64(123)).Return(&QuotaExceededError{
+			Uploader:   "blade-runner",
+			Reason:     "10 runs per day",
+			RetryAfter: time.Hour,
+		}),
+	)
+
+	HandleResultsCreate(mockAE, mockS, resp, req)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "3600", resp.Header().Get("Retry-After"))
+}
+
+func TestHandleQuotaReport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", "/api/results/quotas", nil)
+	resp := httptest.NewRecorder()
+
+	report := []shared.UploaderQuotaReport{
+		{
+			Uploader:    "blade-runner",
+			Quota:       shared.UploaderQuota{Uploader: "blade-runner", RunsPerDay: 10},
+			Usage:       shared.UploaderUsage{Uploader: "blade-runner", Date: "2026-10-18", Runs: 3, Bytes: 1024},
+			PendingRuns: 1,
+		},
+	}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(true),
+		mockAE.EXPECT().GetQuotaReport().Return(report, nil),
+	)
+
+	HandleQuotaReport(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var got []shared.UploaderQuotaReport
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	assert.Equal(t, report, got)
+}
+
+func TestHandleQuotaReport_notAdmin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", "/api/results/quotas", nil)
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockAE.EXPECT().IsAdmin(req).Return(false)
+
+	HandleQuotaReport(mockAE, resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
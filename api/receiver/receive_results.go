@@ -5,10 +5,13 @@
 package receiver
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/web-platform-tests/wpt.fyi/shared"
@@ -23,7 +26,18 @@ const ResultsQueue = "results-arrival"
 // ResultsTarget is the target URL for results processing tasks.
 const ResultsTarget = "/api/results/process"
 
+// uploadAttempts is the number of times an upload to GCS is attempted.
+const uploadAttempts = 3
+
+// uploadRetryDelay is the delay before retrying a failed upload to GCS, which
+// doubles after each attempt.
+var uploadRetryDelay = time.Second
+
 // HandleResultsUpload handles the POST requests for uploading results.
+//
+// Uploaded result files are validated before they are queued, and rejected
+// with a JSON list of errors if they are invalid. With dry_run=true, they are
+// only validated.
 func HandleResultsUpload(a API, w http.ResponseWriter, r *http.Request) {
 	// Most form methods (e.g. FormValue) will call ParseMultipartForm and
 	// ParseForm if necessary; forms with either enctype can be parsed.
@@ -32,49 +46,37 @@ func HandleResultsUpload(a API, w http.ResponseWriter, r *http.Request) {
 	// The default maximum form size is 32MB, which is also the max request
 	// size on AppEngine.
 
-	var uploader string
-	if a.IsAdmin(r) {
-		uploader = r.FormValue("user")
-		if uploader == "" {
-			http.Error(w, "Please specify uploader", http.StatusBadRequest)
-
-			return
-		}
-	} else {
-		uploader = AuthenticateUploader(a, r)
-		if uploader == "" {
-			http.Error(w, "Authentication error", http.StatusUnauthorized)
-
-			return
-		}
+	uploader, token := getUploader(a, w, r)
+	if uploader == "" {
+		return
 	}
-
-	// Non-existent keys will have empty values, which will later be
-	// filtered out by scheduleResultsTask.
-	extraParams := map[string]string{
-		"labels":       r.FormValue("labels"),
-		"callback_url": r.FormValue("callback_url"),
-		// The following fields will be deprecated when all runners embed metadata in the report.
-		"revision":        r.FormValue("revision"),
-		"browser_name":    r.FormValue("browser_name"),
-		"browser_version": r.FormValue("browser_version"),
-		"os_name":         r.FormValue("os_name"),
-		"os_version":      r.FormValue("os_version"),
+	extraParams := getExtraParams(r)
+	if !checkNotifyURL(w, extraParams) || !checkDuplicatePolicy(w, extraParams) ||
+		!checkCallbackSecret(a, w, uploader, extraParams) {
+		return
 	}
 
 	log := shared.GetLogger(a.Context())
+	dryRun, err := shared.ParseBooleanParam(r.URL.Query(), "dry_run")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
 	var results, screenshots, archives []string
+	var files, sFiles []*multipart.FileHeader
+	var validation ReportValidation
 	// nolint:nestif // TODO: Fix nestif lint error
 	if f := r.MultipartForm; f != nil && f.File != nil && len(f.File["result_file"]) > 0 {
 		// result_file[] payload
-		files := f.File["result_file"]
-		sFiles := f.File["screenshot_file"]
-		log.Debugf("Found %d result files, %d screenshot files", len(results), len(screenshots))
-		var err error
-		results, screenshots, err = saveToGCS(a, uploader, files, sFiles)
-		if err != nil {
-			log.Errorf("Failed to save files to GCS: %s", err.Error())
-			http.Error(w, "Failed to save files to GCS", http.StatusInternalServerError)
+		files = f.File["result_file"]
+		sFiles = f.File["screenshot_file"]
+		log.Debugf("Found %d result files, %d screenshot files", len(files), len(sFiles))
+		validation = validateResultFiles(files)
+		if !validation.Valid() {
+			log.Warningf("Rejected %d invalid result files from %s", len(files), uploader)
+			writeJSON(w, http.StatusBadRequest, validation)
 
 			return
 		}
@@ -98,6 +100,44 @@ func HandleResultsUpload(a API, w http.ResponseWriter, r *http.Request) {
 
 		return
 	}
+	// browser_name overrides run_info.product in the results processor.
+	products := validation.Products
+	if browserName := extraParams["browser_name"]; browserName != "" {
+		products = []string{browserName}
+	}
+	if !checkTokenProducts(w, token, products...) {
+		return
+	}
+	if dryRun != nil && *dryRun {
+		if len(files) == 0 {
+			http.Error(w, "dry_run is only supported for result_file uploads", http.StatusBadRequest)
+
+			return
+		}
+		writeJSON(w, http.StatusOK, validation)
+
+		return
+	}
+
+	// Only uploaded files have a size; see ReserveUploadQuota.
+	var size int64
+	for _, file := range append(files, sFiles...) {
+		size += file.Size
+	}
+	if err := a.ReserveUploadQuota(uploader, size); err != nil {
+		writeQuotaError(a, w, err)
+
+		return
+	}
+	if len(files) > 0 {
+		results, screenshots, err = saveToGCS(a, uploader, files, sFiles)
+		if err != nil {
+			log.Errorf("Failed to save files to GCS: %s", err.Error())
+			http.Error(w, "Failed to save files to GCS", http.StatusInternalServerError)
+
+			return
+		}
+	}
 
 	t, err := a.ScheduleResultsTask(uploader, results, screenshots, archives, extraParams)
 	if err != nil {
@@ -110,6 +150,84 @@ func HandleResultsUpload(a API, w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Task %s added to queue\n", t)
 }
 
+// getUploader returns the uploader of the request, which is either given by
+// the "user" param of an admin, or authenticated by HTTP basic auth or an API
+// token (which is also returned). It returns "" (after writing an error
+// response) if there is no valid uploader.
+func getUploader(a API, w http.ResponseWriter, r *http.Request) (string, *shared.APIToken) {
+	if a.IsAdmin(r) {
+		uploader := r.FormValue("user")
+		if uploader == "" {
+			http.Error(w, "Please specify uploader", http.StatusBadRequest)
+		}
+
+		return uploader, nil
+	}
+	uploader, token := AuthenticateUploaderToken(a, r, shared.ScopeUploadResults)
+	if uploader == "" {
+		http.Error(w, "Authentication error", http.StatusUnauthorized)
+	}
+
+	return uploader, token
+}
+
+// getExtraParams returns the optional params of an upload, which are passed
+// on to the results processor.
+func getExtraParams(r *http.Request) map[string]string {
+	// Non-existent keys will have empty values, which will later be
+	// filtered out by scheduleResultsTask.
+	return map[string]string{
+		"labels":       r.FormValue("labels"),
+		"callback_url": r.FormValue("callback_url"),
+		"notify_url":   r.FormValue("notify_url"),
+		// duplicate_policy is passed on by the processor to /api/results/create.
+		"duplicate_policy": r.FormValue("duplicate_policy"),
+		// The following fields will be deprecated when all runners embed metadata in the report.
+		"revision":        r.FormValue("revision"),
+		"browser_name":    r.FormValue("browser_name"),
+		"browser_version": r.FormValue("browser_version"),
+		"os_name":         r.FormValue("os_name"),
+		"os_version":      r.FormValue("os_version"),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	marshalled, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(marshalled)
+}
+
+// isGzip returns whether the given prefix of a file starts with the gzip magic
+// number.
+func isGzip(prefix []byte) bool {
+	return len(prefix) >= 2 && prefix[0] == 0x1f && prefix[1] == 0x8b
+}
+
+// uploadWithRetry uploads f to GCS, retrying with exponential backoff if the
+// upload fails.
+func uploadWithRetry(a API, gcsPath string, f io.ReadSeeker, gzipped bool) error {
+	delay := uploadRetryDelay
+	for attempt := 1; ; attempt++ {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		err := a.UploadToGCS(gcsPath, f, gzipped)
+		if err == nil || attempt >= uploadAttempts {
+			return err
+		}
+		shared.GetLogger(a.Context()).Warningf(
+			"Failed to upload %s (attempt %d of %d): %s", gcsPath, attempt, uploadAttempts, err.Error())
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
 func saveToGCS(a API, uploader string, resultFiles, screenshotFiles []*multipart.FileHeader) (
 	resultGCS, screenshotGCS []string, err error) {
 	id := uuid.New()
@@ -132,9 +250,9 @@ func saveToGCS(a API, uploader string, resultFiles, screenshotFiles []*multipart
 			return
 		}
 		defer f.Close()
-		// nolint:godox // TODO(Hexcles): Detect whether the file is gzipped.
-		// nolint:godox // TODO(Hexcles): Retry after failures.
-		if err := a.UploadToGCS(gcsPath, f, true); err != nil {
+		prefix := make([]byte, 2)
+		n, _ := f.ReadAt(prefix, 0)
+		if err := uploadWithRetry(a, gcsPath, f, isGzip(prefix[:n])); err != nil {
 			errors <- err
 		}
 	}
@@ -8,7 +8,10 @@ package receiver
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +19,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -51,13 +55,15 @@ func matchRegex(r string) *regexMatcher {
 
 // An empty (default) extraParams
 var emptyParams = map[string]string{
-	"browser_name":    "",
-	"labels":          "",
-	"revision":        "",
-	"browser_version": "",
-	"os_name":         "",
-	"os_version":      "",
-	"callback_url":    "",
+	"browser_name":     "",
+	"labels":           "",
+	"revision":         "",
+	"browser_version":  "",
+	"os_name":          "",
+	"os_version":       "",
+	"callback_url":     "",
+	"notify_url":       "",
+	"duplicate_policy": "",
 }
 
 func TestHandleResultsUpload_not_admin(t *testing.T) {
@@ -96,11 +102,12 @@ func TestHandleResultsUpload_extra_params(t *testing.T) {
 
 	payload := url.Values{
 		// Uploader cannot specify ID (i.e. this field should be discarded).
-		"id":            {"12345"},
-		"result_url":    {"http://wpt.fyi/test.json.gz"},
-		"browser_name":  {"firefox"},
-		"labels":        {"stable"},
-		"invalid_param": {"should be ignored"},
+		"id":               {"12345"},
+		"result_url":       {"http://wpt.fyi/test.json.gz"},
+		"browser_name":     {"firefox"},
+		"labels":           {"stable"},
+		"duplicate_policy": {"replace"},
+		"invalid_param":    {"should be ignored"},
 	}
 	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -108,19 +115,22 @@ func TestHandleResultsUpload_extra_params(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	extraParams := map[string]string{
-		"browser_name":    "firefox",
-		"labels":          "stable",
-		"revision":        "",
-		"browser_version": "",
-		"os_name":         "",
-		"os_version":      "",
-		"callback_url":    "",
+		"browser_name":     "firefox",
+		"labels":           "stable",
+		"revision":         "",
+		"browser_version":  "",
+		"os_name":          "",
+		"os_version":       "",
+		"callback_url":     "",
+		"notify_url":       "",
+		"duplicate_policy": "replace",
 	}
 	mockAE := mock_receiver.NewMockAPI(mockCtrl)
 	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
 	gomock.InOrder(
 		mockAE.EXPECT().IsAdmin(req).Return(false),
 		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
 		mockAE.EXPECT().ScheduleResultsTask(
 			"blade-runner", []string{"http://wpt.fyi/test.json.gz"}, nil, nil, extraParams).Return("task", nil),
 	)
@@ -129,6 +139,30 @@ func TestHandleResultsUpload_extra_params(t *testing.T) {
 	assert.Equal(t, resp.Code, http.StatusOK)
 }
 
+func TestHandleResultsUpload_invalidDuplicatePolicy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{
+		"result_url":       {"https://wpt.fyi/test.json.gz"},
+		"duplicate_policy": {"ignore"},
+	}
+	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func TestHandleResultsUpload_azure(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -136,13 +170,15 @@ func TestHandleResultsUpload_azure(t *testing.T) {
 	azureURL := "https://dev.azure.com/web-platform-tests/b14026b4-9423-4454-858f-bf76cf6d1faa/_apis/build/builds/4230/artifacts?artifactName=results&api-version=5.0&%24format=zip"
 	payload := url.Values{"result_url": []string{azureURL}}
 	extraParams := map[string]string{
-		"browser_name":    "",
-		"labels":          "",
-		"revision":        "",
-		"browser_version": "",
-		"os_name":         "",
-		"os_version":      "",
-		"callback_url":    "",
+		"browser_name":     "",
+		"labels":           "",
+		"revision":         "",
+		"browser_version":  "",
+		"os_name":          "",
+		"os_version":       "",
+		"callback_url":     "",
+		"notify_url":       "",
+		"duplicate_policy": "",
 	}
 	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -154,6 +190,7 @@ func TestHandleResultsUpload_azure(t *testing.T) {
 	gomock.InOrder(
 		mockAE.EXPECT().IsAdmin(req).Return(false),
 		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
 		mockAE.EXPECT().ScheduleResultsTask("blade-runner", nil, nil, []string{azureURL}, extraParams).Return("task", nil),
 	)
 
@@ -168,13 +205,15 @@ func TestHandleResultsUpload_archive(t *testing.T) {
 	archiveURL := "https://example.com/results.zip"
 	payload := url.Values{"archive_url": []string{archiveURL}}
 	extraParams := map[string]string{
-		"browser_name":    "",
-		"labels":          "",
-		"revision":        "",
-		"browser_version": "",
-		"os_name":         "",
-		"os_version":      "",
-		"callback_url":    "",
+		"browser_name":     "",
+		"labels":           "",
+		"revision":         "",
+		"browser_version":  "",
+		"os_name":          "",
+		"os_version":       "",
+		"callback_url":     "",
+		"notify_url":       "",
+		"duplicate_policy": "",
 	}
 	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -186,6 +225,7 @@ func TestHandleResultsUpload_archive(t *testing.T) {
 	gomock.InOrder(
 		mockAE.EXPECT().IsAdmin(req).Return(false),
 		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
 		mockAE.EXPECT().ScheduleResultsTask("blade-runner", nil, nil, []string{archiveURL}, extraParams).Return("task", nil),
 	)
 
@@ -200,13 +240,15 @@ func TestHandleResultsUpload_archive_as_results(t *testing.T) {
 	archiveURL := "https://example.com/results.zip"
 	payload := url.Values{"result_url": []string{archiveURL}}
 	extraParams := map[string]string{
-		"browser_name":    "",
-		"labels":          "",
-		"revision":        "",
-		"browser_version": "",
-		"os_name":         "",
-		"os_version":      "",
-		"callback_url":    "",
+		"browser_name":     "",
+		"labels":           "",
+		"revision":         "",
+		"browser_version":  "",
+		"os_name":          "",
+		"os_version":       "",
+		"callback_url":     "",
+		"notify_url":       "",
+		"duplicate_policy": "",
 	}
 	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
@@ -218,6 +260,7 @@ func TestHandleResultsUpload_archive_as_results(t *testing.T) {
 	gomock.InOrder(
 		mockAE.EXPECT().IsAdmin(req).Return(false),
 		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
 		mockAE.EXPECT().ScheduleResultsTask("blade-runner", []string{archiveURL}, nil, nil, extraParams).Return("task", nil),
 	)
 
@@ -248,6 +291,7 @@ func TestHandleResultsUpload_url(t *testing.T) {
 			gomock.InOrder(
 				mockAE.EXPECT().IsAdmin(req).Return(false),
 				mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+				mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
 				mockAE.EXPECT().ScheduleResultsTask("blade-runner", urls, screenshot, nil, emptyParams).Return("task", nil),
 			)
 
@@ -268,7 +312,7 @@ func TestHandleResultsUpload_file(t *testing.T) {
 			buffer := new(bytes.Buffer)
 			writer := multipart.NewWriter(buffer)
 			for _, filename := range filenames {
-				writer.CreateFormFile("result_file", filename)
+				writeResultFile(t, writer, filename, validReport("/"+filename+".html"))
 			}
 			writer.Close()
 			req := httptest.NewRequest("POST", "/api/results/upload", buffer)
@@ -281,6 +325,7 @@ func TestHandleResultsUpload_file(t *testing.T) {
 			gomock.InOrder(
 				mockAE.EXPECT().IsAdmin(req).Return(false),
 				mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+				mockAE.EXPECT().ReserveUploadQuota("blade-runner", gomock.Any()).Return(nil),
 				mockAE.EXPECT().ScheduleResultsTask("blade-runner", gomock.Any(), gomock.Any(), gomock.Any(), emptyParams).Return("task", nil),
 			)
 			for range filenames {
@@ -299,26 +344,165 @@ func TestHandleResultsUpload_fail_uploading(t *testing.T) {
 
 	buffer := new(bytes.Buffer)
 	writer := multipart.NewWriter(buffer)
-	writer.CreateFormFile("result_file", "test.json.gz")
+	writeResultFile(t, writer, "test.json.gz", validReport("/test.html"))
 	writer.Close()
 	req := httptest.NewRequest("POST", "/api/results/upload", buffer)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.SetBasicAuth("blade-runner", "123")
 	resp := httptest.NewRecorder()
 
+	defer func(delay time.Duration) { uploadRetryDelay = delay }(uploadRetryDelay)
+	uploadRetryDelay = 0
+
 	errGCS := fmt.Errorf("failed to upload to GCS")
 	mockAE := mock_receiver.NewMockAPI(mockCtrl)
 	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
 	gomock.InOrder(
 		mockAE.EXPECT().IsAdmin(req).Return(false),
 		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
-		mockAE.EXPECT().UploadToGCS(matchRegex(`^gs://wptd-results-buffer/blade-runner/.*\.json$`), gomock.Any(), true).Return(errGCS),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", gomock.Any()).Return(nil),
+		mockAE.EXPECT().UploadToGCS(matchRegex(`^gs://wptd-results-buffer/blade-runner/.*\.json$`), gomock.Any(), true).
+			Return(errGCS).Times(uploadAttempts),
 	)
 
 	HandleResultsUpload(mockAE, resp, req)
 	assert.Equal(t, resp.Code, http.StatusInternalServerError)
 }
 
+func TestHandleResultsUpload_retry_uploading(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	buffer := new(bytes.Buffer)
+	writer := multipart.NewWriter(buffer)
+	part, err := writer.CreateFormFile("result_file", "test.json")
+	assert.Nil(t, err)
+	_, err = part.Write([]byte(validReport("/test.html")))
+	assert.Nil(t, err)
+	writer.Close()
+	req := httptest.NewRequest("POST", "/api/results/upload", buffer)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	defer func(delay time.Duration) { uploadRetryDelay = delay }(uploadRetryDelay)
+	uploadRetryDelay = 0
+
+	// The file isn't gzipped, and is uploaded in full on the retry.
+	var uploaded []byte
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", gomock.Any()).Return(nil),
+		mockAE.EXPECT().UploadToGCS(gomock.Any(), gomock.Any(), false).DoAndReturn(
+			func(_ string, f io.Reader, _ bool) error {
+				_, err := io.ReadAll(io.LimitReader(f, 5))
+				assert.Nil(t, err)
+
+				return fmt.Errorf("transient error")
+			}),
+		mockAE.EXPECT().UploadToGCS(gomock.Any(), gomock.Any(), false).DoAndReturn(
+			func(_ string, f io.Reader, _ bool) error {
+				uploaded, err = io.ReadAll(f)
+
+				return err
+			}),
+		mockAE.EXPECT().ScheduleResultsTask("blade-runner", gomock.Any(), gomock.Any(), gomock.Any(), emptyParams).Return("task", nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, validReport("/test.html"), string(uploaded))
+}
+
+func TestHandleResultsUpload_invalid_file(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	buffer := new(bytes.Buffer)
+	writer := multipart.NewWriter(buffer)
+	writeResultFile(t, writer, "a.json.gz", validReport("/a.html"))
+	writeResultFile(t, writer, "b.json.gz", `{"results": [
+{"test": "/a.html", "status": "OK", "subtests": []},
+{"test": "/b.html", "status": "BOGUS", "subtests": []}
+]}`)
+	writer.Close()
+	req := httptest.NewRequest("POST", "/api/results/upload", buffer)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	var validation ReportValidation
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &validation))
+	assert.Equal(t, 2, validation.Files)
+	assert.Equal(t, []ReportError{
+		{File: "b.json.gz", Line: 2, Test: "/a.html", Message: "duplicate test, also in a.json.gz"},
+		{File: "b.json.gz", Line: 3, Test: "/b.html", Message: `unknown status "BOGUS"`},
+		{File: "b.json.gz", Line: 4, Message: `missing required field "run_info"`},
+	}, validation.Errors)
+}
+
+func TestHandleResultsUpload_dry_run(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	buffer := new(bytes.Buffer)
+	writer := multipart.NewWriter(buffer)
+	writeResultFile(t, writer, "test.json.gz", validReport("/a.html", "/b.html"))
+	writer.Close()
+	req := httptest.NewRequest("POST", "/api/results/upload?dry_run=true", buffer)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	// Neither UploadToGCS nor ScheduleResultsTask are expected.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var validation ReportValidation
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &validation))
+	assert.Equal(t, ReportValidation{Files: 1, Tests: 2, Products: []string{"chrome"}}, validation)
+}
+
+func TestHandleResultsUpload_dry_run_urls(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{"result_url": {"https://wpt.fyi/test.json.gz"}}
+	req := httptest.NewRequest("POST", "/api/results/upload?dry_run=true", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func TestHandleResultsUpload_empty_payload(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -338,3 +522,24 @@ func TestHandleResultsUpload_empty_payload(t *testing.T) {
 	HandleResultsUpload(mockAE, resp, req)
 	assert.Equal(t, resp.Code, http.StatusBadRequest)
 }
+
+// validReport returns a wptreport with an OK result for each of the tests.
+func validReport(tests ...string) string {
+	var results []string
+	for _, test := range tests {
+		results = append(results, fmt.Sprintf(
+			`{"test": %q, "status": "OK", "subtests": [{"name": "x", "status": "PASS"}]}`, test))
+	}
+
+	return fmt.Sprintf(`{"results": [%s], "run_info": {"product": "chrome"}}`, strings.Join(results, ","))
+}
+
+// writeResultFile adds a gzipped result_file to the multipart form.
+func writeResultFile(t *testing.T, writer *multipart.Writer, filename, report string) {
+	part, err := writer.CreateFormFile("result_file", filename)
+	assert.Nil(t, err)
+	gz := gzip.NewWriter(part)
+	_, err = gz.Write([]byte(report))
+	assert.Nil(t, err)
+	assert.Nil(t, gz.Close())
+}
@@ -12,10 +12,37 @@ func RegisterRoutes() {
 	// This API is authenticated. Runners have credentials.
 	shared.AddRoute("/api/results/upload", "api-results-upload", apiResultsUploadHandler)
 
+	// PROTECTED API endpoints for resumable uploads of large results files.
+	// These APIs are authenticated in the same way as /api/results/upload.
+	shared.AddRoute("/api/results/upload/sessions", "api-results-upload-sessions", apiUploadSessionsHandler)
+	shared.AddRoute("/api/results/upload/sessions/{id}", "api-results-upload-session", apiUploadSessionHandler)
+	shared.AddRoute("/api/results/upload/sessions/{id}/finalize", "api-results-upload-session-finalize",
+		apiUploadSessionFinalizeHandler)
+
+	// PROTECTED API endpoint for checking that the chunks of a sharded run can
+	// be merged. This API is authenticated in the same way as /api/results/upload.
+	shared.AddRoute("/api/results/merge", "api-results-merge", apiResultsMergeHandler)
+
+	// ADMIN API endpoint for reporting uploaders' quotas and usage.
+	shared.AddRoute("/api/results/quotas", "api-results-quotas", apiQuotasHandler)
+
+	// ADMIN API endpoints for creating, listing and revoking API tokens.
+	shared.AddRoute("/api/results/tokens", "api-results-tokens", apiTokensHandler)
+	shared.AddRoute("/api/results/tokens/{id:[0-9]+}", "api-results-token", apiTokenHandler)
+
 	// PRIVATE API endpoint for creating a test run in Datastore.
 	// This API is authenticated. Only this AppEngine project has the credential.
 	shared.AddRoute("/api/results/create", "api-results-create", apiResultsCreateHandler)
 
-	// PRIVATE API endpoint for updating the status of a pending test run
+	// PRIVATE API endpoint for notifying the notify_url of a pending test run
+	// that it has finished. We call this endpoint from the results-callbacks TaskQueue.
+	shared.AddRoute(CallbackTarget, "api-results-notify", apiResultsNotifyHandler)
+
+	// PRIVATE API endpoint for timing out stuck pending test runs, which is
+	// called by cron.
+	shared.AddRoute(SweepTarget, "api-pending-test-runs-sweep", apiPendingTestRunsSweepHandler)
+
+	// PRIVATE API endpoint for updating the status of a pending test run, and
+	// public API endpoint for getting it (with the history of its stages)
 	shared.AddRoute("/api/status/{id:[0-9]+}", "api-pending-test-run-update", apiPendingTestRunUpdateHandler)
 }
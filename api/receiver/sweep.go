@@ -0,0 +1,170 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// SweepTarget is the handler path for timing out stuck pending runs, which is
+// called by cron.
+const SweepTarget = "/api/status/sweep"
+
+// ErrPendingRunChanged is returned when a pending run was updated after it was
+// found to be stuck, so it is no longer timed out or re-scheduled.
+var ErrPendingRunChanged = errors.New("pending run was updated concurrently")
+
+// sweepResult is the response of HandlePendingRunSweep.
+type sweepResult struct {
+	TimedOut    []int64 `json:"timed_out"`
+	Rescheduled []int64 `json:"rescheduled"`
+	Errors      int     `json:"errors"`
+}
+
+func (a apiImpl) GetPendingRunTimeouts() (shared.PendingRunTimeouts, error) {
+	return shared.LoadPendingRunTimeouts(a.store)
+}
+
+func (a apiImpl) ListPendingTestRuns() ([]shared.PendingTestRun, error) {
+	q := a.store.NewQuery("PendingTestRun").Filter("Stage <", int(shared.StageValid))
+	var runs []shared.PendingTestRun
+	keys, err := a.store.GetAll(q, &runs)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		runs[i].ID = key.IntID()
+	}
+
+	return runs, nil
+}
+
+// updateStuckPendingRun updates the pending run with the mutator, unless it
+// has been updated since it was loaded, in which case ErrPendingRunChanged is
+// returned.
+func (a apiImpl) updateStuckPendingRun(
+	run shared.PendingTestRun, mutator func(*shared.PendingTestRun) error) (shared.PendingTestRun, error) {
+	var buffer shared.PendingTestRun
+	err := a.store.Update(a.store.NewIDKey("PendingTestRun", run.ID), &buffer, func(obj interface{}) error {
+		stored := obj.(*shared.PendingTestRun)
+		if stored.Stage != run.Stage || !stored.Updated.Equal(run.Updated) {
+			return ErrPendingRunChanged
+		}
+		if err := mutator(stored); err != nil {
+			return err
+		}
+		stored.Updated = time.Now()
+
+		return nil
+	})
+	buffer.ID = run.ID
+
+	return buffer, err
+}
+
+func (a apiImpl) TimeOutPendingTestRun(run shared.PendingTestRun, message string) error {
+	updated, err := a.updateStuckPendingRun(run, func(stored *shared.PendingTestRun) error {
+		stored.Error = message
+		if err := stored.Transition(shared.StageTimedOut); err != nil {
+			return err
+		}
+		stored.RecordTransition(shared.SweeperActor, message, time.Now())
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	a.pendingRunStageChanged(updated)
+
+	return nil
+}
+
+func (a apiImpl) ReschedulePendingTestRun(run shared.PendingTestRun) error {
+	if !run.Stage.IsProcessing() || run.ResultsTask == "" {
+		return fmt.Errorf("processing of pending run %d cannot be re-scheduled", run.ID)
+	}
+	payload, err := url.ParseQuery(run.ResultsTask)
+	if err != nil {
+		return err
+	}
+	_, err = a.updateStuckPendingRun(run, func(stored *shared.PendingTestRun) error {
+		stored.Reschedules++
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// The task can't be named after the run, as the name of the original
+	// task can't be reused.
+	_, err = a.ScheduleTask(ResultsQueue, "", ResultsTarget, payload)
+
+	return err
+}
+
+// HandlePendingRunSweep handles the requests from cron for timing out pending
+// runs which are stuck in a stage for longer than its timeout, moving them to
+// the TIMED_OUT stage. Stuck runs which are being processed by wpt.fyi are
+// first re-scheduled for processing, as configured for their stage.
+func HandlePendingRunSweep(a API, w http.ResponseWriter, r *http.Request) {
+	// App Engine strips this header from external requests.
+	if r.Header.Get("X-Appengine-Cron") != "true" && !a.IsAdmin(r) {
+		http.Error(w, "Cron or admin only", http.StatusForbidden)
+
+		return
+	}
+	log := shared.GetLogger(a.Context())
+	timeouts, err := a.GetPendingRunTimeouts()
+	if err != nil {
+		log.Errorf("Failed to load pending run timeouts: %s", err.Error())
+		http.Error(w, "Failed to load pending run timeouts", http.StatusInternalServerError)
+
+		return
+	}
+	runs, err := a.ListPendingTestRuns()
+	if err != nil {
+		log.Errorf("Failed to list pending runs: %s", err.Error())
+		http.Error(w, "Failed to list pending runs", http.StatusInternalServerError)
+
+		return
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	result := sweepResult{TimedOut: []int64{}, Rescheduled: []int64{}}
+	now := time.Now()
+	for _, run := range runs {
+		if !timeouts.IsStuck(run, now) {
+			continue
+		}
+		timeout := timeouts[run.Stage]
+		reschedule := run.Stage.IsProcessing() && run.ResultsTask != "" && run.Reschedules < timeout.Reschedules
+		if reschedule {
+			err = a.ReschedulePendingTestRun(run)
+		} else {
+			err = a.TimeOutPendingTestRun(run, fmt.Sprintf("Timed out after %s in %s", timeout.Timeout(), run.Stage))
+		}
+		switch {
+		case errors.Is(err, ErrPendingRunChanged):
+			continue
+		case err != nil:
+			log.Errorf("Failed to sweep stuck pending run %d: %s", run.ID, err.Error())
+			result.Errors++
+		case reschedule:
+			log.Infof("Re-scheduled processing of pending run %d, stuck in %s", run.ID, run.Stage)
+			result.Rescheduled = append(result.Rescheduled, run.ID)
+		default:
+			log.Infof("Timed out pending run %d, stuck in %s", run.ID, run.Stage)
+			result.TimedOut = append(result.TimedOut, run.ID)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
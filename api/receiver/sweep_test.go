@@ -0,0 +1,107 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func TestHandlePendingRunSweep(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", SweepTarget, nil)
+	req.Header.Set("X-Appengine-Cron", "true")
+	resp := httptest.NewRecorder()
+
+	now := time.Now()
+	timeouts := shared.PendingRunTimeouts{
+		shared.StageCIRunning:        {Stage: shared.StageCIRunning, Minutes: 60},
+		shared.StageWptFyiProcessing: {Stage: shared.StageWptFyiProcessing, Minutes: 60, Reschedules: 1},
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	runs := []shared.PendingTestRun{
+		// Stuck in CI.
+		{ID: 1, Stage: shared.StageCIRunning, Updated: now.Add(-2 * time.Hour)},
+		// Not stuck.
+		{ID: 2, Stage: shared.StageCIRunning, Updated: now.Add(-time.Minute)},
+		// Stuck in processing, for the first time.
+		{ID: 3, Stage: shared.StageWptFyiProcessing, Updated: now.Add(-2 * time.Hour), ResultsTask: "id=3"},
+		// Stuck in processing, after being re-scheduled.
+		{ID: 4, Stage: shared.StageWptFyiProcessing, Updated: now.Add(-2 * time.Hour), ResultsTask: "id=4", Reschedules: 1},
+		// Updated concurrently.
+		{ID: 5, Stage: shared.StageCIRunning, Updated: now.Add(-2 * time.Hour)},
+	}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingRunTimeouts().Return(timeouts, nil),
+		mockAE.EXPECT().ListPendingTestRuns().Return(runs, nil),
+		mockAE.EXPECT().TimeOutPendingTestRun(runs[0], "Timed out after 1h0m0s in CI_RUNNING").Return(nil),
+		mockAE.EXPECT().ReschedulePendingTestRun(runs[2]).Return(nil),
+		mockAE.EXPECT().TimeOutPendingTestRun(runs[3], gomock.Any()).Return(nil),
+		mockAE.EXPECT().TimeOutPendingTestRun(runs[4], gomock.Any()).Return(ErrPendingRunChanged),
+	)
+
+	HandlePendingRunSweep(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var result sweepResult
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, sweepResult{TimedOut: []int64{1, 4}, Rescheduled: []int64{3}}, result)
+}
+
+func TestHandlePendingRunSweep_errors(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", SweepTarget, nil)
+	req.Header.Set("X-Appengine-Cron", "true")
+	resp := httptest.NewRecorder()
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	runs := []shared.PendingTestRun{{ID: 1, Stage: shared.StageCIRunning, Updated: time.Now().Add(-48 * time.Hour)}}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().GetPendingRunTimeouts().Return(shared.DefaultPendingRunTimeouts(), nil),
+		mockAE.EXPECT().ListPendingTestRuns().Return(runs, nil),
+		mockAE.EXPECT().TimeOutPendingTestRun(runs[0], gomock.Any()).Return(errors.New("datastore error")),
+	)
+
+	HandlePendingRunSweep(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var result sweepResult
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &result))
+	assert.Equal(t, 1, result.Errors)
+	assert.Empty(t, result.TimedOut)
+}
+
+func TestHandlePendingRunSweep_notCron(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", SweepTarget, nil)
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().IsAdmin(req).Return(false)
+
+	HandlePendingRunSweep(mockAE, resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// defaultAPITokenLifetime is how long an API token is valid for, if no expiry
+// is given when it is created.
+const defaultAPITokenLifetime = 90 * 24 * time.Hour
+
+// maxAPITokenLifetime is the longest an API token can be valid for.
+const maxAPITokenLifetime = 366 * 24 * time.Hour
+
+// apiTokenRequest is the body of a request to create an API token.
+type apiTokenRequest struct {
+	Uploader    string    `json:"uploader"`
+	Scopes      []string  `json:"scopes"`
+	Products    []string  `json:"products"`
+	Description string    `json:"description"`
+	Expires     time.Time `json:"expires"`
+}
+
+// apiTokenResponse is the response to creating an API token, which is the only
+// time the token itself is revealed.
+type apiTokenResponse struct {
+	shared.APIToken
+
+	Token string `json:"token"`
+}
+
+// newAPIToken validates the request and builds the APIToken (without its
+// hash) from it.
+func (req apiTokenRequest) newAPIToken(now time.Time) (*shared.APIToken, error) {
+	if req.Uploader == "" {
+		return nil, errors.New("uploader is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	for _, scope := range req.Scopes {
+		if !shared.IsAPITokenScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	for _, product := range req.Products {
+		if !shared.IsBrowserName(product) {
+			return nil, fmt.Errorf("unknown product %q", product)
+		}
+	}
+	expires := req.Expires
+	if expires.IsZero() {
+		expires = now.Add(defaultAPITokenLifetime)
+	} else if !expires.After(now) {
+		return nil, errors.New("expires must be in the future")
+	} else if expires.Sub(now) > maxAPITokenLifetime {
+		return nil, fmt.Errorf("expires must be within %d days", maxAPITokenLifetime/(24*time.Hour))
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &shared.APIToken{
+		Uploader:    req.Uploader,
+		Scopes:      req.Scopes,
+		Products:    req.Products,
+		Description: req.Description,
+		Expires:     expires.UTC(),
+		Created:     now.UTC(),
+	}, nil
+}
+
+func (a apiImpl) CreateAPIToken(token *shared.APIToken) error {
+	key, err := a.store.Put(a.store.NewIncompleteKey(shared.APITokenKind), token)
+	if err != nil {
+		return err
+	}
+	token.ID = key.IntID()
+
+	return nil
+}
+
+func (a apiImpl) ListAPITokens(uploader string) ([]shared.APIToken, error) {
+	q := a.store.NewQuery(shared.APITokenKind)
+	if uploader != "" {
+		q = q.Filter("Uploader =", uploader)
+	}
+	var tokens []shared.APIToken
+	keys, err := a.store.GetAll(q, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	for i, key := range keys {
+		tokens[i].ID = key.IntID()
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].Created.After(tokens[j].Created) })
+
+	return tokens, nil
+}
+
+func (a apiImpl) RevokeAPIToken(id int64) (*shared.APIToken, error) {
+	var token shared.APIToken
+	err := a.store.Update(a.store.NewIDKey(shared.APITokenKind, id), &token, func(obj interface{}) error {
+		t := obj.(*shared.APIToken)
+		if t.Hash == "" {
+			return shared.ErrNoSuchEntity
+		}
+		t.Revoked = true
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	token.ID = id
+
+	return &token, nil
+}
+
+func (a apiImpl) GetUser(r *http.Request) *shared.User {
+	user, _ := shared.GetUserFromCookie(a.Context(), a.store, r)
+
+	return user
+}
+
+// HandleAPITokenCreate creates an API token from the JSON body of the request,
+// and responds with it. It is only available to admins.
+func HandleAPITokenCreate(a API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	var req apiTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	token, err := req.newAPIToken(time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	if user := a.GetUser(r); user != nil {
+		token.CreatedBy = user.GitHubHandle
+	}
+
+	log := shared.GetLogger(a.Context())
+	value, hash, err := shared.NewAPITokenValue()
+	if err != nil {
+		log.Errorf("Failed to generate API token: %s", err.Error())
+		http.Error(w, "Failed to generate API token", http.StatusInternalServerError)
+
+		return
+	}
+	token.Hash = hash
+	if err := a.CreateAPIToken(token); err != nil {
+		log.Errorf("Failed to create API token: %s", err.Error())
+		http.Error(w, "Failed to create API token", http.StatusInternalServerError)
+
+		return
+	}
+	log.Infof("%s created API token %d for %s with scopes %v", token.CreatedBy, token.ID, token.Uploader, token.Scopes)
+	writeJSON(w, http.StatusCreated, apiTokenResponse{APIToken: *token, Token: value})
+}
+
+// HandleAPITokenList responds with all API tokens, optionally filtered by the
+// "uploader" param, newest first. It is only available to admins.
+func HandleAPITokenList(a API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	tokens, err := a.ListAPITokens(r.URL.Query().Get("uploader"))
+	if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to list API tokens: %s", err.Error())
+		http.Error(w, "Failed to list API tokens", http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+// HandleAPITokenRevoke revokes the API token of the request, and responds with
+// it. It is only available to admins.
+func HandleAPITokenRevoke(a API, w http.ResponseWriter, r *http.Request) {
+	if !a.IsAdmin(r) {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return
+	}
+	idParam := mux.Vars(r)["id"]
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid ID: "+idParam, http.StatusBadRequest)
+
+		return
+	}
+	token, err := a.RevokeAPIToken(id)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		http.Error(w, "API token not found", http.StatusNotFound)
+
+		return
+	} else if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to revoke API token %d: %s", id, err.Error())
+		http.Error(w, "Failed to revoke API token", http.StatusInternalServerError)
+
+		return
+	}
+	shared.GetLogger(a.Context()).Infof("Revoked API token %d of %s", id, token.Uploader)
+	writeJSON(w, http.StatusOK, token)
+}
+
+// checkTokenProducts checks that the API token (if any) the request was
+// authenticated with allows all the given browsers, writing a 403 and
+// returning false otherwise. Unknown browsers ("") are not allowed by tokens
+// with product restrictions.
+func checkTokenProducts(w http.ResponseWriter, token *shared.APIToken, browserNames ...string) bool {
+	if token == nil || len(token.Products) == 0 {
+		return true
+	}
+	if len(browserNames) == 0 {
+		browserNames = []string{""}
+	}
+	for _, browserName := range browserNames {
+		if !token.AllowsProduct(browserName) {
+			http.Error(w,
+				fmt.Sprintf("API token is restricted to products %v, not %q", token.Products, browserName),
+				http.StatusForbidden)
+
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,244 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newTestAPIToken(products ...string) *shared.APIToken {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return &shared.APIToken{
+		ID:       1,
+		Uploader: "blade-runner",
+		Scopes:   []string{string(shared.ScopeUploadResults)},
+		Products: products,
+		Expires:  time.Now().Add(time.Hour),
+	}
+}
+
+func TestAuthenticateUploaderToken_bearer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("POST", "/api/results/upload", nil)
+	req.Header.Set("Authorization", "Bearer wptfyi_secret")
+	token := newTestAPIToken()
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockAE.EXPECT().GetAPIToken("wptfyi_secret").Return(token, nil).Times(2)
+
+	uploader, got := AuthenticateUploaderToken(mockAE, req, shared.ScopeUploadResults)
+	assert.Equal(t, "blade-runner", uploader)
+	assert.Equal(t, token, got)
+
+	// The token can't be used outside its scopes.
+	uploader, got = AuthenticateUploaderToken(mockAE, req, shared.ScopeCreateRuns)
+	assert.Equal(t, "", uploader)
+	assert.Nil(t, got)
+}
+
+func TestAuthenticateUploaderToken_invalidBearer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("POST", "/api/results/upload", nil)
+	req.Header.Set("Authorization", "Bearer wptfyi_revoked")
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	mockAE.EXPECT().GetAPIToken("wptfyi_revoked").Return(nil, shared.ErrInvalidAPIToken)
+
+	assert.Equal(t, "", AuthenticateUploader(mockAE, req, shared.ScopeUploadResults))
+}
+
+func TestAuthenticateUploaderToken_basicAuth(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("POST", "/api/results/create", nil)
+	req.SetBasicAuth("_processor", "secret-token")
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{Username: "_processor", Password: "secret-token"}, nil)
+
+	uploader, token := AuthenticateUploaderToken(mockAE, req, shared.ScopeCreateRuns)
+	assert.Equal(t, InternalUsername, uploader)
+	assert.Nil(t, token)
+}
+
+func TestHandleResultsUpload_tokenProducts(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{
+		"result_url":   {"https://wpt.fyi/test.json.gz"},
+		"browser_name": {"firefox"},
+	}
+	req := httptest.NewRequest("POST", "/api/results/upload", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer wptfyi_secret")
+	resp := httptest.NewRecorder()
+
+	// Neither ReserveUploadQuota nor ScheduleResultsTask are expected.
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetAPIToken("wptfyi_secret").Return(newTestAPIToken("chrome"), nil),
+	)
+
+	HandleResultsUpload(mockAE, resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestNewAPIToken(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	req := apiTokenRequest{
+		Uploader: "blade-runner",
+		Scopes:   []string{"upload_results"},
+		Products: []string{"chrome"},
+	}
+	token, err := req.newAPIToken(now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(defaultAPITokenLifetime), token.Expires)
+	assert.Equal(t, []string{"chrome"}, token.Products)
+
+	for _, invalid := range []apiTokenRequest{
+		{Scopes: []string{"upload_results"}},
+		{Uploader: "blade-runner"},
+		{Uploader: "blade-runner", Scopes: []string{"delete_everything"}},
+		{Uploader: "blade-runner", Scopes: []string{"upload_results"}, Products: []string{"netscape"}},
+		{Uploader: "blade-runner", Scopes: []string{"upload_results"}, Expires: now.Add(-time.Hour)},
+		{Uploader: "blade-runner", Scopes: []string{"upload_results"}, Expires: now.Add(2 * maxAPITokenLifetime)},
+	} {
+		_, err := invalid.newAPIToken(now)
+		assert.NotNil(t, err, "%v", invalid)
+	}
+}
+
+func TestHandleAPITokenCreate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	body := `{"uploader": "blade-runner", "scopes": ["upload_results"], "products": ["chrome"]}`
+	req := httptest.NewRequest("POST", "/api/results/tokens", strings.NewReader(body))
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	var created *shared.APIToken
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(true),
+		mockAE.EXPECT().GetUser(req).Return(&shared.User{GitHubHandle: "deckard"}),
+		mockAE.EXPECT().CreateAPIToken(gomock.Any()).DoAndReturn(func(token *shared.APIToken) error {
+			token.ID = 42
+			created = token
+
+			return nil
+		}),
+	)
+
+	HandleAPITokenCreate(mockAE, resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var got apiTokenResponse
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	assert.Equal(t, int64(42), got.ID)
+	assert.Equal(t, "deckard", got.CreatedBy)
+	// Only the hash of the token is stored.
+	assert.Equal(t, shared.HashAPIToken(got.Token), created.Hash)
+	assert.NotContains(t, resp.Body.String(), created.Hash)
+}
+
+func TestHandleAPITokenCreate_notAdmin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("POST", "/api/results/tokens", strings.NewReader("{}"))
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().IsAdmin(req).Return(false)
+
+	HandleAPITokenCreate(mockAE, resp, req)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
+
+func TestHandleAPITokenList(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", "/api/results/tokens?uploader=blade-runner", nil)
+	resp := httptest.NewRecorder()
+
+	tokens := []shared.APIToken{*newTestAPIToken()}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(true),
+		mockAE.EXPECT().ListAPITokens("blade-runner").Return(tokens, nil),
+	)
+
+	HandleAPITokenList(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var got []shared.APIToken
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	assert.Len(t, got, 1)
+	assert.Equal(t, "blade-runner", got[0].Uploader)
+}
+
+func TestHandleAPITokenRevoke(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("DELETE", "/api/results/tokens/1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "1"})
+	resp := httptest.NewRecorder()
+
+	revoked := newTestAPIToken()
+	revoked.Revoked = true
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(true),
+		mockAE.EXPECT().RevokeAPIToken(int64(1)).Return(revoked, nil),
+	)
+
+	HandleAPITokenRevoke(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestHandleAPITokenRevoke_notFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("DELETE", "/api/results/tokens/2", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "2"})
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(true),
+		mockAE.EXPECT().RevokeAPIToken(int64(2)).Return(nil, shared.ErrNoSuchEntity),
+	)
+
+	HandleAPITokenRevoke(mockAE, resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -6,6 +6,7 @@ package receiver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,9 +16,33 @@ import (
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
+// HandleGetPendingTestRun handles the GET request for a pending test run,
+// including the history of its stage transitions.
+func HandleGetPendingTestRun(a API, w http.ResponseWriter, r *http.Request) {
+	idParam := mux.Vars(r)["id"]
+	id, err := strconv.ParseInt(idParam, 10, 0)
+	if err != nil {
+		http.Error(w, "Invalid ID: "+idParam, http.StatusBadRequest)
+
+		return
+	}
+	run, err := a.GetPendingTestRun(id)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		http.NotFound(w, r)
+
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
 // HandleUpdatePendingTestRun handles the PATCH request for updating pending test runs.
 func HandleUpdatePendingTestRun(a API, w http.ResponseWriter, r *http.Request) {
-	if AuthenticateUploader(a, r) != InternalUsername {
+	uploader, token := AuthenticateUploaderToken(a, r, shared.ScopeUpdatePendingRuns)
+	if uploader != InternalUsername {
 		http.Error(w, "This is a private API.", http.StatusUnauthorized)
 
 		return
@@ -36,6 +61,10 @@ func HandleUpdatePendingTestRun(a API, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkTokenProducts(w, token, run.BrowserName) {
+		return
+	}
+
 	vars := mux.Vars(r)
 	idParam := vars["id"]
 	id, err := strconv.ParseInt(idParam, 10, 0)
@@ -50,7 +79,7 @@ func HandleUpdatePendingTestRun(a API, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := a.UpdatePendingTestRun(run); err != nil {
+	if err := a.UpdatePendingTestRun(uploader, run); err != nil {
 		http.Error(w, "Failed to update run: "+err.Error(), http.StatusInternalServerError)
 
 		return
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -44,7 +45,7 @@ func TestApiPendingTestRunUpdateHandler(t *testing.T) {
 	mockAE.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
 	gomock.InOrder(
 		mockAE.EXPECT().GetUploader("_processor").Return(shared.Uploader{"_processor", "secret-token"}, nil),
-		mockAE.EXPECT().UpdatePendingTestRun(pendingRun).Return(nil),
+		mockAE.EXPECT().UpdatePendingTestRun(InternalUsername, pendingRun).Return(nil),
 	)
 
 	w := httptest.NewRecorder()
@@ -52,3 +53,51 @@ func TestApiPendingTestRunUpdateHandler(t *testing.T) {
 	resp := w.Result()
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 }
+
+func TestHandleGetPendingTestRun(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	pendingRun := shared.PendingTestRun{
+		ID:       12345,
+		Uploader: "azure",
+		Stage:    shared.StageWptFyiReceived,
+		Transitions: []shared.PendingTestRunTransition{
+			{Stage: shared.StageCIRunning, Time: now, Actor: "azure"},
+			{Stage: shared.StageWptFyiReceived, Time: now.Add(time.Hour), Actor: "azure"},
+		},
+	}
+	req := httptest.NewRequest("GET", "/api/status/12345", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "12345"})
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().GetPendingTestRun(int64(12345)).Return(&pendingRun, nil)
+
+	w := httptest.NewRecorder()
+	HandleGetPendingTestRun(mockAE, w, req)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var got map[string]interface{}
+	assert.Nil(t, json.NewDecoder(resp.Body).Decode(&got))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"stage": "CI_RUNNING", "time": "2026-10-18T12:00:00Z", "actor": "azure"},
+		map[string]interface{}{"stage": "WPTFYI_RECEIVED", "time": "2026-10-18T13:00:00Z", "actor": "azure"},
+	}, got["transitions"])
+}
+
+func TestHandleGetPendingTestRun_notFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := httptest.NewRequest("GET", "/api/status/12345", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "12345"})
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().GetPendingTestRun(int64(12345)).Return(nil, shared.ErrNoSuchEntity)
+
+	w := httptest.NewRecorder()
+	HandleGetPendingTestRun(mockAE, w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,318 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// uploadSessionKind is the Datastore kind of ResultsUploadSession entities.
+const uploadSessionKind = "ResultsUploadSession"
+
+// maxChunkSize is the maximum size of a chunk of a resumable upload, which is
+// bounded by the max request size on AppEngine.
+const maxChunkSize = 32 << 20
+
+var errUploadSessionConflict = errors.New("upload session was modified concurrently")
+
+// HandleUploadSessionCreate starts a resumable upload of a single (large)
+// results file. It accepts the same uploader and extra params as
+// HandleResultsUpload, and responds with the new session.
+func HandleUploadSessionCreate(a API, w http.ResponseWriter, r *http.Request) {
+	uploader, token := getUploader(a, w, r)
+	if uploader == "" || !checkTokenProducts(w, token, r.FormValue("browser_name")) {
+		return
+	}
+	if err := a.ReserveUploadQuota(uploader, 0); err != nil {
+		writeQuotaError(a, w, err)
+
+		return
+	}
+
+	extraParams := getExtraParams(r)
+	if !checkNotifyURL(w, extraParams) || !checkDuplicatePolicy(w, extraParams) ||
+		!checkCallbackSecret(a, w, uploader, extraParams) {
+		return
+	}
+	params := url.Values{}
+	for k, v := range extraParams {
+		params.Set(k, v)
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	session := shared.ResultsUploadSession{
+		Uploader: uploader,
+		Params:   params.Encode(),
+	}
+	if err := a.CreateUploadSession(&session); err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to create upload session: %s", err.Error())
+		http.Error(w, "Failed to create upload session", http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// HandleUploadSessionStatus responds with the session, whose offset is where
+// an interrupted upload should be resumed from.
+func HandleUploadSessionStatus(a API, w http.ResponseWriter, r *http.Request) {
+	session := getAuthorizedUploadSession(a, w, r)
+	if session == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// HandleUploadSessionChunk appends the request body to the session's file.
+// The "offset" param must match the session's offset, so chunks can't be
+// skipped or written twice; a 409 with the session is returned otherwise.
+func HandleUploadSessionChunk(a API, w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid offset param", http.StatusBadRequest)
+
+		return
+	}
+	session := getAuthorizedUploadSession(a, w, r)
+	if session == nil {
+		return
+	}
+	if session.Finalized {
+		http.Error(w, "Upload session is already finalized", http.StatusConflict)
+
+		return
+	} else if offset != session.Offset {
+		writeJSON(w, http.StatusConflict, session)
+
+		return
+	}
+
+	chunk, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxChunkSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+
+		return
+	} else if len(chunk) == 0 {
+		http.Error(w, "Empty chunk", http.StatusBadRequest)
+
+		return
+	}
+
+	if err := a.ReserveUploadQuota(session.Uploader, int64(len(chunk))); err != nil {
+		writeQuotaError(a, w, err)
+
+		return
+	}
+
+	log := shared.GetLogger(a.Context())
+	chunkPath := fmt.Sprintf("gs://%s/%s/%s/chunks/%d", BufferBucket, session.Uploader, session.ID, offset)
+	if err := uploadWithRetry(a, chunkPath, bytes.NewReader(chunk), false); err != nil {
+		log.Errorf("Failed to save chunk to GCS: %s", err.Error())
+		http.Error(w, "Failed to save chunk to GCS", http.StatusInternalServerError)
+
+		return
+	}
+
+	session, err = a.UpdateUploadSession(session.ID, func(s *shared.ResultsUploadSession) error {
+		if s.Finalized || s.Offset != offset {
+			return errUploadSessionConflict
+		}
+		if offset == 0 {
+			s.Gzipped = isGzip(chunk)
+		}
+		s.Chunks = append(s.Chunks, chunkPath)
+		s.Offset += int64(len(chunk))
+
+		return nil
+	})
+	if errors.Is(err, errUploadSessionConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	} else if err != nil {
+		log.Errorf("Failed to update upload session: %s", err.Error())
+		http.Error(w, "Failed to update upload session", http.StatusInternalServerError)
+
+		return
+	}
+	writeJSON(w, http.StatusOK, session)
+}
+
+// HandleUploadSessionFinalize assembles the uploaded chunks into the results
+// file, validates it (see validateReport), and schedules it for processing.
+func HandleUploadSessionFinalize(a API, w http.ResponseWriter, r *http.Request) {
+	session := getAuthorizedUploadSession(a, w, r)
+	if session == nil {
+		return
+	}
+	if len(session.Chunks) == 0 {
+		http.Error(w, "No chunks have been uploaded", http.StatusBadRequest)
+
+		return
+	}
+
+	// Mark the session as finalized first, so that concurrent requests don't
+	// schedule the same results twice.
+	log := shared.GetLogger(a.Context())
+	session, err := a.UpdateUploadSession(session.ID, func(s *shared.ResultsUploadSession) error {
+		if s.Finalized {
+			return errUploadSessionConflict
+		}
+		s.Finalized = true
+
+		return nil
+	})
+	if errors.Is(err, errUploadSessionConflict) {
+		http.Error(w, "Upload session is already finalized", http.StatusConflict)
+
+		return
+	} else if err != nil {
+		log.Errorf("Failed to update upload session: %s", err.Error())
+		http.Error(w, "Failed to update upload session", http.StatusInternalServerError)
+
+		return
+	}
+
+	resultPath := fmt.Sprintf("gs://%s/%s/%s/0.json", BufferBucket, session.Uploader, session.ID)
+	if err := a.ComposeGCS(resultPath, session.Chunks, session.Gzipped); err != nil {
+		log.Errorf("Failed to compose %s: %s", resultPath, err.Error())
+		reopenUploadSession(a, session.ID)
+		http.Error(w, "Failed to assemble results in GCS", http.StatusInternalServerError)
+
+		return
+	}
+	validation, err := validateGCSReport(a, resultPath)
+	if err != nil {
+		log.Errorf("Failed to read %s: %s", resultPath, err.Error())
+		reopenUploadSession(a, session.ID)
+		http.Error(w, "Failed to read results from GCS", http.StatusInternalServerError)
+
+		return
+	}
+	if !validation.Valid() {
+		log.Errorf("Invalid wptreport in upload session %s: %d error(s)", session.ID, len(validation.Errors))
+		deleteUploadChunks(a, session.Chunks)
+		if err := a.DeleteGCS(resultPath); err != nil {
+			log.Warningf("Failed to delete %s: %s", resultPath, err.Error())
+		}
+		writeJSON(w, http.StatusBadRequest, validation)
+
+		return
+	}
+
+	params, _ := url.ParseQuery(session.Params)
+	extraParams := make(map[string]string, len(params))
+	for k := range params {
+		extraParams[k] = params.Get(k)
+	}
+	t, err := a.ScheduleResultsTask(session.Uploader, []string{resultPath}, nil, nil, extraParams)
+	if err != nil {
+		log.Errorf("Failed to schedule task: %v", err)
+		reopenUploadSession(a, session.ID)
+		http.Error(w, "Failed to schedule task", http.StatusInternalServerError)
+
+		return
+	}
+	// The chunks are kept until the task is recorded, so that a failed
+	// finalize can be retried by composing them again.
+	if _, err := a.UpdateUploadSession(session.ID, func(s *shared.ResultsUploadSession) error {
+		s.Task = t
+
+		return nil
+	}); err != nil {
+		log.Warningf("Failed to record task %s on upload session %s: %s", t, session.ID, err.Error())
+	} else {
+		deleteUploadChunks(a, session.Chunks)
+	}
+	log.Infof("Task %s added to queue", t)
+	fmt.Fprintf(w, "Task %s added to queue\n", t)
+}
+
+// validateGCSReport streams the wptreport at gcsPath through validateReport.
+func validateGCSReport(a API, gcsPath string) (ReportValidation, error) {
+	reader, err := a.OpenGCS(gcsPath)
+	if err != nil {
+		return ReportValidation{}, err
+	}
+	defer reader.Close()
+
+	v := ReportValidation{Files: 1}
+	tests, product, errs := validateReport(gcsPath, reader, make(map[string]string))
+	v.Tests = tests
+	if product != "" {
+		v.Products = []string{product}
+	}
+	if len(errs) > maxReportErrors {
+		errs = errs[:maxReportErrors]
+	}
+	v.Errors = errs
+
+	return v, nil
+}
+
+// deleteUploadChunks deletes the chunks of a session, which are no longer
+// needed once they've been assembled and processed (or rejected).
+func deleteUploadChunks(a API, chunks []string) {
+	for _, chunk := range chunks {
+		if err := a.DeleteGCS(chunk); err != nil {
+			shared.GetLogger(a.Context()).Warningf("Failed to delete chunk %s: %s", chunk, err.Error())
+		}
+	}
+}
+
+// reopenUploadSession undoes the finalization of a session which failed to be
+// processed, so that finalizing it can be retried.
+func reopenUploadSession(a API, id string) {
+	if _, err := a.UpdateUploadSession(id, func(s *shared.ResultsUploadSession) error {
+		s.Finalized = false
+
+		return nil
+	}); err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to reopen upload session %s: %s", id, err.Error())
+	}
+}
+
+// getAuthorizedUploadSession loads the session of the request, if it belongs
+// to the authenticated uploader (or the user is an admin), and the API token
+// (if any) allows its product. It returns nil (after writing an error
+// response) otherwise.
+func getAuthorizedUploadSession(a API, w http.ResponseWriter, r *http.Request) *shared.ResultsUploadSession {
+	admin := a.IsAdmin(r)
+	uploader := ""
+	var token *shared.APIToken
+	if !admin {
+		if uploader, token = AuthenticateUploaderToken(a, r, shared.ScopeUploadResults); uploader == "" {
+			http.Error(w, "Authentication error", http.StatusUnauthorized)
+
+			return nil
+		}
+	}
+
+	session, err := a.GetUploadSession(mux.Vars(r)["id"])
+	if errors.Is(err, shared.ErrNoSuchEntity) || (err == nil && !admin && session.Uploader != uploader) {
+		http.Error(w, "Upload session not found", http.StatusNotFound)
+
+		return nil
+	} else if err != nil {
+		shared.GetLogger(a.Context()).Errorf("Failed to load upload session: %s", err.Error())
+		http.Error(w, "Failed to load upload session", http.StatusInternalServerError)
+
+		return nil
+	}
+	params, _ := url.ParseQuery(session.Params)
+	if !checkTokenProducts(w, token, params.Get("browser_name")) {
+		return nil
+	}
+
+	return session
+}
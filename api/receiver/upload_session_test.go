@@ -0,0 +1,321 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/api/receiver/mock_receiver"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+// updateSession returns a function to be used with DoAndReturn for
+// UpdateUploadSession, which applies the mutator to the given session.
+func updateSession(session *shared.ResultsUploadSession) func(
+	string, func(*shared.ResultsUploadSession) error) (*shared.ResultsUploadSession, error) {
+	return func(_ string, mutator func(*shared.ResultsUploadSession) error) (*shared.ResultsUploadSession, error) {
+		updated := *session
+		if err := mutator(&updated); err != nil {
+			return nil, err
+		}
+		*session = updated
+
+		return &updated, nil
+	}
+}
+
+func newSessionRequest(method, target string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.SetBasicAuth("blade-runner", "123")
+
+	return mux.SetURLVars(req, map[string]string{"id": "abc"})
+}
+
+func TestHandleUploadSessionCreate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	payload := url.Values{"labels": {"stable"}}
+	req := httptest.NewRequest("POST", "/api/results/upload/sessions", strings.NewReader(payload.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("blade-runner", "123")
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(0)).Return(nil),
+		mockAE.EXPECT().CreateUploadSession(gomock.Any()).DoAndReturn(func(s *shared.ResultsUploadSession) error {
+			assert.Equal(t, "blade-runner", s.Uploader)
+			params, err := url.ParseQuery(s.Params)
+			assert.Nil(t, err)
+			assert.Equal(t, "stable", params.Get("labels"))
+			s.ID = "abc"
+
+			return nil
+		}),
+	)
+
+	HandleUploadSessionCreate(mockAE, resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	var session shared.ResultsUploadSession
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &session))
+	assert.Equal(t, "abc", session.ID)
+	assert.Equal(t, int64(0), session.Offset)
+}
+
+func TestHandleUploadSessionStatus_otherUploader(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("GET", "/api/results/upload/sessions/abc", nil)
+	resp := httptest.NewRecorder()
+
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&shared.ResultsUploadSession{ID: "abc", Uploader: "replicant"}, nil),
+	)
+
+	HandleUploadSessionStatus(mockAE, resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHandleUploadSessionChunk(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	chunk := []byte{0x1f, 0x8b, 0x08, 0x00}
+	req := newSessionRequest("PUT", "/api/results/upload/sessions/abc?offset=0", bytes.NewReader(chunk))
+	resp := httptest.NewRecorder()
+
+	session := shared.ResultsUploadSession{ID: "abc", Uploader: "blade-runner"}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().ReserveUploadQuota("blade-runner", int64(4)).Return(nil),
+		mockAE.EXPECT().UploadToGCS("gs://wptd-results-buffer/blade-runner/abc/chunks/0", gomock.Any(), false).Return(nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+	)
+
+	HandleUploadSessionChunk(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, int64(4), session.Offset)
+	assert.True(t, session.Gzipped)
+	assert.Equal(t, []string{"gs://wptd-results-buffer/blade-runner/abc/chunks/0"}, session.Chunks)
+}
+
+func TestHandleUploadSessionChunk_wrongOffset(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("PUT", "/api/results/upload/sessions/abc?offset=0", strings.NewReader("chunk"))
+	resp := httptest.NewRecorder()
+
+	session := shared.ResultsUploadSession{ID: "abc", Uploader: "blade-runner", Offset: 10}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+	)
+
+	HandleUploadSessionChunk(mockAE, resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	var current shared.ResultsUploadSession
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &current))
+	assert.Equal(t, int64(10), current.Offset)
+}
+
+func TestHandleUploadSessionFinalize(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("POST", "/api/results/upload/sessions/abc/finalize", nil)
+	resp := httptest.NewRecorder()
+
+	params := url.Values{}
+	for k, v := range emptyParams {
+		params.Set(k, v)
+	}
+	chunks := []string{
+		"gs://wptd-results-buffer/blade-runner/abc/chunks/0",
+		"gs://wptd-results-buffer/blade-runner/abc/chunks/100",
+	}
+	session := shared.ResultsUploadSession{
+		ID:       "abc",
+		Uploader: "blade-runner",
+		Params:   params.Encode(),
+		Chunks:   chunks,
+		Offset:   200,
+		Gzipped:  true,
+	}
+	resultPath := "gs://wptd-results-buffer/blade-runner/abc/0.json"
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+		mockAE.EXPECT().ComposeGCS(resultPath, chunks, true).Return(nil),
+		mockAE.EXPECT().OpenGCS(resultPath).Return(io.NopCloser(strings.NewReader(validReport("/test.html"))), nil),
+		mockAE.EXPECT().ScheduleResultsTask("blade-runner", []string{resultPath}, nil, nil, emptyParams).Return("task", nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+		mockAE.EXPECT().DeleteGCS(chunks[0]).Return(nil),
+		mockAE.EXPECT().DeleteGCS(chunks[1]).Return(nil),
+	)
+
+	HandleUploadSessionFinalize(mockAE, resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, session.Finalized)
+	assert.Equal(t, "task", session.Task)
+}
+
+func TestHandleUploadSessionFinalize_composeFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("POST", "/api/results/upload/sessions/abc/finalize", nil)
+	resp := httptest.NewRecorder()
+
+	session := shared.ResultsUploadSession{
+		ID:       "abc",
+		Uploader: "blade-runner",
+		Chunks:   []string{"gs://wptd-results-buffer/blade-runner/abc/chunks/0"},
+	}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+		mockAE.EXPECT().ComposeGCS(gomock.Any(), gomock.Any(), false).Return(errors.New("compose failed")),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+	)
+
+	HandleUploadSessionFinalize(mockAE, resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	// The session can be finalized again.
+	assert.False(t, session.Finalized)
+}
+
+func TestHandleUploadSessionFinalize_scheduleFails(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("POST", "/api/results/upload/sessions/abc/finalize", nil)
+	resp := httptest.NewRecorder()
+
+	chunk := "gs://wptd-results-buffer/blade-runner/abc/chunks/0"
+	session := shared.ResultsUploadSession{
+		ID:       "abc",
+		Uploader: "blade-runner",
+		Chunks:   []string{chunk},
+	}
+	resultPath := "gs://wptd-results-buffer/blade-runner/abc/0.json"
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	// The chunks aren't deleted, so that the finalize can be retried.
+	mockAE.EXPECT().DeleteGCS(gomock.Any()).Times(0)
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+		mockAE.EXPECT().ComposeGCS(resultPath, []string{chunk}, false).Return(nil),
+		mockAE.EXPECT().OpenGCS(resultPath).Return(io.NopCloser(strings.NewReader(validReport("/test.html"))), nil),
+		mockAE.EXPECT().ScheduleResultsTask("blade-runner", []string{resultPath}, nil, nil, gomock.Any()).Return("", errors.New("queue unavailable")),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+	)
+
+	HandleUploadSessionFinalize(mockAE, resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.False(t, session.Finalized)
+}
+
+func TestHandleUploadSessionFinalize_invalidReport(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("POST", "/api/results/upload/sessions/abc/finalize", nil)
+	resp := httptest.NewRecorder()
+
+	chunk := "gs://wptd-results-buffer/blade-runner/abc/chunks/0"
+	session := shared.ResultsUploadSession{
+		ID:       "abc",
+		Uploader: "blade-runner",
+		Chunks:   []string{chunk},
+	}
+	resultPath := "gs://wptd-results-buffer/blade-runner/abc/0.json"
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+		mockAE.EXPECT().ComposeGCS(resultPath, []string{chunk}, false).Return(nil),
+		mockAE.EXPECT().OpenGCS(resultPath).Return(io.NopCloser(strings.NewReader(`{"results": [`)), nil),
+		mockAE.EXPECT().DeleteGCS(chunk).Return(nil),
+		mockAE.EXPECT().DeleteGCS(resultPath).Return(nil),
+	)
+
+	HandleUploadSessionFinalize(mockAE, resp, req)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	var validation ReportValidation
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &validation))
+	assert.False(t, validation.Valid())
+}
+
+func TestHandleUploadSessionFinalize_alreadyFinalized(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	req := newSessionRequest("POST", "/api/results/upload/sessions/abc/finalize", nil)
+	resp := httptest.NewRecorder()
+
+	session := shared.ResultsUploadSession{
+		ID:        "abc",
+		Uploader:  "blade-runner",
+		Chunks:    []string{"gs://wptd-results-buffer/blade-runner/abc/chunks/0"},
+		Finalized: true,
+	}
+	mockAE := mock_receiver.NewMockAPI(mockCtrl)
+	mockAE.EXPECT().Context().Return(sharedtest.NewTestContext()).AnyTimes()
+	gomock.InOrder(
+		mockAE.EXPECT().IsAdmin(req).Return(false),
+		mockAE.EXPECT().GetUploader("blade-runner").Return(shared.Uploader{"blade-runner", "123"}, nil),
+		mockAE.EXPECT().GetUploadSession("abc").Return(&session, nil),
+		mockAE.EXPECT().UpdateUploadSession("abc", gomock.Any()).DoAndReturn(updateSession(&session)),
+	)
+
+	HandleUploadSessionFinalize(mockAE, resp, req)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"slices"
+	"sort"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/metrics"
+)
+
+// maxReportErrors is the maximum number of errors reported for an upload, to
+// keep the response of a badly broken upload reasonably small.
+const maxReportErrors = 100
+
+// ReportError is a problem found while validating an uploaded wptreport.
+type ReportError struct {
+	// File is the name of the uploaded file.
+	File string `json:"file"`
+	// Line is the (1-based) line of the (uncompressed) file, if known.
+	Line    int    `json:"line,omitempty"`
+	Test    string `json:"test,omitempty"`
+	Subtest string `json:"subtest,omitempty"`
+	Message string `json:"message"`
+}
+
+// ReportValidation is the result of validating the wptreports of an upload.
+type ReportValidation struct {
+	Files int `json:"files"`
+	Tests int `json:"tests"`
+	// Products are the distinct run_info.product values of the reports.
+	Products []string      `json:"products,omitempty"`
+	Errors   []ReportError `json:"errors,omitempty"`
+}
+
+// Valid returns whether no errors were found.
+func (v ReportValidation) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// validateResultFiles validates every uploaded result file; see validateReport.
+// Test names must be unique across all files of the upload.
+func validateResultFiles(files []*multipart.FileHeader) ReportValidation {
+	v := ReportValidation{Files: len(files)}
+	seen := make(map[string]string)
+	for _, header := range files {
+		f, err := header.Open()
+		if err != nil {
+			v.Errors = append(v.Errors, ReportError{File: header.Filename, Message: err.Error()})
+
+			continue
+		}
+		tests, product, errs := validateReport(header.Filename, f, seen)
+		f.Close()
+		v.Tests += tests
+		if product != "" && !slices.Contains(v.Products, product) {
+			v.Products = append(v.Products, product)
+		}
+		v.Errors = append(v.Errors, errs...)
+		if len(v.Errors) >= maxReportErrors {
+			v.Errors = v.Errors[:maxReportErrors]
+
+			break
+		}
+	}
+
+	return v
+}
+
+// validateReport streams a (possibly gzipped) wptreport, checking that it has
+// the required "results" and "run_info" fields, that every test and subtest
+// has a known status, and that no test appears twice. seen maps the test names
+// found so far to the file they were found in. It returns the number of tests
+// in the report, its run_info.product (if any), and the errors found.
+func validateReport(file string, r io.Reader, seen map[string]string) (int, string, []ReportError) {
+	br := bufio.NewReader(r)
+	var reader io.Reader = br
+	if prefix, _ := br.Peek(2); isGzip(prefix) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, "", []ReportError{{File: file, Message: "invalid gzip: " + err.Error()}}
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	v := reportValidator{
+		file:  file,
+		lines: &lineReader{r: reader},
+		seen:  seen,
+	}
+	v.validate()
+
+	return v.tests, v.product, v.errors
+}
+
+// lineReader records the offsets of newlines read through it, to map decoder
+// offsets back to lines.
+type lineReader struct {
+	r        io.Reader
+	offset   int64
+	newlines []int64
+}
+
+func (l *lineReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			l.newlines = append(l.newlines, l.offset+int64(i))
+		}
+	}
+	l.offset += int64(n)
+
+	return n, err
+}
+
+// line returns the 1-based line of the given offset.
+func (l *lineReader) line(offset int64) int {
+	return sort.Search(len(l.newlines), func(i int) bool { return l.newlines[i] >= offset }) + 1
+}
+
+type reportValidator struct {
+	file    string
+	lines   *lineReader
+	dec     *json.Decoder
+	seen    map[string]string
+	tests   int
+	product string
+	errors  []ReportError
+}
+
+func (v *reportValidator) addError(offset int64, test, subtest, format string, args ...interface{}) {
+	v.errors = append(v.errors, ReportError{
+		File:    v.file,
+		Line:    v.lines.line(offset),
+		Test:    test,
+		Subtest: subtest,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// addDecodeError records an error from the decoder, using the offset of the
+// syntax or type error where available.
+func (v *reportValidator) addDecodeError(err error) {
+	offset := v.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		v.addError(offset, "", "", "unexpected end of report")
+
+		return
+	}
+	v.addError(offset, "", "", "invalid JSON: %s", err.Error())
+}
+
+func (v *reportValidator) validate() {
+	v.dec = json.NewDecoder(v.lines)
+	if !v.expectDelim('{', "report must be a JSON object") {
+		return
+	}
+	var hasResults, hasRunInfo bool
+	for v.dec.More() {
+		tok, err := v.dec.Token()
+		if err != nil {
+			v.addDecodeError(err)
+
+			return
+		}
+		key, _ := tok.(string)
+		switch key {
+		case "results":
+			hasResults = true
+			if !v.validateResults() {
+				return
+			}
+		case "run_info":
+			hasRunInfo = true
+			offset := v.dec.InputOffset()
+			var runInfo map[string]interface{}
+			if err := v.dec.Decode(&runInfo); err != nil {
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &typeErr) {
+					v.addDecodeError(err)
+
+					return
+				}
+				v.addError(offset, "", "", "run_info must be an object")
+			}
+			v.product, _ = runInfo["product"].(string)
+		default:
+			var skip json.RawMessage
+			if err := v.dec.Decode(&skip); err != nil {
+				v.addDecodeError(err)
+
+				return
+			}
+		}
+		if len(v.errors) >= maxReportErrors {
+			return
+		}
+	}
+	if !v.expectDelim('}', "report must be a JSON object") {
+		return
+	}
+	if !hasResults {
+		v.addError(v.dec.InputOffset(), "", "", `missing required field "results"`)
+	}
+	if !hasRunInfo {
+		v.addError(v.dec.InputOffset(), "", "", `missing required field "run_info"`)
+	}
+}
+
+func (v *reportValidator) expectDelim(delim json.Delim, message string) bool {
+	offset := v.dec.InputOffset()
+	tok, err := v.dec.Token()
+	if err != nil {
+		v.addDecodeError(err)
+
+		return false
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		v.addError(offset, "", "", "%s", message)
+
+		return false
+	}
+
+	return true
+}
+
+// validateResults validates the elements of the "results" array, returning
+// false if the rest of the report can't be read.
+func (v *reportValidator) validateResults() bool {
+	if !v.expectDelim('[', "results must be an array") {
+		return false
+	}
+	for v.dec.More() {
+		var raw json.RawMessage
+		if err := v.dec.Decode(&raw); err != nil {
+			v.addDecodeError(err)
+
+			return false
+		}
+		// RawMessage excludes surrounding whitespace, so this is where the
+		// result begins.
+		offset := v.dec.InputOffset() - int64(len(raw))
+		var result metrics.TestResults
+		if err := json.Unmarshal(raw, &result); err != nil {
+			v.addError(offset, "", "", "invalid result: %s", err.Error())
+		} else {
+			v.validateResult(offset, result)
+		}
+		if len(v.errors) >= maxReportErrors {
+			return false
+		}
+	}
+
+	return v.expectDelim(']', "results must be an array")
+}
+
+func (v *reportValidator) validateResult(offset int64, result metrics.TestResults) {
+	v.tests++
+	if result.Test == "" {
+		v.addError(offset, "", "", "missing test name")
+
+		return
+	}
+	if file, ok := v.seen[result.Test]; ok {
+		if file == v.file {
+			v.addError(offset, result.Test, "", "duplicate test")
+		} else {
+			v.addError(offset, result.Test, "", "duplicate test, also in %s", file)
+		}
+	} else {
+		v.seen[result.Test] = v.file
+	}
+	if !isKnownStatus(result.Status) {
+		v.addError(offset, result.Test, "", "unknown status %q", result.Status)
+	}
+	subtests := make(map[string]bool, len(result.Subtests))
+	for _, subtest := range result.Subtests {
+		if subtests[subtest.Name] {
+			v.addError(offset, result.Test, subtest.Name, "duplicate subtest")
+		}
+		subtests[subtest.Name] = true
+		if !isKnownStatus(subtest.Status) {
+			v.addError(offset, result.Test, subtest.Name, "unknown status %q", subtest.Status)
+		}
+	}
+}
+
+// isKnownStatus returns whether the given status string is understood by
+// TestStatusValueFromString, which maps unknown strings to TestStatusDefault.
+func isKnownStatus(status string) bool {
+	return shared.TestStatusValueFromString(status) != shared.TestStatusDefault ||
+		status == shared.TestStatusNameUnknown || status == "MISSING"
+}
@@ -0,0 +1,96 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateReport_valid(t *testing.T) {
+	report := `{
+  "results": [
+    {"test": "/a.html", "status": "OK", "message": null, "subtests": [
+      {"name": "first", "status": "PASS", "message": null},
+      {"name": "second", "status": "PRECONDITION_FAILED", "message": "nope"}
+    ]},
+    {"test": "/b.html", "status": "TIMEOUT", "subtests": []}
+  ],
+  "run_info": {"product": "firefox", "revision": "0123456789"},
+  "time_start": 1
+}`
+	tests, product, errs := validateReport("report.json", strings.NewReader(report), map[string]string{})
+	assert.Equal(t, 2, tests)
+	assert.Equal(t, "firefox", product)
+	assert.Empty(t, errs)
+}
+
+func TestValidateReport_statuses(t *testing.T) {
+	report := `{"run_info": {},
+"results": [
+  {"test": "/a.html", "status": "OK", "subtests": [{"name": "s", "status": "PASSED"}]},
+  {"test": "/b.html", "status": "", "subtests": [{"name": "s", "status": "PASS"}, {"name": "s", "status": "FAIL"}]},
+  {"status": "OK", "subtests": []}
+]}`
+	tests, _, errs := validateReport("report.json", strings.NewReader(report), map[string]string{})
+	assert.Equal(t, 3, tests)
+	assert.Equal(t, []ReportError{
+		{File: "report.json", Line: 3, Test: "/a.html", Subtest: "s", Message: `unknown status "PASSED"`},
+		{File: "report.json", Line: 4, Test: "/b.html", Message: `unknown status ""`},
+		{File: "report.json", Line: 4, Test: "/b.html", Subtest: "s", Message: "duplicate subtest"},
+		{File: "report.json", Line: 5, Message: "missing test name"},
+	}, errs)
+}
+
+func TestValidateReport_duplicateTests(t *testing.T) {
+	seen := map[string]string{"/a.html": "other.json"}
+	report := `{"run_info": {}, "results": [
+{"test": "/a.html", "status": "OK", "subtests": []},
+{"test": "/b.html", "status": "OK", "subtests": []},
+{"test": "/b.html", "status": "OK", "subtests": []}]}`
+	_, _, errs := validateReport("report.json", strings.NewReader(report), seen)
+	assert.Equal(t, []ReportError{
+		{File: "report.json", Line: 2, Test: "/a.html", Message: "duplicate test, also in other.json"},
+		{File: "report.json", Line: 4, Test: "/b.html", Message: "duplicate test"},
+	}, errs)
+	assert.Equal(t, "report.json", seen["/b.html"])
+}
+
+func TestValidateReport_missingFields(t *testing.T) {
+	_, _, errs := validateReport("report.json", strings.NewReader(`{"time_start": 1}`), map[string]string{})
+	assert.Equal(t, []ReportError{
+		{File: "report.json", Line: 1, Message: `missing required field "results"`},
+		{File: "report.json", Line: 1, Message: `missing required field "run_info"`},
+	}, errs)
+}
+
+func TestValidateReport_wrongTypes(t *testing.T) {
+	_, _, errs := validateReport("report.json", strings.NewReader(`{"run_info": [], "results": {}}`), map[string]string{})
+	assert.Equal(t, []ReportError{
+		{File: "report.json", Line: 1, Message: "run_info must be an object"},
+		{File: "report.json", Line: 1, Message: "results must be an array"},
+	}, errs)
+
+	_, _, errs = validateReport("report.json", strings.NewReader(`[]`), map[string]string{})
+	assert.Equal(t, []ReportError{{File: "report.json", Line: 1, Message: "report must be a JSON object"}}, errs)
+}
+
+func TestValidateReport_invalidJSON(t *testing.T) {
+	report := `{"run_info": {},
+"results": [
+  {"test": "/a.html", "status": "OK",, "subtests": []}
+]}`
+	_, _, errs := validateReport("report.json", strings.NewReader(report), map[string]string{})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "invalid JSON")
+
+	_, _, errs = validateReport("report.json", strings.NewReader(""), map[string]string{})
+	assert.Equal(t, []ReportError{{File: "report.json", Line: 1, Message: "unexpected end of report"}}, errs)
+}
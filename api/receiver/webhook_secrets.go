@@ -0,0 +1,37 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package receiver
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// ErrNoWebhookSecret is returned when an uploader has no secret to verify the
+// notifications of its incoming webhooks with.
+var ErrNoWebhookSecret = errors.New("no webhook secret is configured")
+
+// WebhookSecretName returns the name of the secret (see shared.GetSecret)
+// which the notifications of the uploader's incoming webhooks (e.g.
+// /api/webhook/generic/{uploader}) are verified with. It is distinct from the
+// uploader's password, which is never sent to, or shared with, a CI system.
+func WebhookSecretName(uploader string) string {
+	return "webhook-secret-" + uploader
+}
+
+// GetWebhookSecret gets the secret of the uploader which the notifications of
+// its incoming webhooks are verified with, or ErrNoWebhookSecret if there is
+// none.
+func (a apiImpl) GetWebhookSecret(uploader string) (string, error) {
+	secret, err := shared.GetSecret(a.store, WebhookSecretName(uploader))
+	// Anyone could sign with an empty secret.
+	if errors.Is(err, shared.ErrNoSuchEntity) || (err == nil && secret == "") {
+		return "", fmt.Errorf("%w for %s", ErrNoWebhookSecret, uploader)
+	}
+
+	return secret, err
+}
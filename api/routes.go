@@ -48,11 +48,28 @@ func RegisterRoutes() {
 		shared.WrapApplicationJSON(
 			shared.WrapPermissiveCORS(apiTestRunHandler)))
 
+	// Admin API endpoints for marking a test run invalid, restoring it, or
+	// changing its labels, and for its audit log of such changes.
+	shared.AddRoute("/api/runs/{id:[0-9]+}/{action:invalidate|restore|labels}", "api-test-run-update",
+		shared.WrapApplicationJSON(
+			shared.WrapTrustedCORS(apiTestRunUpdateHandler, CORSList, []string{"POST"})))
+	shared.AddRoute("/api/runs/{id:[0-9]+}/audit", "api-test-run-audit",
+		shared.WrapApplicationJSON(
+			shared.WrapTrustedCORS(apiTestRunAuditLogHandler, CORSList, nil)))
+
 	// API endpoint for listing pending test runs
 	pendingTestRuns := shared.WrapApplicationJSON(
 		shared.WrapPermissiveCORS(apiPendingTestRunsHandler))
 	shared.AddRoute("/api/status", "api-pending-test-runs", pendingTestRuns)
-	shared.AddRoute("/api/status/{filter:pending|invalid|empty|duplicate}", "api-pending-test-runs", pendingTestRuns)
+	shared.AddRoute("/api/status/{filter:pending|invalid|empty|duplicate|timed_out}", "api-pending-test-runs", pendingTestRuns)
+	shared.AddRoute("/api/status/latency", "api-pending-test-run-latency",
+		shared.WrapApplicationJSON(
+			shared.WrapPermissiveCORS(apiPendingTestRunLatencyHandler)))
+
+	// API endpoint for the runs, pending runs, diffs and check runs of a PR.
+	shared.AddRoute("/api/pr/{number:[0-9]+}", "api-pr",
+		shared.WrapApplicationJSON(
+			shared.WrapPermissiveCORS(apiPRHandler)))
 
 	// API endpoint for redirecting to a run's summary JSON blob.
 	shared.AddRoute("/api/results", "api-results", shared.WrapPermissiveCORS(apiResultsRedirectHandler))
@@ -99,6 +116,13 @@ func RegisterRoutes() {
 		shared.WrapApplicationJSON(shared.WrapPermissiveCORS(apiBSFHandler)),
 	)
 
+	// API endpoint for fetching the interop focus-area config.
+	shared.AddRoute(
+		"/api/interop/config",
+		"api-interop-config",
+		shared.WrapApplicationJSON(shared.WrapPermissiveCORS(apiInteropConfigHandler)),
+	)
+
 	// API endpoint for fetching historical data of a specific test for each of the four major browsers.
 	shared.AddRoute("/api/history", "api-history",
 		shared.WrapApplicationJSON(
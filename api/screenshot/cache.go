@@ -60,9 +60,14 @@ func uploadScreenshotHandler(w http.ResponseWriter, r *http.Request) {
 
 	ctx := r.Context()
 	aeAPI := shared.NewAppEngineAPI(ctx)
-	if receiver.AuthenticateUploader(aeAPI, r) != receiver.InternalUsername {
+	uploader, token := receiver.AuthenticateUploaderToken(aeAPI, r, shared.ScopeUploadScreenshots)
+	if uploader != receiver.InternalUsername {
 		http.Error(w, "This is a private API.", http.StatusUnauthorized)
 
+		return
+	} else if token != nil && !token.AllowsProduct(r.FormValue("browser")) {
+		http.Error(w, "API token is not allowed for this browser", http.StatusForbidden)
+
 		return
 	}
 
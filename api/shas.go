@@ -44,6 +44,9 @@ func (h SHAsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := h.ctx
 	store := shared.NewAppEngineDatastore(ctx, true)
 	q := store.TestRunQuery()
+	if filters.IncludeInvalid != nil && *filters.IncludeInvalid {
+		q = q.IncludeInvalid()
+	}
 
 	var shas []string
 	products := filters.GetProductsOrDefault()
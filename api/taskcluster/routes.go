@@ -10,4 +10,8 @@ import "github.com/web-platform-tests/wpt.fyi/shared"
 func RegisterRoutes() {
 	// GitHub webhook for responding to status updates from Taskcluster
 	shared.AddRoute("/api/webhook/taskcluster", "api-webhook-taskcluster", tcStatusWebhookHandler)
+	// PUBLIC API endpoint for checking how task names are classified by the
+	// task rules (or by the rules POSTed in the body).
+	shared.AddRoute("/api/taskcluster/tasks/classify", "api-taskcluster-tasks-classify", taskClassifyHandler).
+		Methods("GET", "POST")
 }
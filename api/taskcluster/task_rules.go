@@ -0,0 +1,232 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package taskcluster
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// TaskRulesKind is the Datastore kind of the entity which overrides the
+// default task rules, keyed by taskRulesKey.
+const TaskRulesKind = "TaskclusterTaskRules"
+
+const taskRulesKey = "rules"
+
+// TaskRulesPathEnv is the environment variable that, when set, points to task
+// rules on disk which override the embedded default.
+const TaskRulesPathEnv = "TASKCLUSTER_TASK_RULES_PATH"
+
+// maxTaskRulesSize is the maximum size of task rules posted for validation.
+const maxTaskRulesSize = 1 << 20
+
+//go:embed task_rules.yml
+var defaultTaskRules []byte
+
+// TaskRule classifies the tasks whose names match its pattern.
+type TaskRule struct {
+	Name string `yaml:"name" json:"name"`
+	// Pattern is a regular expression matched against task names.
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Browser is the browser name of the tasks, which defaults to the group
+	// of the pattern named browser.
+	Browser string `yaml:"browser,omitempty" json:"browser,omitempty"`
+	// Channel is the channel label of the tasks, which defaults to the group
+	// of the pattern named channel.
+	Channel string `yaml:"channel,omitempty" json:"channel,omitempty"`
+	// Labels are extra labels of the runs of the tasks, e.g. pr_head.
+	Labels []string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// Ignore skips the tasks.
+	Ignore bool `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+
+	regex *regexp.Regexp
+}
+
+// TaskRules are rules classifying tasks, in order of precedence.
+type TaskRules []TaskRule
+
+// taskRulesConfig is the YAML config of TaskRules.
+type taskRulesConfig struct {
+	Rules TaskRules `yaml:"rules"`
+}
+
+// taskRulesEntity is the Datastore entity of TaskRules, which holds their
+// YAML config.
+type taskRulesEntity struct {
+	YAML string `datastore:",noindex"`
+}
+
+// TaskClassification is how a task is classified by TaskRules.
+type TaskClassification struct {
+	Task string `json:"task"`
+	// Rule is the name of the rule which applies to the task, if any.
+	Rule    string   `json:"rule,omitempty"`
+	Ignored bool     `json:"ignored"`
+	Browser string   `json:"browser,omitempty"`
+	Channel string   `json:"channel,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	// Product identifies the run which the results of the task are uploaded
+	// to, e.g. chrome-dev-pr_head.
+	Product string `json:"product,omitempty"`
+}
+
+// ParseTaskRules parses and validates YAML task rules.
+func ParseTaskRules(data []byte) (TaskRules, error) {
+	var config taskRulesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if len(config.Rules) == 0 {
+		return nil, errors.New("no rules")
+	}
+	names := make(map[string]bool)
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i)
+		} else if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q has an invalid pattern: %w", rule.Name, err)
+		}
+		rule.regex = regex
+		if !rule.Ignore && rule.Browser == "" && regex.SubexpIndex("browser") < 0 {
+			return nil, fmt.Errorf("rule %q has no browser, nor a group named browser", rule.Name)
+		}
+	}
+
+	return config.Rules, nil
+}
+
+// DefaultTaskRules returns the task rules at TaskRulesPathEnv if set, or the
+// embedded default rules otherwise.
+func DefaultTaskRules() (TaskRules, error) {
+	if path := os.Getenv(TaskRulesPathEnv); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return ParseTaskRules(data)
+	}
+
+	return ParseTaskRules(defaultTaskRules)
+}
+
+// LoadTaskRules loads the task rules from Datastore, falling back to
+// DefaultTaskRules if they aren't configured there.
+func LoadTaskRules(store shared.Datastore) (TaskRules, error) {
+	var entity taskRulesEntity
+	err := store.Get(store.NewNameKey(TaskRulesKind, taskRulesKey), &entity)
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		return DefaultTaskRules()
+	} else if err != nil {
+		return nil, err
+	}
+	rules, err := ParseTaskRules([]byte(entity.YAML))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", TaskRulesKind, err)
+	}
+
+	return rules, nil
+}
+
+// Classify classifies the task with the given name by the first rule which
+// matches it. Tasks which match no rule are ignored.
+func (rules TaskRules) Classify(task string) TaskClassification {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	c := TaskClassification{Task: task, Ignored: true}
+	for _, rule := range rules {
+		match := rule.regex.FindStringSubmatchIndex(task)
+		if match == nil {
+			continue
+		}
+		c.Rule = rule.Name
+		if rule.Ignore {
+			return c
+		}
+		expand := func(template, group string) string {
+			if template == "" && group != "" {
+				template = "${" + group + "}"
+			}
+
+			return string(rule.regex.ExpandString(nil, template, task, match))
+		}
+		c.Browser = expand(rule.Browser, "browser")
+		c.Channel = expand(rule.Channel, "channel")
+		for _, label := range rule.Labels {
+			if label = expand(label, ""); label != "" {
+				c.Labels = append(c.Labels, label)
+			}
+		}
+		if c.Browser == "" {
+			// The browser group is optional in the pattern.
+			return c
+		}
+		c.Ignored = false
+		product := []string{c.Browser}
+		if c.Channel != "" {
+			product = append(product, c.Channel)
+		}
+		c.Product = strings.Join(append(product, c.Labels...), "-")
+
+		return c
+	}
+
+	return c
+}
+
+// taskClassifications is the response of HandleTaskClassify.
+type taskClassifications struct {
+	Rules TaskRules            `json:"rules"`
+	Tasks []TaskClassification `json:"tasks"`
+}
+
+// HandleTaskClassify responds with the classification of the tasks given by
+// the "task" params. The tasks are classified by the current task rules, or
+// by the YAML rules in the body of a POST request, which are validated.
+func HandleTaskClassify(store shared.Datastore, w http.ResponseWriter, r *http.Request) {
+	var rules TaskRules
+	var err error
+	if r.Method == http.MethodPost {
+		var data []byte
+		if data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxTaskRulesSize)); err == nil {
+			rules, err = ParseTaskRules(data)
+		}
+		if err != nil {
+			http.Error(w, "Invalid task rules: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	} else if rules, err = LoadTaskRules(store); err != nil {
+		shared.GetLogger(store.Context()).Errorf("Failed to load task rules: %s", err.Error())
+		http.Error(w, "Failed to load task rules: "+err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	response := taskClassifications{Rules: rules, Tasks: []TaskClassification{}}
+	for _, task := range r.URL.Query()["task"] {
+		response.Tasks = append(response.Tasks, rules.Classify(task))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		shared.GetLogger(store.Context()).Errorf("Failed to encode task classifications: %s", err.Error())
+	}
+}
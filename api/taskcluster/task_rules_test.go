@@ -0,0 +1,193 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package taskcluster
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+const customTaskRules = `
+rules:
+  - name: servo
+    pattern: '^servo-(?P<suite>[a-z]+)$'
+    browser: servo
+    channel: nightly
+    labels: [experimental, '${suite}']
+`
+
+func TestDefaultTaskRules_Classify(t *testing.T) {
+	rules, err := DefaultTaskRules()
+	assert.Nil(t, err)
+
+	for _, c := range []struct {
+		task    string
+		product string
+	}{
+		{"wpt-chrome-dev-results", "chrome-dev-pr_head"},
+		{"wpt-chrome-dev-results-without-changes", "chrome-dev-pr_base"},
+		{"wpt-chrome-stable-reftest-1", "chrome-stable"},
+		{"wpt-firefox-beta-crashtest-2", "firefox-beta"},
+		{"wpt-firefox-nightly-testharness-5", "firefox-nightly"},
+		{"wpt-firefox-stable-wdspec-1", "firefox-stable"},
+		{"wpt-chrome-dev-test262-1", "chrome-dev"},
+		{"wpt-webkitgtk_minibrowser-nightly-testharness-2", "webkitgtk_minibrowser-nightly"},
+		{"wpt-wpewebkit_minibrowser-nightly-testharness-2", "wpewebkit_minibrowser-nightly"},
+		{"infrastructure/ tests (firefox_android)", "firefox_android"},
+	} {
+		t.Run(c.task, func(t *testing.T) {
+			classification := rules.Classify(c.task)
+			assert.False(t, classification.Ignored)
+			assert.Equal(t, c.product, classification.Product)
+		})
+	}
+
+	classification := rules.Classify("wpt-chrome-dev-results")
+	assert.Equal(t, TaskClassification{
+		Task:    "wpt-chrome-dev-results",
+		Rule:    "pr-head",
+		Browser: "chrome",
+		Channel: "dev",
+		Labels:  []string{shared.PRHeadLabel},
+		Product: "chrome-dev-pr_head",
+	}, classification)
+
+	classification = rules.Classify("wpt-chrome-dev-stability")
+	assert.True(t, classification.Ignored)
+	assert.Equal(t, "stability", classification.Rule)
+
+	for _, task := range []string{"wpt-foo-bar--1", "wpt-foo-bar-", "lint"} {
+		classification = rules.Classify(task)
+		assert.True(t, classification.Ignored)
+		assert.Equal(t, "", classification.Rule)
+	}
+}
+
+func TestParseTaskRules_custom(t *testing.T) {
+	rules, err := ParseTaskRules([]byte(customTaskRules))
+	assert.Nil(t, err)
+	classification := rules.Classify("servo-reftest")
+	assert.Equal(t, "servo", classification.Browser)
+	assert.Equal(t, "nightly", classification.Channel)
+	assert.Equal(t, []string{"experimental", "reftest"}, classification.Labels)
+	assert.Equal(t, "servo-nightly-experimental-reftest", classification.Product)
+}
+
+func TestParseTaskRules_invalid(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		rules string
+		err   string
+	}{
+		{"empty", "rules: []", "no rules"},
+		{"no name", "rules: [{pattern: '^a$', browser: a}]", "rule 0 has no name"},
+		{"duplicate", "rules: [{name: a, pattern: '^a$', browser: a}, {name: a, pattern: '^b$', browser: b}]",
+			`duplicate rule "a"`},
+		{"pattern", "rules: [{name: a, pattern: '^(a$', browser: a}]", `rule "a" has an invalid pattern`},
+		{"browser", "rules: [{name: a, pattern: '^a$'}]", `rule "a" has no browser`},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseTaskRules([]byte(c.rules))
+			assert.ErrorContains(t, err, c.err)
+		})
+	}
+
+	// Ignore rules need no browser.
+	_, err := ParseTaskRules([]byte("rules: [{name: a, pattern: '^a$', ignore: true}]"))
+	assert.Nil(t, err)
+}
+
+func TestDefaultTaskRules_path(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task_rules.yml")
+	assert.Nil(t, os.WriteFile(path, []byte(customTaskRules), 0600))
+	t.Setenv(TaskRulesPathEnv, path)
+
+	rules, err := DefaultTaskRules()
+	assert.Nil(t, err)
+	assert.Len(t, rules, 1)
+	assert.Equal(t, "servo", rules[0].Name)
+}
+
+func expectTaskRulesEntity(store *sharedtest.MockDatastore, yaml string, err error) {
+	key := &sharedtest.MockKey{Name: taskRulesKey, TypeName: TaskRulesKind}
+	store.EXPECT().NewNameKey(TaskRulesKind, taskRulesKey).Return(key)
+	store.EXPECT().Get(key, gomock.Any()).DoAndReturn(func(_ shared.Key, dst interface{}) error {
+		dst.(*taskRulesEntity).YAML = yaml
+
+		return err
+	})
+}
+
+func TestLoadTaskRules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	store := sharedtest.NewMockDatastore(mockCtrl)
+
+	expectTaskRulesEntity(store, customTaskRules, nil)
+	rules, err := LoadTaskRules(store)
+	assert.Nil(t, err)
+	assert.Equal(t, "servo", rules[0].Name)
+
+	expectTaskRulesEntity(store, "", shared.ErrNoSuchEntity)
+	rules, err = LoadTaskRules(store)
+	assert.Nil(t, err)
+	assert.Equal(t, "stability", rules[0].Name)
+
+	expectTaskRulesEntity(store, "rules: []", nil)
+	_, err = LoadTaskRules(store)
+	assert.ErrorContains(t, err, "invalid TaskclusterTaskRules: no rules")
+
+	expectTaskRulesEntity(store, "", errors.New("unavailable"))
+	_, err = LoadTaskRules(store)
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestHandleTaskClassify(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	store.EXPECT().Context().AnyTimes().Return(sharedtest.NewTestContext())
+
+	expectTaskRulesEntity(store, "", shared.ErrNoSuchEntity)
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet,
+		"/api/taskcluster/tasks/classify?task=wpt-chrome-dev-results&task=servo-reftest", nil)
+	HandleTaskClassify(store, w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response taskClassifications
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Rules, 5)
+	assert.Len(t, response.Tasks, 2)
+	assert.Equal(t, "chrome-dev-pr_head", response.Tasks[0].Product)
+	assert.True(t, response.Tasks[1].Ignored)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost,
+		"/api/taskcluster/tasks/classify?task=servo-reftest", strings.NewReader(customTaskRules))
+	HandleTaskClassify(store, w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "servo-nightly-experimental-reftest", response.Tasks[0].Product)
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost,
+		"/api/taskcluster/tasks/classify?task=servo-reftest", strings.NewReader("rules: []"))
+	HandleTaskClassify(store, w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
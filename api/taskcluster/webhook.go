@@ -13,14 +13,15 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
-	"sync"
 
 	mapset "github.com/deckarep/golang-set"
 	"github.com/google/go-github/v90/github"
 	tcurls "github.com/taskcluster/taskcluster-lib-urls"
 	"github.com/taskcluster/taskcluster/v103/clients/client-go/tcqueue"
-	uc "github.com/web-platform-tests/wpt.fyi/api/receiver/client"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/receiver"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 )
 
@@ -31,15 +32,6 @@ const uploaderName = "taskcluster"
 const completedState = "completed"
 
 var (
-	// TaskNameRegex is based on task names in
-	// https://github.com/web-platform-tests/wpt/blob/master/tools/ci/tc/tasks/test.yml.
-	TaskNameRegex = regexp.MustCompile(`^wpt-([a-z_]+-[a-z]+)-([a-z]+(?:-[a-z]+)*|test262)(?:-\d+)?$`)
-	// infraTaskNameRegex matches the wptrunner infrastructure smoketest tasks,
-	// also defined in test.yml, e.g. "infrastructure/ tests (chrome)". Unlike
-	// TaskNameRegex there is no channel/suite segment: each browser currently
-	// only runs infra tests on a single channel, so the browser name alone is
-	// a sufficiently unique product key.
-	infraTaskNameRegex = regexp.MustCompile(`^infrastructure/ tests \(([a-z_]+)\)$`)
 	// Taskcluster has used different forms of URLs in their Check & Status
 	// updates in history. We accept all of them.
 	// See TestExtractTaskGroupID for examples.
@@ -49,7 +41,7 @@ var (
 )
 
 // Non-fatal error when there is no result (e.g. nothing finishes yet).
-var errNoResults = errors.New("no result URLs found in task group")
+var errNoResults = fmt.Errorf("no result URLs found in task group: %w", ci.ErrNoResults)
 
 // TaskInfo is an abstraction of a Taskcluster task, containing the necessary
 // information for us to process the task in wpt.fyi.
@@ -216,132 +208,195 @@ func GetCheckSuiteEventInfo(checkSuite github.CheckSuiteEvent, log shared.Logger
 
 // tcStatusWebhookHandler reacts to GitHub status webhook events.
 func tcStatusWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p := provider{aeAPI: shared.NewAppEngineAPI(ctx)}
+	ci.HandleNotification(receiver.NewAPI(ctx), p, w, r)
+}
+
+// taskClassifyHandler shows how tasks are classified by the task rules.
+func taskClassifyHandler(w http.ResponseWriter, r *http.Request) {
+	HandleTaskClassify(shared.NewAppEngineDatastore(r.Context(), false), w, r)
+}
+
+// provider is the ci.CIProvider of Taskcluster task groups, which are notified
+// by GitHub status and check_suite webhook events.
+type provider struct {
+	aeAPI shared.AppEngineAPI
+}
+
+// taskGroupEvent is the Taskcluster-specific information of a ci.Build: the
+// event, and the task rules its tasks are classified by.
+type taskGroupEvent struct {
+	EventInfo
+
+	rules TaskRules
+}
+
+func (p provider) Uploader() string {
+	return uploaderName
+}
+
+func (p provider) ParseEvent(r *http.Request) (*ci.Build, error) {
 	eventName := r.Header.Get("X-GitHub-Event")
 	if r.Header.Get("Content-Type") != "application/json" || (eventName != "status" && eventName != "check_suite") {
-		w.WriteHeader(http.StatusBadRequest)
-
-		return
+		return nil, ci.NewEventError(http.StatusBadRequest, "unexpected %s event", eventName)
 	}
 
-	ctx := r.Context()
+	ctx := p.aeAPI.Context()
 	ds := shared.NewAppEngineDatastore(ctx, false)
 	secret, err := shared.GetSecret(ds, "github-tc-webhook-secret")
 	if err != nil {
-		http.Error(w, "Unable to verify request: secret not found", http.StatusInternalServerError)
-
-		return
+		return nil, errors.New("unable to verify request: secret not found")
 	}
 
 	log := shared.GetLogger(ctx)
 	log.Debugf("Retrieved GitHub secret from datastore")
+	rules, err := LoadTaskRules(ds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load task rules: %w", err)
+	}
 
 	payload, err := github.ValidatePayload(r, []byte(secret))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
-
-		return
+		return nil, ci.EventError{Status: http.StatusUnauthorized, Err: err}
 	}
 	log.Debugf("Payload validated against secret")
 
 	log.Debugf("GitHub Delivery: %s", r.Header.Get("X-GitHub-Delivery"))
 
-	aeAPI := shared.NewAppEngineAPI(ctx)
-
-	ghClient, err := aeAPI.GetGitHubClient()
+	ghClient, err := p.aeAPI.GetGitHubClient()
 	if err != nil {
-		log.Errorf("Failed to get GitHub client: %s", err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-
-		return
+		return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 	}
-	api := apiImpl{ctx: aeAPI.Context(), ghClient: ghClient}
+	api := apiImpl{ctx: ctx, ghClient: ghClient}
 
 	var event EventInfo
 	// nolint:nestif // TODO: Fix nestif lint error
 	if eventName == "status" {
 		var status StatusEventPayload
 		if err := json.Unmarshal(payload, &status); err != nil {
-			log.Errorf("%v", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
-
-			return
+			return nil, ci.EventError{Status: http.StatusBadRequest, Err: err}
 		}
 
 		if !ShouldProcessStatus(log, &status) {
-			w.WriteHeader(http.StatusNoContent)
-			fmt.Fprintln(w, "Status was ignored")
-
-			return
+			return nil, fmt.Errorf("status was ignored: %w", ci.ErrIgnored)
 		}
 
 		event, err = GetStatusEventInfo(status, log, api)
 	} else {
 		var checkSuite github.CheckSuiteEvent
 		if err := json.Unmarshal(payload, &checkSuite); err != nil {
-			log.Errorf("%v", err)
-			http.Error(w, err.Error(), http.StatusBadRequest)
-
-			return
+			return nil, ci.EventError{Status: http.StatusBadRequest, Err: err}
 		}
 
 		if checkSuite.GetCheckSuite().GetApp().GetID() != AppID {
-			log.Debugf("Ignoring non-Taskcluster app: %s (%d)",
+			return nil, fmt.Errorf("non-Taskcluster app %s (%d): %w",
 				checkSuite.GetCheckSuite().GetApp().GetName(),
-				checkSuite.GetCheckSuite().GetApp().GetID())
-			w.WriteHeader(http.StatusNoContent)
-			fmt.Fprintln(w, "Status was ignored")
-
-			return
+				checkSuite.GetCheckSuite().GetApp().GetID(),
+				ci.ErrIgnored)
 		}
 
 		// As a webhook we should only receive completed check_suite events, as per
 		// https://developer.github.com/webhooks/event-payloads/#check_suite
 		if checkSuite.GetAction() != completedState || checkSuite.GetCheckSuite().GetStatus() != completedState {
-			log.Errorf("Received non-completed check_suite event (action: %s, status: %s)",
+			return nil, ci.NewEventError(http.StatusBadRequest,
+				"non-completed check_suite event (action: %s, status: %s)",
 				checkSuite.GetAction(), checkSuite.GetCheckSuite().GetStatus())
-			http.Error(w, "Non-completed check_suite event", http.StatusBadRequest)
-
-			return
 		}
 
 		event, err = GetCheckSuiteEventInfo(checkSuite, log, api)
 	}
 	if err != nil {
-		log.Errorf("%v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+	if event.TaskID != "" {
+		log.Debugf("Taskcluster task %s", event.TaskID)
+	}
+
+	return &ci.Build{
+		ID:     event.Group.TaskGroupID,
+		Owner:  shared.WPTRepoOwner,
+		Repo:   shared.WPTRepoName,
+		SHA:    event.Sha,
+		Sender: event.Sender,
+		Event:  taskGroupEvent{EventInfo: event, rules: rules},
+	}, nil
+}
 
-		return
+// ListArtifacts lists the results of each product in the task group, named
+// after the product (e.g. chrome-dev-pr_head).
+func (p provider) ListArtifacts(b *ci.Build) ([]ci.Artifact, error) {
+	event := b.Event.(taskGroupEvent)
+	urlsByProduct, err := ExtractArtifactURLs(
+		event.RootURL, shared.GetLogger(p.aeAPI.Context()), event.Group, event.TaskID, event.rules)
+	if err != nil {
+		return nil, err
 	}
 
-	labels := mapset.NewSet()
-	if event.Master {
-		labels.Add(shared.MasterLabel)
+	artifacts := make([]ci.Artifact, 0, len(urlsByProduct))
+	for product, urls := range urlsByProduct {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		artifacts = append(artifacts, ci.Artifact{
+			Name:        product,
+			Results:     urls.Results,
+			Screenshots: urls.Screenshots,
+		})
 	}
-	if event.Sender != "" {
-		labels.Add(shared.GetUserLabel(event.Sender))
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Name < artifacts[j].Name })
+
+	return artifacts, nil
+}
+
+// ProductAndLabels classifies the tasks of the group again, to find those of
+// the product of the artifact.
+func (p provider) ProductAndLabels(b *ci.Build, artifact ci.Artifact) (shared.ProductAtRevision, []string) {
+	event := b.Event.(taskGroupEvent)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	classification := TaskClassification{Product: artifact.Name}
+	for _, task := range event.Group.Tasks {
+		if c := event.rules.Classify(task.Name); c.Product == artifact.Name {
+			classification = c
+
+			break
+		}
 	}
 
-	processed, err := processTaskclusterBuild(aeAPI, event, shared.ToStringSlice(labels)...)
+	return ProductAndLabels(event.EventInfo, classification)
+}
 
-	if errors.Is(err, errNoResults) {
-		log.Infof("%v", err)
-		http.Error(w, err.Error(), http.StatusNoContent)
+func (p provider) ReportStatus(w http.ResponseWriter, _ *ci.Build, _ []shared.PendingTestRun) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "Taskcluster tasks were sent to results receiver")
+}
 
-		return
+// ProductAndLabels returns the product and labels of the run of the tasks of
+// the event with the given classification.
+func ProductAndLabels(event EventInfo, task TaskClassification) (shared.ProductAtRevision, []string) {
+	labels := mapset.NewSet()
+	if event.Sender != "" {
+		labels.Add(shared.GetUserLabel(event.Sender))
+	}
+	if task.Channel != "" {
+		labels.Add(task.Channel)
 	}
-	if err != nil {
-		log.Errorf("%v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
 
-		return
+	isPR := false
+	for _, label := range task.Labels {
+		labels.Add(label)
+		isPR = isPR || label == shared.PRBaseLabel || label == shared.PRHeadLabel
 	}
-	if processed {
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, "Taskcluster tasks were sent to results receiver")
-	} else {
-		w.WriteHeader(http.StatusNoContent)
-		fmt.Fprintln(w, "Status was ignored")
+	// We have seen cases where Community-TC triggers a pull request for
+	// merged commits. To guard against that, we don't add the master label
+	// to the runs of pull requests.
+	if event.Master && !isPR {
+		labels.Add(shared.MasterLabel)
 	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	return shared.ProductAtRevision{
+		Product:          shared.Product{BrowserName: task.Browser},
+		FullRevisionHash: event.Sha,
+	}, shared.ToStringSlice(labels)
 }
 
 // StatusEventPayload wraps a github.StatusEvent so we can declare methods on it
@@ -372,41 +427,6 @@ func (s StatusEventPayload) IsOnMaster() bool {
 	return false
 }
 
-func processTaskclusterBuild(aeAPI shared.AppEngineAPI, event EventInfo, labels ...string) (bool, error) {
-	ctx := aeAPI.Context()
-	log := shared.GetLogger(ctx)
-
-	if event.TaskID != "" {
-		log.Debugf("Taskcluster task %s", event.TaskID)
-	}
-
-	urlsByProduct, err := ExtractArtifactURLs(event.RootURL, log, event.Group, event.TaskID)
-	if err != nil {
-		return false, err
-	}
-
-	uploader, err := aeAPI.GetUploader(uploaderName)
-	if err != nil {
-		log.Errorf("Failed to get uploader creds from Datastore")
-
-		return false, err
-	}
-
-	err = CreateAllRuns(
-		log,
-		shared.NewAppEngineAPI(ctx),
-		event.Sha,
-		uploader.Username,
-		uploader.Password,
-		urlsByProduct,
-		labels)
-	if err != nil {
-		return false, err
-	}
-
-	return true, nil
-}
-
 // ShouldProcessStatus determines whether we are interested in processing a
 // given StatusEventPayload or not.
 func ShouldProcessStatus(log shared.Logger, status *StatusEventPayload) bool {
@@ -521,8 +541,8 @@ type ArtifactURLs struct {
 }
 
 // ExtractArtifactURLs extracts the results and screenshot URLs for a set of
-// tasks in a TaskGroupInfo.
-func ExtractArtifactURLs(rootURL string, log shared.Logger, group *TaskGroupInfo, taskID string) (
+// tasks in a TaskGroupInfo, by the product the rules classify them as.
+func ExtractArtifactURLs(rootURL string, log shared.Logger, group *TaskGroupInfo, taskID string, rules TaskRules) (
 	urlsByProduct map[string]ArtifactURLs, err error) {
 	urlsByProduct = make(map[string]ArtifactURLs)
 	failures := mapset.NewSet()
@@ -537,27 +557,17 @@ func ExtractArtifactURLs(rootURL string, log shared.Logger, group *TaskGroupInfo
 			continue
 		}
 
-		var product string
-		if matches := TaskNameRegex.FindStringSubmatch(task.Name); len(matches) == 3 {
-			// full match, browser-channel, test type
-			product = matches[1]
-			switch matches[2] {
-			case "stability":
-				// Skip stability checks.
-				continue
-			case "results":
-				product += "-" + shared.PRHeadLabel
-			case "results-without-changes":
-				product += "-" + shared.PRBaseLabel
+		classification := rules.Classify(task.Name)
+		if classification.Ignored {
+			if classification.Rule == "" {
+				log.Infof("Ignoring unrecognized task: %s", task.Name)
+			} else {
+				log.Debugf("Ignoring task %s by rule %s", task.Name, classification.Rule)
 			}
-		} else if matches := infraTaskNameRegex.FindStringSubmatch(task.Name); len(matches) == 2 {
-			// full match, browser
-			product = matches[1]
-		} else {
-			log.Infof("Ignoring unrecognized task: %s", task.Name)
 
 			continue
 		}
+		product := classification.Product
 
 		if task.State != completedState {
 			log.Infof("Task group %s has a non-successful task: %s; %s will be ignored in this group.",
@@ -593,54 +603,3 @@ func ExtractArtifactURLs(rootURL string, log shared.Logger, group *TaskGroupInfo
 
 	return urlsByProduct, nil
 }
-
-// CreateAllRuns creates run entries in wpt.fyi for a set of products coming
-// from Taskcluster.
-func CreateAllRuns(
-	log shared.Logger,
-	aeAPI shared.AppEngineAPI,
-	sha,
-	username,
-	password string,
-	urlsByProduct map[string]ArtifactURLs,
-	labels []string) error {
-	errors := make(chan error, len(urlsByProduct))
-	var wg sync.WaitGroup
-	wg.Add(len(urlsByProduct))
-	for product, urls := range urlsByProduct {
-		go func(product string, urls ArtifactURLs) {
-			defer wg.Done()
-			log.Infof("Reports for %s: %v", product, urls)
-
-			// chrome-dev-pr_head => [chrome, dev, pr_head]
-			bits := strings.Split(product, "-")
-			labelsForRun := labels
-			switch lastBit := bits[len(bits)-1]; lastBit {
-			case shared.PRBaseLabel, shared.PRHeadLabel:
-				// We have seen cases where Community-TC triggers a pull request
-				// for merged commits. To guard against that, we strip the
-				// master label here.
-				for i, label := range labelsForRun {
-					if label == shared.MasterLabel {
-						labelsForRun = append(labelsForRun[:i], labelsForRun[i+1:]...)
-
-						break
-					}
-				}
-				labelsForRun = append(labelsForRun, lastBit)
-			}
-
-			uploadClient := uc.NewClient(aeAPI)
-			err := uploadClient.CreateRun(sha, username, password, urls.Results, urls.Screenshots, nil, labelsForRun)
-			if err != nil {
-				errors <- err
-
-				return
-			}
-		}(product, urls)
-	}
-	wg.Wait()
-	close(errors)
-
-	return shared.NewMultiErrorFromChan(errors, "sending Taskcluster runs to results receiver")
-}
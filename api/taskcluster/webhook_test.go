@@ -9,27 +9,26 @@ package taskcluster_test
 import (
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/http/httptest"
-	"net/url"
-	"strings"
-	"sync/atomic"
 	"testing"
-	"time"
 
 	"github.com/google/go-github/v90/github"
 	"github.com/stretchr/testify/assert"
-	uc "github.com/web-platform-tests/wpt.fyi/api/receiver/client"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
 	tc "github.com/web-platform-tests/wpt.fyi/api/taskcluster"
 	mock_tc "github.com/web-platform-tests/wpt.fyi/api/taskcluster/mock_taskcluster"
 	"github.com/web-platform-tests/wpt.fyi/shared"
-	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
 	"go.uber.org/mock/gomock"
 )
 
 type branchInfos []*github.Branch
 
+func defaultRules(t *testing.T) tc.TaskRules {
+	rules, err := tc.DefaultTaskRules()
+	assert.Nil(t, err)
+
+	return rules
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -136,7 +135,7 @@ func TestExtractArtifactURLs_all_success_master(t *testing.T) {
 	}
 
 	t.Run("All", func(t *testing.T) {
-		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "")
+		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
 		assert.Nil(t, err)
 		assert.Equal(t, map[string]tc.ArtifactURLs{
 			"firefox-nightly": {
@@ -167,7 +166,7 @@ func TestExtractArtifactURLs_all_success_master(t *testing.T) {
 	})
 
 	t.Run("Filtered", func(t *testing.T) {
-		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "0")
+		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "0", defaultRules(t))
 		assert.Nil(t, err)
 		assert.Equal(t, map[string]tc.ArtifactURLs{
 			"firefox-nightly": {
@@ -193,7 +192,7 @@ func TestExtractArtifactURLs_all_success_pr(t *testing.T) {
 	}
 
 	t.Run("All", func(t *testing.T) {
-		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "")
+		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
 		assert.Nil(t, err)
 		assert.Equal(t, map[string]tc.ArtifactURLs{
 			"chrome-dev-pr_head": {
@@ -216,7 +215,7 @@ func TestExtractArtifactURLs_all_success_pr(t *testing.T) {
 	})
 
 	t.Run("Filtered", func(t *testing.T) {
-		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "2")
+		urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "2", defaultRules(t))
 		assert.Nil(t, err)
 		assert.Equal(t, map[string]tc.ArtifactURLs{
 			"chrome-dev-pr_base": {
@@ -243,7 +242,7 @@ func TestExtractArtifactURLs_with_failures(t *testing.T) {
 	group.Tasks[2].TaskID = "baz"
 	group.Tasks[2].Name = "wpt-chrome-dev-testharness-1"
 
-	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "")
+	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(urls))
 	assert.Contains(t, urls, "chrome-dev")
@@ -259,7 +258,7 @@ func TestExtractArtifactURLs_infrastructure(t *testing.T) {
 		group.Tasks[i].TaskID = fmt.Sprint(i)
 	}
 
-	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "")
+	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
 	assert.Nil(t, err)
 	assert.Equal(t, map[string]tc.ArtifactURLs{
 		"chrome": {
@@ -298,201 +297,51 @@ func TestExtractArtifactURLs_infrastructure_with_failure(t *testing.T) {
 	group.Tasks[1].State = "completed"
 	group.Tasks[1].TaskID = "bar"
 
-	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "")
+	urls, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(urls))
 	assert.Contains(t, urls, "firefox")
 }
 
-func TestCreateAllRuns_success(t *testing.T) {
-	var requested uint32
-	requested = 0
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		atomic.AddUint32(&requested, 1)
-		w.Write([]byte("OK"))
-	}
-	server := httptest.NewServer(http.HandlerFunc(handler))
-	defer server.Close()
-	serverURL, _ := url.Parse(server.URL)
-	sha := "abcdef1234abcdef1234abcdef1234abcdef1234"
-
-	mockC := gomock.NewController(t)
-	defer mockC.Finish()
-	aeAPI := sharedtest.NewMockAppEngineAPI(mockC)
-	aeAPI.EXPECT().GetVersionedHostname().AnyTimes().Return("localhost:8080")
-	aeAPI.EXPECT().GetHTTPClientWithTimeout(uc.UploadTimeout).AnyTimes().Return(server.Client())
-	aeAPI.EXPECT().GetResultsUploadURL().AnyTimes().Return(serverURL)
-
-	t.Run("master", func(t *testing.T) {
-		err := tc.CreateAllRuns(
-			shared.NewNilLogger(),
-			aeAPI,
-			sha,
-			"username",
-			"password",
-			map[string]tc.ArtifactURLs{
-				"safari-preview": {Results: []string{"1"}},
-				"chrome-dev":     {Results: []string{"1"}},
-				"firefox-stable": {Results: []string{"1", "2"}},
-			},
-			[]string{shared.MasterLabel, "user:person"},
-		)
-		assert.Nil(t, err)
-		assert.Equal(t, uint32(3), requested)
-	})
+func TestExtractArtifactURLs_no_results(t *testing.T) {
+	group := &tc.TaskGroupInfo{Tasks: make([]tc.TaskInfo, 1)}
+	group.Tasks[0].State = "failed"
+	group.Tasks[0].TaskID = "foo"
+	group.Tasks[0].Name = "wpt-firefox-nightly-testharness-1"
 
-	requested = 0
-	t.Run("PR", func(t *testing.T) {
-		err := tc.CreateAllRuns(
-			shared.NewNilLogger(),
-			aeAPI,
-			sha,
-			"username",
-			"password",
-			map[string]tc.ArtifactURLs{
-				"chrome-dev-pr_head":     {Results: []string{"1"}},
-				"chrome-dev-pr_base":     {Results: []string{"1"}},
-				"firefox-stable-pr_head": {Results: []string{"1"}},
-				"firefox-stable-pr_base": {Results: []string{"1"}},
-			},
-			nil,
-		)
-		assert.Nil(t, err)
-		assert.Equal(t, uint32(4), requested)
-	})
+	_, err := tc.ExtractArtifactURLs("https://tc.example.com", shared.NewNilLogger(), group, "", defaultRules(t))
+	assert.ErrorIs(t, err, ci.ErrNoResults)
 }
 
-func TestCreateAllRuns_one_error(t *testing.T) {
-	var requested uint32
-	requested = 0
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		if atomic.CompareAndSwapUint32(&requested, 0, 1) {
-			w.Write([]byte("OK"))
-		} else if atomic.CompareAndSwapUint32(&requested, 1, 2) {
-			http.Error(w, "Not found", http.StatusNotFound)
-		} else {
-			assert.FailNow(t, "requested != 0 && requested != 1")
-		}
-	}
-	server := httptest.NewServer(http.HandlerFunc(handler))
-	defer server.Close()
-	mockC := gomock.NewController(t)
-	defer mockC.Finish()
-
+func TestProductAndLabels(t *testing.T) {
 	sha := "abcdef1234abcdef1234abcdef1234abcdef1234"
+	event := tc.EventInfo{Sha: sha, Master: true, Sender: "person"}
+	rules := defaultRules(t)
 
-	aeAPI := sharedtest.NewMockAppEngineAPI(mockC)
-	aeAPI.EXPECT().GetVersionedHostname().MinTimes(1).Return("localhost:8080")
-	aeAPI.EXPECT().GetHTTPClientWithTimeout(uc.UploadTimeout).Times(2).Return(server.Client())
-	serverURL, _ := url.Parse(server.URL)
-	aeAPI.EXPECT().GetResultsUploadURL().AnyTimes().Return(serverURL)
-
-	err := tc.CreateAllRuns(
-		shared.NewNilLogger(),
-		aeAPI,
-		sha,
-		"username",
-		"password",
-		map[string]tc.ArtifactURLs{
-			"chrome":  {Results: []string{"1"}},
-			"firefox": {Results: []string{"1", "2"}},
-		},
-		[]string{shared.MasterLabel},
-	)
-	assert.NotNil(t, err)
-	assert.Equal(t, uint32(2), requested)
-	assert.Contains(t, err.Error(), "API error:")
-	assert.Contains(t, err.Error(), "404")
-}
+	product, labels := tc.ProductAndLabels(event, rules.Classify("wpt-chrome-dev-testharness-1"))
+	assert.Equal(t, "chrome", product.BrowserName)
+	assert.Equal(t, sha, product.FullRevisionHash)
+	assert.ElementsMatch(t, []string{"dev", shared.MasterLabel, "user:person"}, labels)
 
-func TestCreateAllRuns_all_errors(t *testing.T) {
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(time.Second * 2)
-	}
-	server := httptest.NewServer(http.HandlerFunc(handler))
-	defer server.Close()
-	mockC := gomock.NewController(t)
-	defer mockC.Finish()
-
-	sha := "abcdef1234abcdef1234abcdef1234abcdef1234"
-
-	aeAPI := sharedtest.NewMockAppEngineAPI(mockC)
-	aeAPI.EXPECT().GetVersionedHostname().MinTimes(1).Return("localhost:8080")
-	// Give a very short timeout (instead of the asked 1min) to make tests faster.
-	aeAPI.EXPECT().GetHTTPClientWithTimeout(uc.UploadTimeout).MinTimes(1).Return(&http.Client{Timeout: time.Microsecond})
-	serverURL, _ := url.Parse(server.URL)
-	aeAPI.EXPECT().GetResultsUploadURL().AnyTimes().Return(serverURL)
-
-	err := tc.CreateAllRuns(
-		shared.NewNilLogger(),
-		aeAPI,
-		sha,
-		"username",
-		"password",
-		map[string]tc.ArtifactURLs{
-			"chrome":  {Results: []string{"1"}},
-			"firefox": {Results: []string{"1", "2"}},
-		},
-		[]string{shared.MasterLabel},
-	)
-	assert.NotNil(t, err)
-	assert.Equal(t, 2, strings.Count(err.Error(), "Client.Timeout"))
+	product, labels = tc.ProductAndLabels(tc.EventInfo{Sha: sha}, rules.Classify("infrastructure/ tests (firefox)"))
+	assert.Equal(t, "firefox", product.BrowserName)
+	assert.Empty(t, labels)
 }
 
-func TestCreateAllRuns_pr_labels_exclude_master(t *testing.T) {
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		// We should not see a master label here, even though we
-		// specify one in the call to tc.CreateAllRuns.
-		defer r.Body.Close()
-		body, _ := io.ReadAll(r.Body)
-		assert.NotContains(t, string(body), "master")
-		w.Write([]byte("OK"))
-	}
-	server := httptest.NewServer(http.HandlerFunc(handler))
-	defer server.Close()
-	serverURL, _ := url.Parse(server.URL)
-	sha := "abcdef1234abcdef1234abcdef1234abcdef1234"
-
-	mockC := gomock.NewController(t)
-	defer mockC.Finish()
-	aeAPI := sharedtest.NewMockAppEngineAPI(mockC)
-	aeAPI.EXPECT().GetVersionedHostname().AnyTimes().Return("localhost:8080")
-	aeAPI.EXPECT().GetHTTPClientWithTimeout(uc.UploadTimeout).AnyTimes().Return(server.Client())
-	aeAPI.EXPECT().GetResultsUploadURL().AnyTimes().Return(serverURL)
-
+func TestProductAndLabels_pr_labels_exclude_master(t *testing.T) {
 	// This test reproduces the case where Community-TC executes a pull
 	// request run on a master commit (which we have historically seen).
 	// When we get a master-tagged run which contains pull-request runs, we
-	// should ignore the tag. This is asserted by the HTTP handler above.
-	err := tc.CreateAllRuns(
-		shared.NewNilLogger(),
-		aeAPI,
-		sha,
-		"username",
-		"password",
-		map[string]tc.ArtifactURLs{
-			"chrome-dev-pr_head":     {Results: []string{"1"}},
-			"chrome-dev-pr_base":     {Results: []string{"1"}},
-			"firefox-stable-pr_head": {Results: []string{"1"}},
-			"firefox-stable-pr_base": {Results: []string{"1"}},
-		},
-		[]string{shared.MasterLabel, "user:person"},
-	)
-	assert.Nil(t, err)
-}
+	// should ignore the tag.
+	event := tc.EventInfo{Sha: "abcdef1234abcdef1234abcdef1234abcdef1234", Master: true, Sender: "person"}
+	rules := defaultRules(t)
+
+	product, labels := tc.ProductAndLabels(event, rules.Classify("wpt-chrome-dev-results"))
+	assert.Equal(t, "chrome", product.BrowserName)
+	assert.ElementsMatch(t, []string{"dev", shared.PRHeadLabel, "user:person"}, labels)
 
-func TestTaskNameRegex(t *testing.T) {
-	assert.Equal(t, []string{"chrome-dev", "results"}, tc.TaskNameRegex.FindStringSubmatch("wpt-chrome-dev-results")[1:])
-	assert.Equal(t, []string{"chrome-dev", "results-without-changes"}, tc.TaskNameRegex.FindStringSubmatch("wpt-chrome-dev-results-without-changes")[1:])
-	assert.Equal(t, []string{"chrome-dev", "stability"}, tc.TaskNameRegex.FindStringSubmatch("wpt-chrome-dev-stability")[1:])
-	assert.Equal(t, []string{"chrome-stable", "reftest"}, tc.TaskNameRegex.FindStringSubmatch("wpt-chrome-stable-reftest-1")[1:])
-	assert.Equal(t, []string{"firefox-beta", "crashtest"}, tc.TaskNameRegex.FindStringSubmatch("wpt-firefox-beta-crashtest-2")[1:])
-	assert.Equal(t, []string{"firefox-nightly", "testharness"}, tc.TaskNameRegex.FindStringSubmatch("wpt-firefox-nightly-testharness-5")[1:])
-	assert.Equal(t, []string{"firefox-stable", "wdspec"}, tc.TaskNameRegex.FindStringSubmatch("wpt-firefox-stable-wdspec-1")[1:])
-	assert.Equal(t, []string{"webkitgtk_minibrowser-nightly", "testharness"}, tc.TaskNameRegex.FindStringSubmatch("wpt-webkitgtk_minibrowser-nightly-testharness-2")[1:])
-	assert.Equal(t, []string{"wpewebkit_minibrowser-nightly", "testharness"}, tc.TaskNameRegex.FindStringSubmatch("wpt-wpewebkit_minibrowser-nightly-testharness-2")[1:])
-	assert.Nil(t, tc.TaskNameRegex.FindStringSubmatch("wpt-foo-bar--1"))
-	assert.Nil(t, tc.TaskNameRegex.FindStringSubmatch("wpt-foo-bar-"))
+	_, labels = tc.ProductAndLabels(event, rules.Classify("wpt-firefox-stable-results-without-changes"))
+	assert.ElementsMatch(t, []string{"stable", shared.PRBaseLabel, "user:person"}, labels)
 }
 
 func TestGetStatusEventInfo_target_url(t *testing.T) {
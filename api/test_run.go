@@ -66,7 +66,11 @@ func apiTestRunHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		one := 1
-		testRuns, err := store.TestRunQuery().LoadTestRuns(
+		q := store.TestRunQuery()
+		if filters.IncludeInvalid != nil && *filters.IncludeInvalid {
+			q = q.IncludeInvalid()
+		}
+		testRuns, err := q.LoadTestRuns(
 			filters.Products,
 			filters.Labels,
 			filters.SHAs,
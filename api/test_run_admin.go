@@ -0,0 +1,276 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// testRunAuditLogKind is the Datastore kind of TestRunAuditLog entities.
+const testRunAuditLogKind = "TestRunAuditLog"
+
+// The actions of admin updates of test runs.
+const (
+	testRunActionInvalidate = "invalidate"
+	testRunActionRestore    = "restore"
+	testRunActionLabels     = "labels"
+)
+
+// testRunUpdate is the (optional) JSON body of an admin update of a test run.
+type testRunUpdate struct {
+	// Reason is recorded in the audit log.
+	Reason string `json:"reason"`
+	// AddLabels and RemoveLabels are only used by the "labels" action.
+	AddLabels    []string `json:"add"`
+	RemoveLabels []string `json:"remove"`
+}
+
+var errNoLabelChanges = errors.New("no labels to add or remove")
+
+// apiTestRunUpdateHandler handles admin updates of a single test run: marking
+// it invalid, restoring it, or changing its labels.
+func apiTestRunUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+	gac, store, ok := getAdminAccessControl(w, r)
+	if !ok {
+		return
+	}
+	handleTestRunUpdate(r.Context(), gac, store, w, r)
+}
+
+// apiTestRunAuditLogHandler responds with the audit log of a single test run.
+func apiTestRunAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+	gac, store, ok := getAdminAccessControl(w, r)
+	if !ok {
+		return
+	}
+	handleTestRunAuditLog(r.Context(), gac, store, w, r)
+}
+
+// getAdminAccessControl returns the GitHubAccessControl of the logged-in user,
+// along with an uncached Datastore. ok is false (after writing an error
+// response) if the user isn't logged in.
+func getAdminAccessControl(w http.ResponseWriter, r *http.Request) (
+	gac shared.GitHubAccessControl, store shared.Datastore, ok bool) {
+	ctx := r.Context()
+	store = shared.NewAppEngineDatastore(ctx, false)
+	gac, err := shared.NewGitHubAccessControlFromRequest(shared.NewAppEngineAPI(ctx), store, r)
+	if err != nil {
+		shared.GetLogger(ctx).Errorf("Error creating GitHubAccessControl: %s", err.Error())
+		http.Error(w, "Error creating GitHubAccessControl", http.StatusInternalServerError)
+
+		return nil, nil, false
+	} else if gac == nil {
+		http.Error(w, "User is not logged in", http.StatusUnauthorized)
+
+		return nil, nil, false
+	}
+
+	return gac, store, true
+}
+
+// checkAdmin writes an error response and returns false if the user isn't an
+// admin.
+func checkAdmin(ctx context.Context, gac shared.GitHubAccessControl, w http.ResponseWriter) bool {
+	admin, err := gac.IsValidAdmin()
+	if err != nil {
+		shared.GetLogger(ctx).Errorf("Error checking admin: %s", err.Error())
+		http.Error(w, "Error checking admin", http.StatusInternalServerError)
+
+		return false
+	} else if !admin {
+		http.Error(w, "Admin only", http.StatusForbidden)
+
+		return false
+	}
+
+	return true
+}
+
+// parseTestRunID parses the "id" var of the request, writing an error response
+// if it is invalid.
+func parseTestRunID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idParam := mux.Vars(r)["id"]
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid id '%s'", idParam), http.StatusBadRequest)
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+func handleTestRunUpdate(
+	ctx context.Context,
+	gac shared.GitHubAccessControl,
+	store shared.Datastore,
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	if !checkAdmin(ctx, gac, w) {
+		return
+	}
+	id, ok := parseTestRunID(w, r)
+	if !ok {
+		return
+	}
+	action := mux.Vars(r)["action"]
+	var update testRunUpdate
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusInternalServerError)
+
+		return
+	} else if len(body) > 0 {
+		if err := json.Unmarshal(body, &update); err != nil {
+			http.Error(w, "Failed to parse JSON: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	entry := shared.TestRunAuditLog{
+		RunID:  id,
+		Action: action,
+		Reason: update.Reason,
+		Time:   time.Now(),
+	}
+	if user := gac.User(); user != nil {
+		entry.GitHubUser = user.GitHubHandle
+	}
+	var run shared.TestRun
+	err = store.Update(store.NewIDKey("TestRun", id), &run, func(obj interface{}) error {
+		run := obj.(*shared.TestRun)
+		if run.BrowserName == "" {
+			return shared.ErrNoSuchEntity
+		}
+		switch action {
+		case testRunActionInvalidate:
+			run.Invalid = true
+		case testRunActionRestore:
+			run.Invalid = false
+		case testRunActionLabels:
+			entry.AddedLabels, entry.RemovedLabels = updateLabels(run, update.AddLabels, update.RemoveLabels)
+			if len(entry.AddedLabels) == 0 && len(entry.RemovedLabels) == 0 {
+				return errNoLabelChanges
+			}
+		default:
+			return fmt.Errorf("unknown action %q", action)
+		}
+
+		return nil
+	})
+	if errors.Is(err, shared.ErrNoSuchEntity) {
+		http.NotFound(w, r)
+
+		return
+	} else if errors.Is(err, errNoLabelChanges) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	run.ID = id
+
+	logger := shared.GetLogger(ctx)
+	logger.Infof("%s: %s run %d", entry.GitHubUser, action, id)
+	if _, err := store.Put(store.NewIncompleteKey(testRunAuditLogKind), &entry); err != nil {
+		logger.Errorf("Failed to write audit log of run %d: %s", id, err.Error())
+	}
+	// Cached copies of the run would otherwise still be served.
+	if err := shared.DeleteCachedTestRun(id); err != nil {
+		logger.Warningf("Failed to delete cached run %d: %s", id, err.Error())
+	}
+
+	writeTestRunAdminJSON(ctx, w, run)
+}
+
+// updateLabels adds and removes the given labels of the run, returning the
+// labels which were actually added and removed.
+func updateLabels(run *shared.TestRun, add, remove []string) (added, removed []string) {
+	labels := run.LabelsSet()
+	for _, label := range add {
+		if label != "" && !labels.Contains(label) {
+			labels.Add(label)
+			added = append(added, label)
+		}
+	}
+	for _, label := range remove {
+		if labels.Contains(label) {
+			labels.Remove(label)
+			removed = append(removed, label)
+		}
+	}
+	run.Labels = shared.ToStringSlice(labels)
+
+	return added, removed
+}
+
+func handleTestRunAuditLog(
+	ctx context.Context,
+	gac shared.GitHubAccessControl,
+	store shared.Datastore,
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	if !checkAdmin(ctx, gac, w) {
+		return
+	}
+	id, ok := parseTestRunID(w, r)
+	if !ok {
+		return
+	}
+	var entries []shared.TestRunAuditLog
+	keys, err := store.GetAll(store.NewQuery(testRunAuditLogKind).Filter("RunID =", id).Order("-Time"), &entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	for i, key := range keys {
+		entries[i].ID = key.IntID()
+	}
+	if entries == nil {
+		entries = []shared.TestRunAuditLog{}
+	}
+	writeTestRunAdminJSON(ctx, w, entries)
+}
+
+func writeTestRunAdminJSON(ctx context.Context, w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	if _, err := w.Write(data); err != nil {
+		shared.GetLogger(ctx).Warningf("Failed to write data in api/runs admin handler: %s", err.Error())
+	}
+}
@@ -0,0 +1,131 @@
+//go:build medium
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package api //nolint:revive
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+)
+
+func newTestRunUpdateRequest(t *testing.T, i sharedtest.Instance, action, body string) *http.Request {
+	r, err := i.NewRequest("POST", "/api/runs/123/"+action, strings.NewReader(body))
+	assert.Nil(t, err)
+
+	return mux.SetURLVars(r, map[string]string{"id": "123", "action": action})
+}
+
+func TestHandleTestRunUpdate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	i, err := sharedtest.NewAEInstance(true)
+	assert.Nil(t, err)
+	defer i.Close()
+
+	r := newTestRunUpdateRequest(t, i, "invalidate", `{"reason": "Broken runner"}`)
+	ctx := r.Context()
+	store := shared.NewAppEngineDatastore(ctx, false)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := shared.TestRun{}
+	run.BrowserName = "chrome"
+	run.Labels = []string{"chrome", "stable"}
+	_, err = store.Put(store.NewIDKey("TestRun", 123), &run)
+	assert.Nil(t, err)
+
+	mockgac := sharedtest.NewMockGitHubAccessControl(mockCtrl)
+	mockgac.EXPECT().IsValidAdmin().Return(true, nil).AnyTimes()
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	mockgac.EXPECT().User().Return(&shared.User{GitHubHandle: "deckard"}).AnyTimes()
+
+	resp := httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, store, resp, r)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var got shared.TestRun
+	assert.Nil(t, store.Get(store.NewIDKey("TestRun", 123), &got))
+	assert.True(t, got.Invalid)
+
+	r = newTestRunUpdateRequest(t, i, "labels", `{"add": ["experimental"], "remove": ["stable", "beta"]}`)
+	resp = httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, store, resp, r)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &got))
+	assert.Equal(t, int64(123), got.ID)
+	assert.ElementsMatch(t, []string{"chrome", "experimental"}, got.Labels)
+
+	// Removing labels which the run doesn't have is a bad request.
+	r = newTestRunUpdateRequest(t, i, "labels", `{"remove": ["stable"]}`)
+	resp = httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, store, resp, r)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	r = newTestRunUpdateRequest(t, i, "restore", "")
+	resp = httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, store, resp, r)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, store.Get(store.NewIDKey("TestRun", 123), &got))
+	assert.False(t, got.Invalid)
+
+	r, err = i.NewRequest("GET", "/api/runs/123/audit", nil)
+	assert.Nil(t, err)
+	r = mux.SetURLVars(r, map[string]string{"id": "123"})
+	resp = httptest.NewRecorder()
+	handleTestRunAuditLog(ctx, mockgac, store, resp, r)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var entries []shared.TestRunAuditLog
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &entries))
+	assert.Len(t, entries, 3)
+	actions := make([]string, len(entries))
+	for j, entry := range entries {
+		assert.Equal(t, "deckard", entry.GitHubUser)
+		actions[j] = entry.Action
+	}
+	assert.ElementsMatch(t, []string{"invalidate", "labels", "restore"}, actions)
+}
+
+func TestHandleTestRunUpdate_notFound(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	i, err := sharedtest.NewAEInstance(true)
+	assert.Nil(t, err)
+	defer i.Close()
+
+	r := newTestRunUpdateRequest(t, i, "invalidate", "")
+	ctx := r.Context()
+	mockgac := sharedtest.NewMockGitHubAccessControl(mockCtrl)
+	mockgac.EXPECT().IsValidAdmin().Return(true, nil)
+	mockgac.EXPECT().User().Return(nil)
+
+	resp := httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, shared.NewAppEngineDatastore(ctx, false), resp, r)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestHandleTestRunUpdate_notAdmin(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	i, err := sharedtest.NewAEInstance(true)
+	assert.Nil(t, err)
+	defer i.Close()
+
+	r := newTestRunUpdateRequest(t, i, "invalidate", "")
+	ctx := r.Context()
+	mockgac := sharedtest.NewMockGitHubAccessControl(mockCtrl)
+	mockgac.EXPECT().IsValidAdmin().Return(false, nil)
+
+	resp := httptest.NewRecorder()
+	handleTestRunUpdate(ctx, mockgac, shared.NewAppEngineDatastore(ctx, false), resp, r)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+}
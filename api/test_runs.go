@@ -113,6 +113,9 @@ func LoadTestRunKeysForFilters(store shared.Datastore, filters shared.TestRunFil
 	err error,
 ) {
 	q := store.TestRunQuery()
+	if filters.IncludeInvalid != nil && *filters.IncludeInvalid {
+		q = q.IncludeInvalid()
+	}
 	limit := filters.MaxCount
 	offset := filters.Offset
 	from := filters.From
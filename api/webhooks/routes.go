@@ -0,0 +1,14 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package webhooks
+
+import "github.com/web-platform-tests/wpt.fyi/shared"
+
+// RegisterRoutes adds the route handlers for outgoing webhooks.
+func RegisterRoutes() {
+	// Endpoint for delivering an event to a registered webhook.
+	// When a run's state changes, we call this endpoint from the outgoing-webhooks TaskQueue.
+	shared.AddRoute(DeliverTarget, "api-webhooks-deliver", deliverHandler)
+}
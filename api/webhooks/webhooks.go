@@ -0,0 +1,245 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+// WebhookKind is the Datastore kind of OutgoingWebhook entities.
+const WebhookKind = "OutgoingWebhook"
+
+// WebhooksQueue is the name of the TaskQueue that delivers outgoing webhooks.
+// Failed deliveries are retried with backoff by the queue.
+const WebhooksQueue = "outgoing-webhooks"
+
+// DeliverTarget is the handler path for delivering an outgoing webhook.
+const DeliverTarget = "/api/webhooks/deliver"
+
+// EventKind is the Datastore kind of WebhookEvent entities.
+const EventKind = "WebhookEvent"
+
+// SignatureHeader is the header carrying the hex HMAC-SHA256 of the request
+// (see SignTimestamped), keyed by the webhook's secret, in the form
+// "sha256=<hex>".
+const SignatureHeader = "X-WPT-Signature"
+
+// TimestampHeader is the header carrying the Unix time at which a webhook
+// request was sent, which is signed along with its body; see SignTimestamped.
+const TimestampHeader = "X-WPT-Timestamp"
+
+// EventHeader is the header carrying the name of the event being delivered.
+const EventHeader = "X-WPT-Event"
+
+// Event is the name of a run lifecycle event.
+type Event string
+
+const (
+	// EventPendingRunStage is fired when a PendingTestRun changes stage.
+	EventPendingRunStage Event = "pending_run.stage"
+	// EventTestRunCreated is fired when a TestRun is created.
+	EventTestRunCreated Event = "test_run.created"
+	// EventPendingRunFinished is delivered to the notify_url of a
+	// PendingTestRun (rather than to webhooks) when it reaches a terminal stage.
+	EventPendingRunFinished Event = "pending_run.finished"
+)
+
+// OutgoingWebhook is an external URL which is notified of run lifecycle events.
+// Webhooks are registered by adding entities to Datastore.
+type OutgoingWebhook struct {
+	ID  int64  `datastore:"-"`
+	URL string `datastore:"URL"`
+	// Secret is the key used to sign deliveries; see SignatureHeader.
+	Secret string `datastore:"Secret,noindex"`
+	// Events are the events to deliver; all events are delivered if empty.
+	Events []string `datastore:"Events"`
+}
+
+// WebhookEvent is an event dispatched to the webhooks. Its payload is stored,
+// rather than passed to the delivery tasks, so that only payloads created by
+// wpt.fyi are ever signed.
+type WebhookEvent struct {
+	Event   string    `datastore:"Event"`
+	Payload []byte    `datastore:"Payload,noindex"`
+	Created time.Time `datastore:"Created"`
+}
+
+// Wants returns whether the webhook should be notified of the given event.
+func (h OutgoingWebhook) Wants(event Event) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if Event(e) == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign returns the value of the SignatureHeader for the given body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignTimestamped returns the value of the SignatureHeader for the given body,
+// sent at the given timestamp (the value of the TimestampHeader), i.e. the
+// signature of "<timestamp>.<body>", so that the timestamp can't be replaced.
+func SignTimestamped(secret, timestamp string, body []byte) string {
+	return Sign(secret, append([]byte(timestamp+"."), body...))
+}
+
+// Dispatch schedules the delivery of an event, with the JSON of the given
+// payload as its body, to every webhook that wants it.
+func Dispatch(aeAPI shared.AppEngineAPI, store shared.Datastore, event Event, payload interface{}) error {
+	var hooks []OutgoingWebhook
+	keys, err := store.GetAll(store.NewQuery(WebhookKind), &hooks)
+	if err != nil {
+		return err
+	}
+	var wanting []int64
+	for i, hook := range hooks {
+		if hook.Wants(event) {
+			wanting = append(wanting, keys[i].IntID())
+		}
+	}
+	if len(wanting) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	eventKey, err := store.Put(store.NewIncompleteKey(EventKind), &WebhookEvent{
+		Event:   string(event),
+		Payload: body,
+		Created: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range wanting {
+		params := url.Values{}
+		params.Set("webhook", strconv.FormatInt(id, 10))
+		params.Set("event", strconv.FormatInt(eventKey.IntID(), 10))
+		if _, err := aeAPI.ScheduleTask(WebhooksQueue, "", DeliverTarget, params); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliverHandler delivers a single stored event to a single webhook. It is
+// called from the outgoing-webhooks TaskQueue; a non-2XX response causes a
+// retry.
+func deliverHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST is supported.", http.StatusMethodNotAllowed)
+
+		return
+	}
+	if r.Header.Get(shared.QueueNameHeader) != WebhooksQueue {
+		http.Error(w, "Deliveries can only be requested from the "+WebhooksQueue+" queue", http.StatusForbidden)
+
+		return
+	}
+	ctx := r.Context()
+	logger := shared.GetLogger(ctx)
+	id, err := strconv.ParseInt(r.FormValue("webhook"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook param", http.StatusBadRequest)
+
+		return
+	}
+	eventID, err := strconv.ParseInt(r.FormValue("event"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid event param", http.StatusBadRequest)
+
+		return
+	}
+
+	store := shared.NewAppEngineDatastore(ctx, false)
+	var hook OutgoingWebhook
+	if err := store.Get(store.NewIDKey(WebhookKind, id), &hook); err != nil {
+		if errors.Is(err, shared.ErrNoSuchEntity) {
+			// The webhook was removed; there's nothing left to retry.
+			logger.Warningf("Webhook %v no longer exists", id)
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+	hook.ID = id
+	var stored WebhookEvent
+	if err := store.Get(store.NewIDKey(EventKind, eventID), &stored); err != nil {
+		if errors.Is(err, shared.ErrNoSuchEntity) {
+			logger.Warningf("Webhook event %v no longer exists", eventID)
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	client := shared.NewAppEngineAPI(ctx).GetHTTPClientWithTimeout(time.Second * 30)
+	event := Event(stored.Event)
+	if err := Deliver(client, hook, event, stored.Payload); err != nil {
+		logger.Warningf("Failed to deliver %s to webhook %v: %s", event, id, err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+
+		return
+	}
+	logger.Infof("Delivered %s to webhook %v", event, id)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Deliver POSTs the body to the webhook's URL, signed along with the current
+// time, so that the receiver can reject replayed deliveries.
+func Deliver(client *http.Client, hook OutgoingWebhook, event Event, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, SignTimestamped(hook.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned HTTP status %d", hook.URL, resp.StatusCode)
+	}
+
+	return nil
+}
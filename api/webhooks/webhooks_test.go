@@ -0,0 +1,127 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package webhooks
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/web-platform-tests/wpt.fyi/shared"
+	"github.com/web-platform-tests/wpt.fyi/shared/sharedtest"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '{}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t, "sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13", Sign("secret", []byte("{}")))
+	assert.NotEqual(t, Sign("secret", []byte("{}")), Sign("other", []byte("{}")))
+}
+
+func TestSignTimestamped(t *testing.T) {
+	assert.Equal(t, Sign("secret", []byte("1700000000.{}")), SignTimestamped("secret", "1700000000", []byte("{}")))
+	assert.NotEqual(t, SignTimestamped("secret", "1700000000", []byte("{}")), SignTimestamped("secret", "1700000001", []byte("{}")))
+}
+
+func TestOutgoingWebhook_Wants(t *testing.T) {
+	assert.True(t, OutgoingWebhook{}.Wants(EventTestRunCreated))
+	hook := OutgoingWebhook{Events: []string{string(EventPendingRunStage)}}
+	assert.True(t, hook.Wants(EventPendingRunStage))
+	assert.False(t, hook.Wants(EventTestRunCreated))
+}
+
+func TestDeliver(t *testing.T) {
+	body := []byte(`{"id":123}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		assert.Equal(t, body, received)
+		assert.Equal(t, string(EventTestRunCreated), r.Header.Get(EventHeader))
+		timestamp := r.Header.Get(TimestampHeader)
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, SignTimestamped("secret", timestamp, body), r.Header.Get(SignatureHeader))
+	}))
+	defer server.Close()
+
+	hook := OutgoingWebhook{URL: server.URL, Secret: "secret"}
+	assert.Nil(t, Deliver(server.Client(), hook, EventTestRunCreated, body))
+}
+
+func TestDeliver_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	hook := OutgoingWebhook{URL: server.URL, Secret: "secret"}
+	assert.NotNil(t, Deliver(server.Client(), hook, EventTestRunCreated, []byte("{}")))
+}
+
+func TestDispatch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	aeAPI := sharedtest.NewMockAppEngineAPI(mockCtrl)
+	store.EXPECT().NewQuery(WebhookKind).Return(nil)
+	store.EXPECT().GetAll(nil, gomock.Any()).DoAndReturn(func(_ shared.Query, dst interface{}) ([]shared.Key, error) {
+		*(dst.(*[]OutgoingWebhook)) = []OutgoingWebhook{
+			{URL: "https://a.example.com"},
+			{URL: "https://b.example.com", Events: []string{string(EventPendingRunStage)}},
+		}
+
+		return []shared.Key{
+			&sharedtest.MockKey{TypeName: WebhookKind, ID: 1},
+			&sharedtest.MockKey{TypeName: WebhookKind, ID: 2},
+		}, nil
+	})
+	eventKey := &sharedtest.MockKey{TypeName: EventKind, ID: 456}
+	store.EXPECT().NewIncompleteKey(EventKind).Return(eventKey)
+	store.EXPECT().Put(eventKey, gomock.Any()).DoAndReturn(func(_ shared.Key, src interface{}) (shared.Key, error) {
+		stored := src.(*WebhookEvent)
+		assert.Equal(t, string(EventTestRunCreated), stored.Event)
+		assert.Equal(t, `{"id":123}`, string(stored.Payload))
+
+		return eventKey, nil
+	})
+	// Only the ID of the stored event is passed to the delivery task.
+	params := url.Values{}
+	params.Set("webhook", "1")
+	params.Set("event", "456")
+	aeAPI.EXPECT().ScheduleTask(WebhooksQueue, "", DeliverTarget, params).Return("task", nil)
+
+	assert.Nil(t, Dispatch(aeAPI, store, EventTestRunCreated, map[string]int{"id": 123}))
+}
+
+func TestDispatch_NoWebhooks(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// Nothing is stored or scheduled when no webhook wants the event.
+	store := sharedtest.NewMockDatastore(mockCtrl)
+	store.EXPECT().NewQuery(WebhookKind).Return(nil)
+	store.EXPECT().GetAll(nil, gomock.Any()).DoAndReturn(func(_ shared.Query, dst interface{}) ([]shared.Key, error) {
+		*(dst.(*[]OutgoingWebhook)) = []OutgoingWebhook{
+			{URL: "https://a.example.com", Events: []string{string(EventPendingRunStage)}},
+		}
+
+		return []shared.Key{&sharedtest.MockKey{TypeName: WebhookKind, ID: 1}}, nil
+	})
+
+	assert.Nil(t, Dispatch(nil, store, EventTestRunCreated, map[string]int{"id": 123}))
+}
+
+func TestDeliverHandler_NotFromQueue(t *testing.T) {
+	r := httptest.NewRequest("POST", DeliverTarget, strings.NewReader("webhook=1&event=456"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	deliverHandler(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -274,6 +274,7 @@ class Processor(object):
         labels: str,
         uploader: str,
         callback_url: Optional[str] = None,
+        duplicate_policy: Optional[str] = None,
     ) -> None:
         """Creates a TestRun record.
 
@@ -282,6 +283,8 @@ class Processor(object):
             labels: A comma-separated string of extra labels.
             uploader: The name of the uploader.
             callback_url: URL of the test run creation API (optional).
+            duplicate_policy: What to do if the run duplicates an existing
+                run (optional).
         """
         self.test_run_id = wptreport.create_test_run(
             self.report,
@@ -291,7 +294,8 @@ class Processor(object):
             self.auth,
             self.results_url,
             self.raw_results_url,
-            callback_url)
+            callback_url,
+            duplicate_policy)
         assert self.test_run_id
 
     def update_status(
@@ -375,6 +379,7 @@ def process_report(task_id: Optional[str], params: MultiDict[str, str]) -> str:
     run_id = params.get('id', '0')
     callback_url = params.get('callback_url')
     labels = params.get('labels', '')
+    duplicate_policy = params.get('duplicate_policy')
 
     response = []
     with Processor() as p:
@@ -436,7 +441,17 @@ def process_report(task_id: Optional[str], params: MultiDict[str, str]) -> str:
             p.update_status(run_id, 'DUPLICATE', None, callback_url)
             return ''
 
-        p.create_run(run_id, labels, uploader, callback_url)
+        try:
+            p.create_run(run_id, labels, uploader, callback_url,
+                         duplicate_policy)
+        except requests.HTTPError as e:
+            # The run was rejected as a duplicate, which wpt.fyi has already
+            # recorded on the pending run, so there is no point to retry.
+            if e.response is None or e.response.status_code != 409:
+                raise
+            _log.warning('Run %s was rejected as a duplicate: %s',
+                         run_id, e.response.text)
+            return ''
         response.append("run ID: {}".format(p.test_run_id))
 
         p.run_hooks([_upload_screenshots])
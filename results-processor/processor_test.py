@@ -4,8 +4,9 @@
 
 import json
 import unittest
-from unittest.mock import call, patch
+from unittest.mock import Mock, call, patch
 
+import requests
 from werkzeug.datastructures import MultiDict
 
 import test_util
@@ -141,6 +142,7 @@ class MockProcessorTest(unittest.TestCase):
             'os_name': 'Linux',
             'os_version': '5.0',
             'revision': '21917b36553562d21c14fe086756a57cbe8a381b',
+            'duplicate_policy': 'reject',
         })
         process_report('12345', params)
         mock.assert_has_calls([
@@ -153,7 +155,8 @@ class MockProcessorTest(unittest.TestCase):
             browser_name='Chrome', browser_version='70',
             os_name='Linux', os_version='5.0')
         mock.create_run.assert_called_once_with(
-            '654321', 'foo,bar', 'blade-runner', 'https://test.wpt.fyi/api')
+            '654321', 'foo,bar', 'blade-runner', 'https://test.wpt.fyi/api',
+            'reject')
 
     @patch('processor.Processor')
     def test_params_plumbing_error(self, MockProcessor):
@@ -224,6 +227,29 @@ class MockProcessorTest(unittest.TestCase):
         mock.create_run.assert_not_called()
 
 
+    @patch('processor.Processor')
+    def test_params_plumbing_duplicate_rejected(self, MockProcessor):
+        # Set up mock context manager to return self.
+        mock = MockProcessor.return_value
+        mock.__enter__.return_value = mock
+        mock.check_existing_run.return_value = False
+        mock.results = ['/tmp/wpt_report.json.gz']
+        mock.create_run.side_effect = requests.HTTPError(
+            response=Mock(status_code=409, text='duplicate of run 123'))
+
+        params = MultiDict({
+            'uploader': 'blade-runner',
+            'id': '654321',
+            'results': 'https://wpt.fyi/wpt_report.json.gz',
+            'duplicate_policy': 'reject',
+        })
+        with self.assertLogs():
+            self.assertEqual(process_report('12345', params), '')
+        mock.create_run.assert_called_once_with(
+            '654321', '', 'blade-runner', None, 'reject')
+        mock.run_hooks.assert_not_called()
+
+
 class ProcessorDownloadServerTest(unittest.TestCase):
     """This class tests behaviours of Processor related to downloading
     artifacts (e.g. JSON reports) from an external server. test_server is used
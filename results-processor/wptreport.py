@@ -687,6 +687,7 @@ def create_test_run(
     results_url: str,
     raw_results_url: str,
     callback_url: Optional[str] = None,
+    duplicate_policy: Optional[str] = None,
 ) -> int:
     """Creates a TestRun on the dashboard.
 
@@ -702,6 +703,9 @@ def create_test_run(
             'https://.../wptd/0123456789/chrome-62.0-linux-summary_v2.json.gz')
         raw_results_url: URL of the raw full report. (e.g.
             'https://.../wptd-results/[FullSHA]/chrome-62.0-linux/report.json')
+        callback_url: URL of the test run creation API (optional).
+        duplicate_policy: What to do if the run duplicates an existing run
+            (optional); see the duplicate_policy param of /api/results/create.
 
     Returns:
         The integral ID associated with the created test run.
@@ -723,7 +727,11 @@ def create_test_run(
     payload['raw_results_url'] = raw_results_url
     payload['labels'] = sorted(labels)
 
-    response = requests.post(callback_url, auth=auth, json=payload)
+    params = None
+    if duplicate_policy:
+        params = {'duplicate_policy': duplicate_policy}
+    response = requests.post(
+        callback_url, params=params, auth=auth, json=payload)
     response.raise_for_status()
     response_data = response.json()
     assert isinstance(response_data['id'], int)
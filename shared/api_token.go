@@ -0,0 +1,104 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// APITokenKind is the Datastore kind of APIToken entities.
+const APITokenKind = "APIToken"
+
+// apiTokenPrefix makes API tokens recognizable, e.g. by secret scanners.
+const apiTokenPrefix = "wptfyi_"
+
+// ErrInvalidAPIToken is returned for API tokens which are unknown, revoked or
+// expired.
+var ErrInvalidAPIToken = errors.New("invalid API token")
+
+// NewAPITokenValue generates a new random API token, returning the token and
+// its hash.
+func NewAPITokenValue() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the hash of the token under which it is stored.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// LookupAPIToken loads the APIToken for the given (plaintext) token. An
+// ErrInvalidAPIToken is returned if it doesn't exist, is revoked, or has
+// expired.
+func LookupAPIToken(ds Datastore, token string) (*APIToken, error) {
+	var tokens []APIToken
+	keys, err := ds.GetAll(ds.NewQuery(APITokenKind).Filter("Hash =", HashAPIToken(token)).Limit(1), &tokens)
+	if err != nil {
+		return nil, err
+	} else if len(tokens) == 0 {
+		return nil, ErrInvalidAPIToken
+	}
+	t := &tokens[0]
+	t.ID = keys[0].IntID()
+	if !t.Valid(time.Now()) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	return t, nil
+}
+
+// Valid returns whether the token is neither revoked nor expired at the given
+// time.
+func (t APIToken) Valid(now time.Time) bool {
+	return !t.Revoked && now.Before(t.Expires)
+}
+
+// HasScope returns whether the token can be used for the given scope.
+func (t APIToken) HasScope(scope APITokenScope) bool {
+	for _, s := range t.Scopes {
+		if s == string(scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsProduct returns whether the token can be used for the given browser.
+func (t APIToken) AllowsProduct(browserName string) bool {
+	if len(t.Products) == 0 {
+		return true
+	}
+	for _, p := range t.Products {
+		if p == browserName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsAPITokenScope returns whether the given string is a known APITokenScope.
+func IsAPITokenScope(scope string) bool {
+	switch APITokenScope(scope) {
+	case ScopeUploadResults, ScopeUpdatePendingRuns, ScopeCreateRuns, ScopeUploadScreenshots:
+		return true
+	}
+
+	return false
+}
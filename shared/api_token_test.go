@@ -0,0 +1,56 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPITokenValue(t *testing.T) {
+	token, hash, err := NewAPITokenValue()
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(token, "wptfyi_"))
+	assert.Equal(t, HashAPIToken(token), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, err := NewAPITokenValue()
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestAPIToken_Valid(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	token := APIToken{Expires: now.Add(time.Hour)}
+	assert.True(t, token.Valid(now))
+	assert.False(t, token.Valid(now.Add(time.Hour)))
+	token.Revoked = true
+	assert.False(t, token.Valid(now))
+}
+
+func TestAPIToken_HasScope(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	token := APIToken{Scopes: []string{string(ScopeUploadResults)}}
+	assert.True(t, token.HasScope(ScopeUploadResults))
+	assert.False(t, token.HasScope(ScopeCreateRuns))
+}
+
+func TestAPIToken_AllowsProduct(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	token := APIToken{}
+	assert.True(t, token.AllowsProduct("chrome"))
+	assert.True(t, token.AllowsProduct(""))
+
+	token.Products = []string{"chrome", "edge"}
+	assert.True(t, token.AllowsProduct("edge"))
+	assert.False(t, token.AllowsProduct("firefox"))
+	assert.False(t, token.AllowsProduct(""))
+}
@@ -206,6 +206,9 @@ type AppEngineAPI interface {
 	// Simple wrappers that delegate to Datastore
 	IsFeatureEnabled(featureName string) bool
 	GetUploader(uploader string) (Uploader, error)
+	// GetAPIToken returns the valid APIToken for the given (plaintext)
+	// token, or ErrInvalidAPIToken.
+	GetAPIToken(token string) (*APIToken, error)
 
 	// ScheduleTask schedules an AppEngine POST task on Cloud Tasks.
 	// taskName can be empty, in which case one will be generated by Cloud
@@ -306,6 +309,10 @@ func (a appEngineAPIImpl) GetUploader(uploader string) (Uploader, error) {
 	return GetUploader(m, uploader)
 }
 
+func (a appEngineAPIImpl) GetAPIToken(token string) (*APIToken, error) {
+	return LookupAPIToken(NewAppEngineDatastore(a.ctx, false), token)
+}
+
 func (a appEngineAPIImpl) GetHostname() string {
 	if runtimeIdentity.AppID == "wptdashboard" {
 		return "wpt.fyi"
@@ -351,6 +358,10 @@ func (a appEngineAPIImpl) GetResultsUploadURL() *url.URL {
 	return result
 }
 
+// QueueNameHeader is the header which AppEngine sets on the requests of its
+// task queues, to the name of the queue, and strips from external requests.
+const QueueNameHeader = "X-AppEngine-QueueName"
+
 func (a appEngineAPIImpl) ScheduleTask(queueName, taskName, target string, params url.Values) (string, error) {
 	if Clients.cloudtasks == nil {
 		panic("Clients.cloudtasks is nil")
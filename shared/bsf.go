@@ -0,0 +1,346 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ComputeBSFTestScores computes, for each test, the browser-specific failure
+// contribution of each of the given aligned runs' summaries.
+//
+// A run's contribution for a test is the fraction of the test's subtests that
+// fail in that run, minus the failing fractions of all the other runs, floored
+// at 0. When every other run passes the test completely, this is exactly the
+// fraction of subtests that fail only in that run; otherwise it is a lower
+// bound, as [pass, total] summaries don't say which subtests failed. Each test
+// therefore contributes at most 1 to a run's score.
+//
+// Tests which are missing from any of the runs can't be compared, and are
+// skipped. Only tests with a non-zero contribution for some run are included
+// in the result.
+func ComputeBSFTestScores(summaries []ResultsSummary) map[string][]float64 {
+	scores := make(map[string][]float64)
+	if len(summaries) < 2 {
+		return scores
+	}
+
+	failing := make([]float64, len(summaries))
+	for test := range summaries[0] {
+		complete := true
+		var total float64
+		for i, summary := range summaries {
+			counts, ok := summary[test]
+			if !ok || len(counts) < 2 || counts[1] < 1 {
+				complete = false
+
+				break
+			}
+			failing[i] = float64(counts[1]-counts[0]) / float64(counts[1])
+			total += failing[i]
+		}
+		if !complete || total == 0 {
+			continue
+		}
+
+		var testScores []float64
+		for i := range summaries {
+			// failing[i] - (total - failing[i]) is this run's failures, less all the others'.
+			score := 2*failing[i] - total
+			if score <= 0 {
+				continue
+			}
+			if testScores == nil {
+				testScores = make([]float64, len(summaries))
+			}
+			testScores[i] = score
+		}
+		if testScores != nil {
+			scores[test] = testScores
+		}
+	}
+
+	return scores
+}
+
+// ComputeBSFScores computes the browser-specific failure score of each of the
+// given aligned runs' summaries; see ComputeBSFTestScores.
+func ComputeBSFScores(summaries []ResultsSummary) []float64 {
+	scores := make([]float64, len(summaries))
+	for _, testScores := range ComputeBSFTestScores(summaries) {
+		for i, score := range testScores {
+			scores[i] += score
+		}
+	}
+
+	return scores
+}
+
+// NewBSFDataForRuns produces a BSFData table, in the same format as the
+// precomputed BSF CSVs, for the given products. alignedRuns contains one
+// TestRun per product for each revision, and summaries the corresponding
+// results summaries. Rows are sorted chronologically.
+func NewBSFDataForRuns(products ProductSpecs, alignedRuns []TestRuns, summaries [][]ResultsSummary) BSFData {
+	fields := []string{"sha", "date"}
+	for _, product := range products {
+		fields = append(fields, product.String()+"-version", product.String())
+	}
+
+	type row struct {
+		start time.Time
+		data  []string
+	}
+	rows := make([]row, 0, len(alignedRuns))
+	for i, runs := range alignedRuns {
+		if len(runs) != len(products) || len(runs) == 0 {
+			continue
+		}
+		scores := ComputeBSFScores(summaries[i])
+		data := []string{runs[0].FullRevisionHash, runs[0].TimeStart.Format("2006-01-02")}
+		for j, run := range runs {
+			data = append(data, run.BrowserVersion, strconv.FormatFloat(scores[j], 'f', -1, 64))
+		}
+		rows = append(rows, row{start: runs[0].TimeStart, data: data})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].start.Before(rows[j].start) })
+
+	if len(rows) == 0 {
+		return BSFData{}
+	}
+	response := BSFData{Fields: fields}
+	for _, r := range rows {
+		response.Data = append(response.Data, r.data)
+	}
+	// The latest revision is the last row.
+	response.LastUpdateRevision = rows[len(rows)-1].data[0]
+
+	return response
+}
+
+// GroupAlignedRuns groups the given runs by revision, returning one TestRun
+// per product (in order) for each revision that has a run for all products.
+func GroupAlignedRuns(runsByProduct TestRunsByProduct) []TestRuns {
+	if len(runsByProduct) == 0 {
+		return nil
+	}
+
+	var revisions []string
+	byRevision := make(map[string]TestRuns)
+	for i, productRuns := range runsByProduct {
+		for _, run := range productRuns.TestRuns {
+			runs, ok := byRevision[run.Revision]
+			if !ok {
+				if i > 0 {
+					// Not run for an earlier product, so can't be aligned.
+					continue
+				}
+				runs = make(TestRuns, len(runsByProduct))
+				revisions = append(revisions, run.Revision)
+			}
+			if runs[i].ID == 0 {
+				runs[i] = run
+			}
+			byRevision[run.Revision] = runs
+		}
+	}
+
+	var aligned []TestRuns
+	for _, revision := range revisions {
+		runs := byRevision[revision]
+		complete := true
+		for _, run := range runs {
+			if run.ID == 0 {
+				complete = false
+
+				break
+			}
+		}
+		if complete {
+			aligned = append(aligned, runs)
+		}
+	}
+
+	return aligned
+}
+
+// summaryV2Result is the format of a test's entry in a "-summary_v2.json.gz"
+// results summary file.
+type summaryV2Result struct {
+	Status string `json:"s"`
+	Counts []int  `json:"c"`
+}
+
+// FetchRunResultsSummary fetches the [pass, total] results summary of the
+// given run, supporting both the legacy and the "-summary_v2" formats.
+func FetchRunResultsSummary(client *http.Client, run TestRun) (ResultsSummary, error) {
+	url := strings.TrimSpace(run.ResultsURL)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned HTTP status %d", url, resp.StatusCode)
+	}
+
+	return ParseResultsSummary(url, body)
+}
+
+// ParseResultsSummary parses a results summary file, which is in the
+// "-summary_v2" format if its URL says so.
+func ParseResultsSummary(url string, body []byte) (ResultsSummary, error) {
+	if !strings.HasSuffix(url, "-summary_v2.json.gz") {
+		var results ResultsSummary
+		if err := json.Unmarshal(body, &results); err != nil {
+			return nil, err
+		}
+
+		return results, nil
+	}
+
+	var v2 map[string]summaryV2Result
+	if err := json.Unmarshal(body, &v2); err != nil {
+		return nil, err
+	}
+	results := make(ResultsSummary, len(v2))
+	for test, result := range v2 {
+		results[test] = TestSummary(result.Counts)
+	}
+
+	return results, nil
+}
+
+// FetchAlignedRunsBSF fetches the summaries of the given aligned runs and
+// computes their BSF data.
+func FetchAlignedRunsBSF(ctx context.Context, products ProductSpecs, alignedRuns []TestRuns) (BSFData, error) {
+	client := NewAppEngineAPI(ctx).GetHTTPClientWithTimeout(time.Minute)
+	summaries := make([][]ResultsSummary, len(alignedRuns))
+	for i, runs := range alignedRuns {
+		var err error
+		if summaries[i], err = fetchRunsResultsSummaries(client, runs); err != nil {
+			return BSFData{}, err
+		}
+	}
+
+	return NewBSFDataForRuns(products, alignedRuns, summaries), nil
+}
+
+// BSFBreakdown is the browser-specific failure data of a single revision,
+// broken down by directory and, optionally, by test.
+type BSFBreakdown struct {
+	Revision string   `json:"revision"`
+	Products []string `json:"products"`
+	// Path is the directory the breakdown is limited to.
+	Path string `json:"path"`
+	// Directories are the directories immediately below Path.
+	Directories []BSFBreakdownEntry `json:"directories"`
+	Tests       []BSFBreakdownEntry `json:"tests,omitempty"`
+}
+
+// BSFBreakdownEntry is the BSF contribution of a directory or test, with one
+// score per product.
+type BSFBreakdownEntry struct {
+	Path   string    `json:"path"`
+	Scores []float64 `json:"scores"`
+}
+
+// NewBSFBreakdown computes the BSF contributions of the directories
+// immediately below the given path (or the top-level directories, if path is
+// empty or "/") for a set of aligned runs; see ComputeBSFTestScores. Only
+// tests under path are considered, and they are listed individually if
+// includeTests is true. Entries without any BSF contribution are omitted.
+func NewBSFBreakdown(
+	products ProductSpecs,
+	runs TestRuns,
+	summaries []ResultsSummary,
+	path string,
+	includeTests bool,
+) BSFBreakdown {
+	dir := "/" + strings.Trim(path, "/")
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	breakdown := BSFBreakdown{Path: dir, Directories: []BSFBreakdownEntry{}}
+	if len(runs) > 0 {
+		breakdown.Revision = runs[0].FullRevisionHash
+	}
+	for _, product := range products {
+		breakdown.Products = append(breakdown.Products, product.String())
+	}
+
+	dirScores := make(map[string][]float64)
+	for test, scores := range ComputeBSFTestScores(summaries) {
+		if test != dir && !strings.HasPrefix(test, prefix) {
+			continue
+		}
+		// Tests directly in the path are their own entry.
+		child := test
+		if rest := strings.TrimPrefix(test, prefix); strings.Contains(rest, "/") {
+			child = prefix + rest[:strings.Index(rest, "/")]
+		}
+		if _, ok := dirScores[child]; !ok {
+			dirScores[child] = make([]float64, len(scores))
+		}
+		for i, score := range scores {
+			dirScores[child][i] += score
+		}
+		if includeTests {
+			breakdown.Tests = append(breakdown.Tests, BSFBreakdownEntry{Path: test, Scores: scores})
+		}
+	}
+	for child, scores := range dirScores {
+		breakdown.Directories = append(breakdown.Directories, BSFBreakdownEntry{Path: child, Scores: scores})
+	}
+	sort.Slice(breakdown.Directories, func(i, j int) bool {
+		return breakdown.Directories[i].Path < breakdown.Directories[j].Path
+	})
+	sort.Slice(breakdown.Tests, func(i, j int) bool {
+		return breakdown.Tests[i].Path < breakdown.Tests[j].Path
+	})
+
+	return breakdown
+}
+
+// FetchAlignedRunsBSFBreakdown fetches the summaries of the given aligned runs
+// and computes their BSF breakdown; see NewBSFBreakdown.
+func FetchAlignedRunsBSFBreakdown(
+	ctx context.Context,
+	products ProductSpecs,
+	runs TestRuns,
+	path string,
+	includeTests bool,
+) (BSFBreakdown, error) {
+	client := NewAppEngineAPI(ctx).GetHTTPClientWithTimeout(time.Minute)
+	summaries, err := fetchRunsResultsSummaries(client, runs)
+	if err != nil {
+		return BSFBreakdown{}, err
+	}
+
+	return NewBSFBreakdown(products, runs, summaries, path, includeTests), nil
+}
+
+func fetchRunsResultsSummaries(client *http.Client, runs TestRuns) ([]ResultsSummary, error) {
+	summaries := make([]ResultsSummary, len(runs))
+	for i, run := range runs {
+		summary, err := FetchRunResultsSummary(client, run)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load summary for run %v: %w", run.ID, err)
+		}
+		summaries[i] = summary
+	}
+
+	return summaries, nil
+}
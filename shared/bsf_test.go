@@ -0,0 +1,166 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBSFTestScores(t *testing.T) {
+	summaries := []ResultsSummary{
+		{
+			"/a.html": {1, 4},
+			"/b.html": {0, 1},
+			"/c.html": {2, 2},
+			"/d.html": {0, 2},
+			"/e.html": {0, 1},
+		},
+		{
+			"/a.html": {4, 4},
+			"/b.html": {1, 1},
+			"/c.html": {2, 2},
+			"/d.html": {1, 2},
+		},
+		{
+			"/a.html": {4, 4},
+			"/b.html": {1, 1},
+			"/c.html": {1, 2},
+			"/d.html": {2, 2},
+		},
+	}
+	scores := ComputeBSFTestScores(summaries)
+	assert.Equal(t, map[string][]float64{
+		// Fails only in the first run.
+		"/a.html": {0.75, 0, 0},
+		"/b.html": {1, 0, 0},
+		// Fails only in the last run.
+		"/c.html": {0, 0, 0.5},
+		// Fails everywhere but one; (1 - 0.5 - 0) for the first run.
+		"/d.html": {0.5, 0, 0},
+		// "/e.html" is missing from some runs, so can't be compared.
+	}, scores)
+
+	assert.Equal(t, []float64{2.25, 0, 0.5}, ComputeBSFScores(summaries))
+}
+
+func TestComputeBSFTestScores_SingleRun(t *testing.T) {
+	assert.Empty(t, ComputeBSFTestScores([]ResultsSummary{{"/a.html": {0, 1}}}))
+}
+
+func TestGroupAlignedRuns(t *testing.T) {
+	chrome := TestRun{ID: 1}
+	chrome.Revision = "abc"
+	chrome2 := TestRun{ID: 2}
+	chrome2.Revision = "def"
+	firefox := TestRun{ID: 3}
+	firefox.Revision = "abc"
+	firefox2 := TestRun{ID: 4}
+	firefox2.Revision = "123"
+
+	aligned := GroupAlignedRuns(TestRunsByProduct{
+		{TestRuns: TestRuns{chrome, chrome2}},
+		{TestRuns: TestRuns{firefox, firefox2}},
+	})
+	assert.Equal(t, []TestRuns{{chrome, firefox}}, aligned)
+}
+
+func TestNewBSFDataForRuns(t *testing.T) {
+	products, _ := ParseProductSpecs("chrome", "firefox")
+	newRun := func(id int64, sha, version string, start time.Time) TestRun {
+		run := TestRun{ID: id, TimeStart: start}
+		run.FullRevisionHash = sha
+		run.BrowserVersion = version
+
+		return run
+	}
+	later := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	alignedRuns := []TestRuns{
+		{newRun(1, "bbb", "120", later), newRun(2, "bbb", "121", later)},
+		{newRun(3, "aaa", "119", earlier), newRun(4, "aaa", "120", earlier)},
+	}
+	summaries := [][]ResultsSummary{
+		{{"/a.html": {0, 2}}, {"/a.html": {2, 2}}},
+		{{"/a.html": {2, 2}}, {"/a.html": {1, 2}}},
+	}
+
+	data := NewBSFDataForRuns(products, alignedRuns, summaries)
+	assert.Equal(t, "bbb", data.LastUpdateRevision)
+	assert.Equal(t, []string{"sha", "date", "chrome-version", "chrome", "firefox-version", "firefox"}, data.Fields)
+	assert.Equal(t, [][]string{
+		{"aaa", "2026-01-01", "119", "0", "120", "0.5"},
+		{"bbb", "2026-02-02", "120", "1", "121", "0"},
+	}, data.Data)
+}
+
+func TestParseResultsSummary(t *testing.T) {
+	v2, err := ParseResultsSummary(
+		"https://example.com/chrome-summary_v2.json.gz",
+		[]byte(`{"/a.html":{"s":"O","c":[1,3]}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, ResultsSummary{"/a.html": {1, 3}}, v2)
+
+	v1, err := ParseResultsSummary(
+		"https://example.com/chrome-summary.json.gz",
+		[]byte(`{"/a.html":[1,3]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, ResultsSummary{"/a.html": {1, 3}}, v1)
+}
+
+func TestNewBSFBreakdown(t *testing.T) {
+	products, _ := ParseProductSpecs("chrome", "firefox")
+	chrome := TestRun{ID: 1}
+	chrome.FullRevisionHash = "abc"
+	firefox := TestRun{ID: 2}
+	firefox.FullRevisionHash = "abc"
+	summaries := []ResultsSummary{
+		{
+			"/css/grid/a.html": {0, 1},
+			"/css/grid/b.html": {1, 2},
+			"/css/flex/a.html": {1, 1},
+			"/css/top.html":    {1, 1},
+			"/dom/a.html":      {1, 1},
+		},
+		{
+			"/css/grid/a.html": {1, 1},
+			"/css/grid/b.html": {2, 2},
+			"/css/flex/a.html": {0, 1},
+			"/css/top.html":    {0, 4},
+			"/dom/a.html":      {1, 1},
+		},
+	}
+
+	breakdown := NewBSFBreakdown(products, TestRuns{chrome, firefox}, summaries, "", false)
+	assert.Equal(t, "abc", breakdown.Revision)
+	assert.Equal(t, []string{"chrome", "firefox"}, breakdown.Products)
+	assert.Equal(t, "/", breakdown.Path)
+	assert.Equal(t, []BSFBreakdownEntry{
+		{Path: "/css", Scores: []float64{1.5, 2}},
+	}, breakdown.Directories)
+	assert.Nil(t, breakdown.Tests)
+
+	breakdown = NewBSFBreakdown(products, TestRuns{chrome, firefox}, summaries, "/css/", true)
+	assert.Equal(t, "/css", breakdown.Path)
+	assert.Equal(t, []BSFBreakdownEntry{
+		{Path: "/css/flex", Scores: []float64{0, 1}},
+		{Path: "/css/grid", Scores: []float64{1.5, 0}},
+		{Path: "/css/top.html", Scores: []float64{0, 1}},
+	}, breakdown.Directories)
+	assert.Equal(t, []BSFBreakdownEntry{
+		{Path: "/css/flex/a.html", Scores: []float64{0, 1}},
+		{Path: "/css/grid/a.html", Scores: []float64{1, 0}},
+		{Path: "/css/grid/b.html", Scores: []float64{0.5, 0}},
+		{Path: "/css/top.html", Scores: []float64{0, 1}},
+	}, breakdown.Tests)
+
+	breakdown = NewBSFBreakdown(products, TestRuns{chrome, firefox}, summaries, "/dom", true)
+	assert.Empty(t, breakdown.Directories)
+	assert.Empty(t, breakdown.Tests)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import "time"
+
+// CINotificationKind is the Datastore kind of CINotification entities.
+const CINotificationKind = "CINotification"
+
+// CINotificationOutcome is the outcome of handling a CINotification.
+type CINotificationOutcome string
+
+// The outcomes of CINotifications.
+const (
+	// CINotificationReceived is the outcome of a notification which is still
+	// being handled, or whose handling was interrupted.
+	CINotificationReceived CINotificationOutcome = "received"
+	// CINotificationIgnored is the outcome of a notification which isn't
+	// about a build whose results are collected.
+	CINotificationIgnored CINotificationOutcome = "ignored"
+	// CINotificationInvalid is the outcome of a notification which couldn't
+	// be parsed, or wasn't authenticated.
+	CINotificationInvalid CINotificationOutcome = "invalid"
+	// CINotificationNoResults is the outcome of a notification of a build
+	// which has no results (yet).
+	CINotificationNoResults CINotificationOutcome = "no_results"
+	// CINotificationDuplicate is the outcome of a notification of a build
+	// whose artifacts were all processed by earlier notifications.
+	CINotificationDuplicate CINotificationOutcome = "duplicate"
+	// CINotificationFailed is the outcome of a notification whose handling
+	// failed, possibly after creating some runs; it can be replayed.
+	CINotificationFailed CINotificationOutcome = "failed"
+	// CINotificationSucceeded is the outcome of a notification which runs
+	// were created for.
+	CINotificationSucceeded CINotificationOutcome = "succeeded"
+)
+
+// CINotificationHeader is a header of a CINotification.
+type CINotificationHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CINotification is a notification from a CI system that a build has finished,
+// recorded with its outcome so that it can be replayed if handling it failed.
+type CINotification struct {
+	ID       int64  `json:"id" datastore:"-"`
+	Uploader string `json:"uploader"`
+	// BuildID identifies the build within its CI system, e.g. a task group or
+	// workflow run ID, if the notification could be parsed.
+	BuildID string `json:"build_id,omitempty"`
+
+	// Method, URL (the path and query), Headers and Payload are the raw
+	// request. Credential headers are not recorded.
+	Method  string                 `json:"method" datastore:",noindex"`
+	URL     string                 `json:"url" datastore:",noindex"`
+	Headers []CINotificationHeader `json:"headers,omitempty" datastore:",noindex"`
+	Payload string                 `json:"payload,omitempty" datastore:",noindex"`
+
+	Outcome CINotificationOutcome `json:"outcome"`
+	// Status is the HTTP status the notification was responded to with.
+	Status int    `json:"status,omitempty" datastore:",noindex"`
+	Error  string `json:"error,omitempty" datastore:",noindex"`
+	// Artifacts are the names of the artifacts of the build which runs were
+	// created for, which are skipped by later notifications of the build.
+	Artifacts []string `json:"artifacts,omitempty" datastore:",noindex"`
+	// Runs are the IDs of the pending runs created for the artifacts.
+	Runs []int64 `json:"runs,omitempty" datastore:",noindex"`
+	// Replays is the number of times the notification was replayed.
+	Replays int `json:"replays,omitempty" datastore:",noindex"`
+	// Verified is whether the credentials of the notification were verified
+	// when it was received. Some can't be verified again when it's replayed,
+	// e.g. unrecorded tokens, or signed timestamps which have gone stale.
+	Verified bool `json:"verified,omitempty" datastore:",noindex"`
+
+	Received time.Time `json:"received"`
+	Updated  time.Time `json:"updated"`
+}
@@ -30,6 +30,13 @@ func (d cachedDatastore) Get(k Key, dst interface{}) error {
 	return cs.Get(getTestRunRedisKey(k.IntID()), k.IntID(), dst)
 }
 
+// DeleteCachedTestRun evicts the test run with the given ID from Redis, so that
+// the next read of the run goes to Datastore. It should be called after
+// modifying an existing TestRun entity.
+func DeleteCachedTestRun(id int64) error {
+	return DeleteCache(getTestRunRedisKey(id))
+}
+
 func (d cachedDatastore) GetMulti(keys []Key, dst interface{}) error {
 	for _, key := range keys {
 		if key.Kind() != "TestRun" {
@@ -35,6 +35,8 @@ type GitHubAccessControl interface {
 	// IsValid* functions also verify the access token with GitHub.
 	IsValidWPTMember() (bool, error)
 	IsValidAdmin() (bool, error)
+	// User returns the logged-in GitHub user.
+	User() *User
 }
 
 type githubAccessControlImpl struct {
@@ -176,6 +178,10 @@ func (gaci githubAccessControlImpl) IsValidAdmin() (bool, error) {
 	return true, nil
 }
 
+func (gaci githubAccessControlImpl) User() *User {
+	return gaci.user
+}
+
 // NewGitHubAccessControl returns a GitHubAccessControl for checking the
 // permission of a logged-in GitHub user.
 func NewGitHubAccessControl(ctx context.Context, ds Datastore, botClient *github.Client, user *User, token string) (GitHubAccessControl, error) {
@@ -0,0 +1,256 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InteropConfigVersion is the latest schema version of the interop config
+// that this code understands.
+const InteropConfigVersion = 1
+
+// InteropConfigFileName is the path of the interop config within the
+// wpt-metadata repo.
+const InteropConfigFileName = "interop.yml"
+
+// InteropConfigPathEnv is the environment variable that, when set, points to
+// an interop config on disk which overrides the embedded default.
+const InteropConfigPathEnv = "INTEROP_CONFIG_PATH"
+
+// interop.yml is generated from webapp/static/interop-data.json, which the
+// frontend reads, so that the two can't disagree.
+//go:generate go run ../util/interop_config ../webapp/static/interop-data.json interop.yml
+
+//go:embed interop.yml
+var defaultInteropConfig []byte
+
+// ErrInvalidInteropConfig is returned when an interop config fails validation.
+var ErrInvalidInteropConfig = errors.New("invalid interop config")
+
+// InteropConfig is the versioned definition of the Interop dashboards: the
+// years that exist, and the categories and focus areas of each year.
+type InteropConfig struct {
+	Version     int           `yaml:"version"      json:"version"`
+	DefaultYear string        `yaml:"default_year" json:"default_year"`
+	Years       []InteropYear `yaml:"years"        json:"years"`
+}
+
+// InteropYear is a single year of Interop.
+type InteropYear struct {
+	Year       string            `yaml:"year"             json:"year"`
+	Mobile     bool              `yaml:"mobile,omitempty" json:"mobile"`
+	Categories []InteropCategory `yaml:"categories"       json:"categories"`
+}
+
+// InteropCategory is a named group of focus areas, e.g. "Active Focus Areas".
+type InteropCategory struct {
+	Name       string             `yaml:"name"        json:"name"`
+	FocusAreas []InteropFocusArea `yaml:"focus_areas" json:"focus_areas"`
+}
+
+// InteropFocusArea is a single focus area, whose tests are selected by the
+// given wpt-metadata labels.
+type InteropFocusArea struct {
+	ID                string   `yaml:"id"                  json:"id"`
+	Description       string   `yaml:"description"         json:"description"`
+	Labels            []string `yaml:"labels"              json:"labels"`
+	Spec              string   `yaml:"spec,omitempty"      json:"spec,omitempty"`
+	MDN               string   `yaml:"mdn,omitempty"       json:"mdn,omitempty"`
+	Mobile            bool     `yaml:"mobile,omitempty"    json:"mobile"`
+	CountsTowardScore bool     `yaml:"counts_toward_score" json:"counts_toward_score"`
+}
+
+// ParseInteropConfig parses and validates a YAML interop config.
+func ParseInteropConfig(data []byte) (*InteropConfig, error) {
+	var config InteropConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// interopData is the subset of the frontend's interop-data.json format that
+// the interop config is generated from.
+type interopData struct {
+	ValidYears       []string `json:"valid_years"`
+	ValidMobileYears []string `json:"valid_mobile_years"`
+}
+
+type interopDataYear struct {
+	TableSections []struct {
+		Name string   `json:"name"`
+		Rows []string `json:"rows"`
+	} `json:"table_sections"`
+	MobileFocusAreas []string `json:"mobile_focus_areas"`
+	FocusAreas       map[string]struct {
+		Description       string   `json:"description"`
+		Labels            []string `json:"labels"`
+		Spec              string   `json:"spec"`
+		MDN               string   `json:"mdn"`
+		CountsTowardScore bool     `json:"countsTowardScore"`
+	} `json:"focus_areas"`
+}
+
+// ConvertInteropData converts the frontend's interop-data.json to an interop
+// config. The sections of each year's table become its categories, keeping
+// only their rows which are focus areas, and the latest year is the default.
+func ConvertInteropData(data []byte) (*InteropConfig, error) {
+	var parsed interopData
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	var years map[string]json.RawMessage
+	if err := json.Unmarshal(data, &years); err != nil {
+		return nil, err
+	}
+	mobileYears := NewSetFromStringSlice(parsed.ValidMobileYears)
+
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	config := InteropConfig{Version: InteropConfigVersion}
+	for _, year := range parsed.ValidYears {
+		var yearData interopDataYear
+		if err := json.Unmarshal(years[year], &yearData); err != nil {
+			return nil, fmt.Errorf("%w: year %s: %s", ErrInvalidInteropConfig, year, err.Error())
+		}
+		mobileFocusAreas := NewSetFromStringSlice(yearData.MobileFocusAreas)
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		interopYear := InteropYear{Year: year, Mobile: mobileYears.Contains(year)}
+		for _, section := range yearData.TableSections {
+			// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+			category := InteropCategory{Name: section.Name}
+			for _, id := range section.Rows {
+				focusArea, ok := yearData.FocusAreas[id]
+				if !ok {
+					// E.g. investigations, which aren't focus areas.
+					continue
+				}
+				category.FocusAreas = append(category.FocusAreas, InteropFocusArea{
+					ID:                id,
+					Description:       focusArea.Description,
+					Labels:            focusArea.Labels,
+					Spec:              focusArea.Spec,
+					MDN:               focusArea.MDN,
+					Mobile:            mobileFocusAreas.Contains(id),
+					CountsTowardScore: focusArea.CountsTowardScore,
+				})
+			}
+			if len(category.FocusAreas) > 0 {
+				interopYear.Categories = append(interopYear.Categories, category)
+			}
+		}
+		config.Years = append(config.Years, interopYear)
+	}
+	if len(parsed.ValidYears) > 0 {
+		config.DefaultYear = parsed.ValidYears[len(parsed.ValidYears)-1]
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// LoadInteropConfigFile reads and parses an interop config from disk.
+func LoadInteropConfigFile(path string) (*InteropConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseInteropConfig(data)
+}
+
+// FetchInteropConfig fetches and parses the interop config from the
+// wpt-metadata repo at the given ref (master if nil or empty).
+func FetchInteropConfig(client *http.Client, ref *string) (*InteropConfig, error) {
+	data, err := GetWPTMetadataArchiveFile(client, ref, InteropConfigFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseInteropConfig(data)
+}
+
+var (
+	interopConfigOnce sync.Once
+	interopConfig     *InteropConfig
+	interopConfigErr  error
+)
+
+// GetInteropConfig returns the interop config used by the webapp. It is read
+// from InteropConfigPathEnv if set, falling back to the default config that is
+// embedded in the binary. The result is loaded once and cached.
+func GetInteropConfig() (*InteropConfig, error) {
+	interopConfigOnce.Do(func() {
+		if path := os.Getenv(InteropConfigPathEnv); path != "" {
+			interopConfig, interopConfigErr = LoadInteropConfigFile(path)
+
+			return
+		}
+		interopConfig, interopConfigErr = ParseInteropConfig(defaultInteropConfig)
+	})
+
+	return interopConfig, interopConfigErr
+}
+
+// Validate checks that the config has a supported version, that years are
+// unique, and that the default year is one of them.
+func (c InteropConfig) Validate() error {
+	if c.Version < 1 || c.Version > InteropConfigVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidInteropConfig, c.Version)
+	}
+	years := make(map[string]bool, len(c.Years))
+	for _, y := range c.Years {
+		if y.Year == "" {
+			return fmt.Errorf("%w: year must not be empty", ErrInvalidInteropConfig)
+		}
+		if years[y.Year] {
+			return fmt.Errorf("%w: duplicate year %s", ErrInvalidInteropConfig, y.Year)
+		}
+		years[y.Year] = true
+	}
+	if !years[c.DefaultYear] {
+		return fmt.Errorf("%w: default year %q is not a configured year", ErrInvalidInteropConfig, c.DefaultYear)
+	}
+
+	return nil
+}
+
+// GetYear returns the config for the given year, or nil if it doesn't exist.
+func (c InteropConfig) GetYear(year string) *InteropYear {
+	for i := range c.Years {
+		if c.Years[i].Year == year {
+			return &c.Years[i]
+		}
+	}
+
+	return nil
+}
+
+// IsValidYear returns whether the given year has an Interop dashboard.
+func (c InteropConfig) IsValidYear(year string) bool {
+	return c.GetYear(year) != nil
+}
+
+// IsValidMobileYear returns whether the given year has a mobile Interop
+// dashboard.
+func (c InteropConfig) IsValidMobileYear(year string) bool {
+	y := c.GetYear(year)
+
+	return y != nil && y.Mobile
+}
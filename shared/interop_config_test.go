@@ -0,0 +1,99 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInteropConfig_Default(t *testing.T) {
+	config, err := ParseInteropConfig(defaultInteropConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, InteropConfigVersion, config.Version)
+	assert.True(t, config.IsValidYear(config.DefaultYear))
+	assert.True(t, config.IsValidYear("2021"))
+	assert.False(t, config.IsValidYear("1999"))
+	assert.False(t, config.IsValidMobileYear("2021"))
+	assert.True(t, config.IsValidMobileYear("2024"))
+
+	year := config.GetYear("2021")
+	assert.NotNil(t, year)
+	assert.NotEmpty(t, year.Categories)
+	for _, category := range year.Categories {
+		for _, fa := range category.FocusAreas {
+			assert.NotEmpty(t, fa.ID)
+			assert.NotEmpty(t, fa.Labels)
+		}
+	}
+}
+
+func TestParseInteropConfig_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unsupported version": "version: 99\ndefault_year: \"2024\"\nyears:\n  - year: \"2024\"\n",
+		"missing version":     "default_year: \"2024\"\nyears:\n  - year: \"2024\"\n",
+		"duplicate year":      "version: 1\ndefault_year: \"2024\"\nyears:\n  - year: \"2024\"\n  - year: \"2024\"\n",
+		"unknown default":     "version: 1\ndefault_year: \"2025\"\nyears:\n  - year: \"2024\"\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseInteropConfig([]byte(data))
+			assert.True(t, errors.Is(err, ErrInvalidInteropConfig))
+		})
+	}
+}
+
+func TestGetInteropConfig(t *testing.T) {
+	config, err := GetInteropConfig()
+	assert.Nil(t, err)
+	assert.NotNil(t, config.GetYear(config.DefaultYear))
+}
+
+func TestConvertInteropData(t *testing.T) {
+	// The embedded config must be regenerated whenever the frontend's data
+	// changes; see the go:generate directive in interop_config.go.
+	data, err := os.ReadFile("../webapp/static/interop-data.json")
+	assert.Nil(t, err)
+	converted, err := ConvertInteropData(data)
+	assert.Nil(t, err)
+	config, err := ParseInteropConfig(defaultInteropConfig)
+	assert.Nil(t, err)
+	assert.Equal(t, config, converted)
+}
+
+func TestConvertInteropData_Sections(t *testing.T) {
+	data := `{
+  "valid_years": ["2024", "2025"],
+  "valid_mobile_years": ["2025"],
+  "2024": {"table_sections": [], "focus_areas": {}},
+  "2025": {
+    "table_sections": [
+      {"name": "Active Focus Areas", "rows": ["a", "b"]},
+      {"name": "Active Investigations", "rows": ["investigation"]}
+    ],
+    "mobile_focus_areas": ["b"],
+    "focus_areas": {
+      "a": {"description": "A", "labels": ["interop-a"], "countsTowardScore": true},
+      "b": {"description": "B", "labels": ["interop-b"], "mdn": "https://mdn/b"}
+    }
+  }
+}`
+	config, err := ConvertInteropData([]byte(data))
+	assert.Nil(t, err)
+	assert.Equal(t, "2025", config.DefaultYear)
+	assert.False(t, config.IsValidMobileYear("2024"))
+	assert.True(t, config.IsValidMobileYear("2025"))
+	assert.Equal(t, []InteropCategory{{
+		Name: "Active Focus Areas",
+		FocusAreas: []InteropFocusArea{
+			{ID: "a", Description: "A", Labels: []string{"interop-a"}, CountsTowardScore: true},
+			{ID: "b", Description: "B", Labels: []string{"interop-b"}, MDN: "https://mdn/b", Mobile: true},
+		},
+	}}, config.GetYear("2025").Categories)
+}
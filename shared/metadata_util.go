@@ -58,34 +58,91 @@ func GetWPTMetadataArchive(client *http.Client, ref *string) (res map[string][]b
 }
 
 func getWPTMetadataArchiveWithURL(client *http.Client, url string, ref *string) (res map[string][]byte, err error) {
+	err = readWPTMetadataArchive(client, url, ref, func(gzip *gzip.Reader) error {
+		res, err = parseMetadataFromGZip(gzip)
+
+		return err
+	})
+
+	return res, err
+}
+
+// GetWPTMetadataArchiveFile returns the content of a single file, given by its
+// path relative to the root of the wpt-metadata repository, at a given ref.
+func GetWPTMetadataArchiveFile(client *http.Client, ref *string, path string) ([]byte, error) {
+	return getWPTMetadataArchiveFileWithURL(client, "https://api.github.com/repos/web-platform-tests/wpt-metadata/tarball", ref, path)
+}
+
+func getWPTMetadataArchiveFileWithURL(client *http.Client, url string, ref *string, path string) ([]byte, error) {
+	var files map[string][]byte
+	err := readWPTMetadataArchive(client, url, ref, func(gzip *gzip.Reader) (err error) {
+		files, err = readFilesFromGZip(gzip, func(name string) bool { return name == path })
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, ok := files[path]
+	if !ok {
+		return nil, fmt.Errorf("%s not found in wpt-metadata", path)
+	}
+
+	return data, nil
+}
+
+// readWPTMetadataArchive downloads the wpt-metadata tarball at the given ref,
+// and streams it to read, before the response is closed.
+func readWPTMetadataArchive(client *http.Client, url string, ref *string, read func(*gzip.Reader) error) error {
 	if ref != nil && *ref != "" {
 		url = url + "/" + *ref
 	}
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	statusCode := resp.StatusCode
 	if !(statusCode >= 200 && statusCode <= 299) {
 		err := fmt.Errorf("bad status code:%d, Unable to download wpt-metadata", statusCode)
-		return nil, err
+
+		return err
 	}
 
 	gzip, err := gzip.NewReader(resp.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return parseMetadataFromGZip(gzip)
+
+	return read(gzip)
 }
 
 func parseMetadataFromGZip(gzip *gzip.Reader) (res map[string][]byte, err error) {
+	files, err := readFilesFromGZip(gzip, func(name string) bool {
+		return strings.HasSuffix(name, MetadataFileName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var metadataMap = make(map[string][]byte, len(files))
+	for name, data := range files {
+		metadataMap[strings.TrimSuffix(name, "/"+MetadataFileName)] = data
+	}
+
+	return metadataMap, nil
+}
+
+// readFilesFromGZip reads all regular files in a GitHub tarball whose path,
+// relative to the repository root, satisfies match. The result is keyed by
+// that relative path.
+func readFilesFromGZip(gzip *gzip.Reader, match func(name string) bool) (map[string][]byte, error) {
 	defer gzip.Close()
 
 	tarReader := tar.NewReader(gzip)
-	var metadataMap = make(map[string][]byte)
+	var files = make(map[string][]byte)
 	for {
 		header, err := tarReader.Next()
 
@@ -102,7 +159,9 @@ func parseMetadataFromGZip(gzip *gzip.Reader) (res map[string][]byte, err error)
 			continue
 		}
 
-		if !strings.HasSuffix(header.Name, "META.yml") {
+		// Removes `owner-repo` prefix in the file name.
+		relativeFileName := header.Name[strings.Index(header.Name, "/")+1:]
+		if !match(relativeFileName) {
 			continue
 		}
 
@@ -110,12 +169,8 @@ func parseMetadataFromGZip(gzip *gzip.Reader) (res map[string][]byte, err error)
 		if err != nil && err != io.EOF {
 			return nil, err
 		}
-
-		// Removes `owner-repo` prefix in the file name.
-		relativeFileName := header.Name[strings.Index(header.Name, "/")+1:]
-		relativeFileName = strings.TrimSuffix(relativeFileName, "/META.yml")
-		metadataMap[relativeFileName] = data
+		files[relativeFileName] = data
 	}
 
-	return metadataMap, nil
+	return files, nil
 }
@@ -81,3 +81,18 @@ func TestParseMetadataFromGZip_Success(t *testing.T) {
 	assert.True(t, exist)
 	assert.Equal(t, expectedValTheHistoryInterface, string(val))
 }
+
+func TestGetWPTMetadataArchiveFileWithURL(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "../shared/metadata_testdata/util_gzip_testfile.tar.gz")
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	data, err := getWPTMetadataArchiveFileWithURL(server.Client(), server.URL, nil, "IndexedDB/META.yml")
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "bindings-inject-key.html")
+
+	_, err = getWPTMetadataArchiveFileWithURL(server.Client(), server.URL, nil, "interop.yml")
+	assert.NotNil(t, err)
+}
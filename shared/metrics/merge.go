@@ -0,0 +1,188 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MergeConflictKind is the kind of a MergeConflict.
+type MergeConflictKind string
+
+const (
+	// ConflictDuplicateTest means the same test was found in more than one
+	// chunk (or more than once in one chunk).
+	ConflictDuplicateTest MergeConflictKind = "duplicate_test"
+	// ConflictRunInfo means a chunk's run_info doesn't match the first chunk's.
+	ConflictRunInfo MergeConflictKind = "run_info"
+)
+
+// MergeConflict describes why chunks of a sharded run can't be merged.
+type MergeConflict struct {
+	Kind MergeConflictKind `json:"kind"`
+	// Chunks are the (0-based) indices of the conflicting chunks.
+	Chunks []int `json:"chunks"`
+	// Test is the conflicting test, for ConflictDuplicateTest.
+	Test string `json:"test,omitempty"`
+	// Fields are the mismatched run_info fields, for ConflictRunInfo.
+	Fields  []string `json:"fields,omitempty"`
+	Message string   `json:"message"`
+}
+
+// MergeConflictError is the error returned by MergeReports if the reports
+// conflict with each other.
+type MergeConflictError struct {
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+func (e MergeConflictError) Error() string {
+	messages := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		messages[i] = c.Message
+	}
+	return fmt.Sprintf("%d merge conflicts: %s", len(e.Conflicts), strings.Join(messages, "; "))
+}
+
+// mergeRunInfoFields are the run_info fields which must match across the
+// chunks of a run; the others (e.g. "debug" or "headless") are taken from the
+// first chunk.
+var mergeRunInfoFields = []string{"product", "browser_version", "os", "os_version", "revision"}
+
+// mergeResult is the part of a result needed to merge it; results are
+// otherwise kept as they are.
+type mergeResult struct {
+	Test string `json:"test"`
+}
+
+// MergeReports merges the (JSON) wptreports of the chunks of a sharded run
+// into a single report, with the results of the chunks in order, the earliest
+// time_start and latest time_end of the chunks, and the run_info and other
+// fields of the first chunk. The reports are merged as raw JSON, so that no
+// fields are lost. It returns a MergeConflictError listing all the conflicts
+// found if the same test is in more than one chunk, or the run_info of the
+// chunks doesn't match.
+func MergeReports(chunks [][]byte) ([]byte, error) {
+	if len(chunks) == 0 {
+		return nil, errors.New("no reports to merge")
+	}
+	reports := make([]map[string]json.RawMessage, len(chunks))
+	runInfos := make([]map[string]json.RawMessage, len(chunks))
+	for i, chunk := range chunks {
+		if err := json.Unmarshal(chunk, &reports[i]); err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", i, err)
+		}
+		if runInfo, ok := reports[i]["run_info"]; ok {
+			if err := json.Unmarshal(runInfo, &runInfos[i]); err != nil {
+				return nil, fmt.Errorf("chunk %d: invalid run_info: %w", i, err)
+			}
+		}
+	}
+
+	var conflicts []MergeConflict
+	var results []json.RawMessage
+	var timeStart, timeEnd *int64
+	// The chunk each test was first found in.
+	seen := make(map[string]int)
+	for i, report := range reports {
+		if fields := diffRunInfo(runInfos[0], runInfos[i]); len(fields) > 0 {
+			conflicts = append(conflicts, MergeConflict{
+				Kind:    ConflictRunInfo,
+				Chunks:  []int{0, i},
+				Fields:  fields,
+				Message: fmt.Sprintf("run_info of chunk %d differs from chunk 0 in %s", i, strings.Join(fields, ", ")),
+			})
+		}
+		var chunkResults []json.RawMessage
+		if err := unmarshalOptional(report["results"], &chunkResults); err != nil {
+			return nil, fmt.Errorf("chunk %d: invalid results: %w", i, err)
+		}
+		for _, raw := range chunkResults {
+			var result *mergeResult
+			if err := json.Unmarshal(raw, &result); err != nil {
+				return nil, fmt.Errorf("chunk %d: invalid result: %w", i, err)
+			} else if result == nil {
+				continue
+			}
+			if first, ok := seen[result.Test]; ok {
+				conflicts = append(conflicts, MergeConflict{
+					Kind:    ConflictDuplicateTest,
+					Chunks:  []int{first, i},
+					Test:    result.Test,
+					Message: fmt.Sprintf("%s is in both chunk %d and chunk %d", result.Test, first, i),
+				})
+				continue
+			}
+			seen[result.Test] = i
+			results = append(results, raw)
+		}
+		var start, end *int64
+		if err := unmarshalOptional(report["time_start"], &start); err != nil {
+			return nil, fmt.Errorf("chunk %d: invalid time_start: %w", i, err)
+		} else if err := unmarshalOptional(report["time_end"], &end); err != nil {
+			return nil, fmt.Errorf("chunk %d: invalid time_end: %w", i, err)
+		}
+		if start != nil && (timeStart == nil || *start < *timeStart) {
+			timeStart = start
+		}
+		if end != nil && (timeEnd == nil || *end > *timeEnd) {
+			timeEnd = end
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, MergeConflictError{Conflicts: conflicts}
+	}
+
+	merged := reports[0]
+	var err error
+	if results == nil {
+		results = []json.RawMessage{}
+	}
+	if merged["results"], err = json.Marshal(results); err != nil {
+		return nil, err
+	}
+	if timeStart != nil {
+		merged["time_start"] = json.RawMessage(strconv.FormatInt(*timeStart, 10))
+	}
+	if timeEnd != nil {
+		merged["time_end"] = json.RawMessage(strconv.FormatInt(*timeEnd, 10))
+	}
+	return json.Marshal(merged)
+}
+
+// unmarshalOptional unmarshals data into v, unless data is empty (i.e. the
+// field is missing).
+func unmarshalOptional(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// diffRunInfo returns the names of the mergeRunInfoFields which differ between
+// the run_info objects a and b.
+func diffRunInfo(a, b map[string]json.RawMessage) []string {
+	var fields []string
+	for _, name := range mergeRunInfoFields {
+		if !bytes.Equal(compactJSON(a[name]), compactJSON(b[name])) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// compactJSON returns data without insignificant whitespace, so that equal
+// values compare equal.
+func compactJSON(data json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
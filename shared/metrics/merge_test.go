@@ -0,0 +1,101 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeReports(t *testing.T) {
+	runInfo := `{"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc"}`
+	merged, err := MergeReports([][]byte{
+		[]byte(`{"results": [{"test": "/a.html", "status": "OK"}], "run_info": ` + runInfo + `}`),
+		[]byte(`{"results": [{"test": "/b.html", "status": "OK"}, {"test": "/c.html", "status": "OK"}], "run_info": ` + runInfo + `}`),
+	})
+	assert.Nil(t, err)
+	var report TestResultsReport
+	assert.Nil(t, json.Unmarshal(merged, &report))
+	tests := make([]string, len(report.Results))
+	for i, result := range report.Results {
+		tests[i] = result.Test
+	}
+	assert.Equal(t, []string{"/a.html", "/b.html", "/c.html"}, tests)
+}
+
+func TestMergeReports_keepsAllFields(t *testing.T) {
+	merged, err := MergeReports([][]byte{
+		[]byte(`{
+			"results": [{"test": "/a.html", "status": "OK", "expected": "OK", "duration": 12, "known_intermittent": ["TIMEOUT"],
+				"subtests": [{"name": "x", "status": "FAIL", "expected": "PASS", "message": null}]}],
+			"run_info": {"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc", "debug": false},
+			"time_start": 200,
+			"time_end": 300,
+			"lsan_leaks": []
+		}`),
+		[]byte(`{
+			"results": [{"test": "/b.html", "status": "ERROR", "expected": "OK"}],
+			"run_info": {"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc", "debug": true},
+			"time_start": 100,
+			"time_end": 250
+		}`),
+	})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"results": [
+			{"test": "/a.html", "status": "OK", "expected": "OK", "duration": 12, "known_intermittent": ["TIMEOUT"],
+				"subtests": [{"name": "x", "status": "FAIL", "expected": "PASS", "message": null}]},
+			{"test": "/b.html", "status": "ERROR", "expected": "OK"}
+		],
+		"run_info": {"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc", "debug": false},
+		"time_start": 100,
+		"time_end": 300,
+		"lsan_leaks": []
+	}`, string(merged))
+}
+
+func TestMergeReports_conflicts(t *testing.T) {
+	_, err := MergeReports([][]byte{
+		[]byte(`{"results": [{"test": "/a.html", "status": "OK"}],
+			"run_info": {"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc"}}`),
+		[]byte(`{"results": [{"test": "/b.html", "status": "OK"}],
+			"run_info": {"product": "chrome", "browser_version": "131", "os": "linux", "revision": "def"}}`),
+		[]byte(`{"results": [{"test": "/a.html", "status": "OK"}],
+			"run_info": {"product": "chrome", "browser_version": "130", "os": "linux", "revision": "abc"}}`),
+	})
+	var conflictErr MergeConflictError
+	assert.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, []MergeConflict{
+		{
+			Kind:    ConflictRunInfo,
+			Chunks:  []int{0, 1},
+			Fields:  []string{"browser_version", "revision"},
+			Message: "run_info of chunk 1 differs from chunk 0 in browser_version, revision",
+		},
+		{
+			Kind:    ConflictDuplicateTest,
+			Chunks:  []int{0, 2},
+			Test:    "/a.html",
+			Message: "/a.html is in both chunk 0 and chunk 2",
+		},
+	}, conflictErr.Conflicts)
+}
+
+func TestMergeReports_invalid(t *testing.T) {
+	_, err := MergeReports([][]byte{[]byte(`{"results": {}}`)})
+	assert.NotNil(t, err)
+	var conflictErr MergeConflictError
+	assert.False(t, errors.As(err, &conflictErr))
+}
+
+func TestMergeReports_empty(t *testing.T) {
+	_, err := MergeReports(nil)
+	assert.NotNil(t, err)
+}
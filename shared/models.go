@@ -7,6 +7,7 @@ package shared
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/datastore"
@@ -110,6 +111,10 @@ type TestRun struct {
 
 	// Labels for the test run.
 	Labels []string `json:"labels"`
+
+	// Invalid runs (e.g. from broken builds) are hidden from TestRunQuery,
+	// unless invalid runs are explicitly included.
+	Invalid bool `json:"invalid,omitempty"`
 }
 
 // IsExperimental returns true if the run is labelled experimental.
@@ -139,6 +144,17 @@ func (r TestRun) Channel() string {
 	return ""
 }
 
+// RepoLabel returns the label of the repo of the run, for the runs of repos
+// other than wpt, or the empty string for the runs of wpt.
+func (r TestRun) RepoLabel() string {
+	for _, label := range r.Labels {
+		if strings.HasPrefix(label, RepoLabelPrefix) {
+			return label
+		}
+	}
+	return ""
+}
+
 // Load is part of the datastore.PropertyLoadSaver interface.
 // We use it to reset all time to UTC and trim their monotonic clock.
 func (r *TestRun) Load(ps []datastore.Property) error {
@@ -174,8 +190,21 @@ const (
 	StageInvalid          PendingTestRunStage = 850
 	StageEmpty            PendingTestRunStage = 851
 	StageDuplicate        PendingTestRunStage = 852
+	StageTimedOut         PendingTestRunStage = 853
 )
 
+// IsTerminal returns whether the stage is final, i.e. processing of the run
+// has finished, successfully or not.
+func (s PendingTestRunStage) IsTerminal() bool {
+	return s >= StageValid
+}
+
+// IsProcessing returns whether the stage is one of wpt.fyi's processing of the
+// run (after it was received from CI), which can be re-scheduled.
+func (s PendingTestRunStage) IsProcessing() bool {
+	return s == StageWptFyiReceived || s == StageWptFyiProcessing
+}
+
 func (s PendingTestRunStage) String() string {
 	switch s {
 	case StageGitHubQueued:
@@ -202,6 +231,8 @@ func (s PendingTestRunStage) String() string {
 		return "EMPTY"
 	case StageDuplicate:
 		return "DUPLICATE"
+	case StageTimedOut:
+		return "TIMED_OUT"
 	}
 	return ""
 }
@@ -242,6 +273,8 @@ func (s *PendingTestRunStage) UnmarshalJSON(b []byte) error {
 		*s = StageEmpty
 	case "DUPLICATE":
 		*s = StageDuplicate
+	case "TIMED_OUT":
+		*s = StageTimedOut
 	default:
 		return fmt.Errorf("unknown stage: %s", str)
 	}
@@ -260,21 +293,134 @@ type PendingTestRun struct {
 	Uploader   string              `json:"uploader"`
 	Error      string              `json:"error" datastore:",noindex,omitempty"`
 	Stage      PendingTestRunStage `json:"stage"`
+	// DuplicatePolicy is what the uploader asked to be done if this run
+	// duplicates an existing run, and DuplicateOf is the ID of the existing
+	// run which it duplicated.
+	DuplicateOf     int64              `json:"duplicate_of,omitempty" datastore:",omitempty"`
+	DuplicatePolicy DuplicateRunPolicy `json:"duplicate_policy,omitempty" datastore:",noindex,omitempty"`
+	// NotifyURL is POSTed the run when it reaches a terminal stage (each
+	// terminal stage it reaches, since a run which timed out may still
+	// finish), and Deliveries are the attempts to do so.
+	NotifyURL  string             `json:"notify_url,omitempty" datastore:",noindex,omitempty"`
+	Deliveries []CallbackDelivery `json:"deliveries,omitempty" datastore:",noindex,omitempty"`
+	// ResultsTask is the URL-encoded payload of the results processing task,
+	// so that processing can be re-scheduled if the run gets stuck, and
+	// Reschedules is the number of times it has been.
+	ResultsTask string `json:"-" datastore:",noindex,omitempty"`
+	Reschedules int    `json:"reschedules,omitempty" datastore:",noindex,omitempty"`
+	// Transitions are the stages the run has been in, in order.
+	Transitions []PendingTestRunTransition `json:"transitions,omitempty" datastore:",noindex,omitempty"`
 
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
 }
 
+// DuplicateRunPolicy is what to do when a TestRun is created for the same
+// product, revision and labels as an existing run.
+type DuplicateRunPolicy string
+
+// The policies for duplicate runs.
+const (
+	// DuplicateRunReject rejects the new run.
+	DuplicateRunReject DuplicateRunPolicy = "reject"
+	// DuplicateRunReplace replaces the existing run with the new run.
+	DuplicateRunReplace DuplicateRunPolicy = "replace"
+	// DuplicateRunKeep keeps both runs, labelling the new run as a duplicate.
+	DuplicateRunKeep DuplicateRunPolicy = "keep"
+	// DuplicateRunAllow keeps both runs without looking for duplicates.
+	DuplicateRunAllow DuplicateRunPolicy = "allow"
+)
+
+// ParseDuplicateRunPolicy parses a DuplicateRunPolicy, which defaults to
+// DuplicateRunAllow if empty.
+func ParseDuplicateRunPolicy(policy string) (DuplicateRunPolicy, error) {
+	switch p := DuplicateRunPolicy(policy); p {
+	case "":
+		return DuplicateRunAllow, nil
+	case DuplicateRunReject, DuplicateRunReplace, DuplicateRunKeep, DuplicateRunAllow:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown duplicate run policy %q", policy)
+}
+
+// DuplicateRunError is returned when a TestRun is rejected for duplicating an
+// existing run.
+type DuplicateRunError struct {
+	ExistingID int64
+}
+
+func (e *DuplicateRunError) Error() string {
+	return fmt.Sprintf("duplicate of existing run %d", e.ExistingID)
+}
+
 // Transition sets Stage to next if the transition is allowed; otherwise an
-// error is returned.
+// error is returned. Stages only move forwards, except that a run which timed
+// out can move to any stage, since it may still finish late.
 func (s *PendingTestRun) Transition(next PendingTestRunStage) error {
-	if next == 0 || s.Stage > next {
+	if next == 0 || (s.Stage > next && s.Stage != StageTimedOut) {
 		return fmt.Errorf("cannot transition from %s to %s", s.Stage.String(), next.String())
 	}
 	s.Stage = next
 	return nil
 }
 
+// Delivered returns whether the run was successfully delivered to its
+// NotifyURL at its current stage.
+func (s PendingTestRun) Delivered() bool {
+	for _, d := range s.Deliveries {
+		if d.Stage == s.Stage && d.Error == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryAttempts returns the number of attempts to deliver the run to its
+// NotifyURL at its current stage.
+func (s PendingTestRun) DeliveryAttempts() int {
+	attempts := 0
+	for _, d := range s.Deliveries {
+		if d.Stage == s.Stage {
+			attempts++
+		}
+	}
+	return attempts
+}
+
+// CallbackDelivery is an attempt to POST a PendingTestRun to its NotifyURL.
+type CallbackDelivery struct {
+	// Stage is the stage of the run which was delivered.
+	Stage PendingTestRunStage `json:"stage"`
+	Time  time.Time           `json:"time"`
+	// Error is empty if the attempt succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// PendingTestRunTransition is a change of the stage of a PendingTestRun.
+type PendingTestRunTransition struct {
+	Stage PendingTestRunStage `json:"stage"`
+	Time  time.Time           `json:"time"`
+	// Actor is who changed the stage: the uploader of the run when it was
+	// received, the results processor ("_processor"), or SweeperActor.
+	Actor string `json:"actor,omitempty"`
+	// Error is the error message of the change, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// SweeperActor is the actor of the transitions made by the sweeper of stuck
+// pending runs.
+const SweeperActor = "sweeper"
+
+// RecordTransition records that the run has moved to its current stage.
+func (s *PendingTestRun) RecordTransition(actor, message string, t time.Time) {
+	s.Transitions = append(s.Transitions, PendingTestRunTransition{
+		Stage: s.Stage,
+		Time:  t.UTC().Round(0),
+		Actor: actor,
+		Error: message,
+	})
+}
+
 // Load is part of the datastore.PropertyLoadSaver interface.
 // We use it to reset all time to UTC and trim their monotonic clock.
 func (s *PendingTestRun) Load(ps []datastore.Property) error {
@@ -283,6 +429,9 @@ func (s *PendingTestRun) Load(ps []datastore.Property) error {
 	}
 	s.Created = s.Created.UTC().Round(0)
 	s.Updated = s.Updated.UTC().Round(0)
+	for i := range s.Transitions {
+		s.Transitions[i].Time = s.Transitions[i].Time.UTC().Round(0)
+	}
 	return nil
 }
 
@@ -299,6 +448,104 @@ func (a PendingTestRunByUpdated) Len() int           { return len(a) }
 func (a PendingTestRunByUpdated) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a PendingTestRunByUpdated) Less(i, j int) bool { return a[i].Updated.Before(a[j].Updated) }
 
+// ResultsUploadSession is a resumable upload of a results file that is too
+// large for a single request. The file is uploaded to GCS in chunks, which are
+// assembled when the session is finalized.
+type ResultsUploadSession struct {
+	ID       string `json:"id" datastore:"-"`
+	Uploader string `json:"uploader"`
+	// Params are the URL-encoded extra params of the upload (e.g. labels).
+	Params string `json:"-" datastore:",noindex"`
+	// Chunks are the GCS paths of the chunks uploaded so far, in order.
+	Chunks []string `json:"-" datastore:",noindex"`
+	// Offset is the number of bytes uploaded so far.
+	Offset int64 `json:"offset"`
+	// Gzipped is detected from the first chunk.
+	Gzipped   bool `json:"gzipped"`
+	Finalized bool `json:"finalized"`
+	// Task is the results processing task scheduled when finalized.
+	Task string `json:"task,omitempty"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+// UploaderQuota limits how much an uploader can upload. Zero limits are
+// unlimited.
+type UploaderQuota struct {
+	Uploader    string `json:"uploader" datastore:"-"`
+	RunsPerDay  int    `json:"runs_per_day"`
+	BytesPerDay int64  `json:"bytes_per_day"`
+	// MaxPendingRuns is the maximum number of the uploader's runs which can be
+	// waiting for, or in, processing at the same time.
+	MaxPendingRuns int `json:"max_pending_runs"`
+}
+
+// UploaderUsage is an uploader's usage of its quota on a (UTC) day.
+type UploaderUsage struct {
+	Uploader string `json:"uploader"`
+	// Date is formatted as YYYY-MM-DD.
+	Date  string `json:"date"`
+	Runs  int    `json:"runs"`
+	Bytes int64  `json:"bytes"`
+	// RunIDs are the IDs of the runs counted in Runs, so that a run whose
+	// creation is retried is only counted once.
+	RunIDs []int64 `json:"-" datastore:",noindex"`
+}
+
+// UploaderQuotaReport is an uploader's quota, along with its current usage.
+type UploaderQuotaReport struct {
+	Uploader    string        `json:"uploader"`
+	Quota       UploaderQuota `json:"quota"`
+	Usage       UploaderUsage `json:"usage"`
+	PendingRuns int           `json:"pending_runs"`
+}
+
+// APITokenScope is an action which an APIToken can be used for.
+type APITokenScope string
+
+// The scopes of API tokens.
+const (
+	ScopeUploadResults     APITokenScope = "upload_results"
+	ScopeUpdatePendingRuns APITokenScope = "update_pending_runs"
+	ScopeCreateRuns        APITokenScope = "create_runs"
+	ScopeUploadScreenshots APITokenScope = "upload_screenshots"
+)
+
+// APIToken is a bearer token which authenticates as an uploader, but only for
+// the given scopes, products and until it expires. Only the hash of the token
+// is stored.
+type APIToken struct {
+	ID       int64  `json:"id" datastore:"-"`
+	Hash     string `json:"-"`
+	Uploader string `json:"uploader"`
+	// Scopes are the APITokenScopes the token can be used for.
+	Scopes []string `json:"scopes"`
+	// Products are the browser names the token can upload for; any browser
+	// if empty.
+	Products    []string  `json:"products,omitempty"`
+	Description string    `json:"description,omitempty" datastore:",noindex"`
+	Expires     time.Time `json:"expires"`
+	Revoked     bool      `json:"revoked"`
+	// CreatedBy is the GitHub handle of the admin who created the token.
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+}
+
+// TestRunAuditLog records a change to a TestRun made by an admin.
+type TestRunAuditLog struct {
+	ID    int64 `json:"id" datastore:"-"`
+	RunID int64 `json:"run_id"`
+	// Action is one of "invalidate", "restore" or "labels".
+	Action        string   `json:"action"`
+	AddedLabels   []string `json:"added_labels,omitempty" datastore:",noindex"`
+	RemovedLabels []string `json:"removed_labels,omitempty" datastore:",noindex"`
+	Reason        string   `json:"reason,omitempty" datastore:",noindex"`
+	// GitHubUser is the GitHub handle of the admin.
+	GitHubUser string    `json:"github_user"`
+	Time       time.Time `json:"time"`
+}
+
 // TestHistoryEntry formats Test History data for the datastore.
 type TestHistoryEntry struct {
 	BrowserName string
@@ -329,6 +576,87 @@ type CheckSuite struct {
 	PRNumbers      []int  `json:"pr_numbers"`
 }
 
+// CheckRepo entities configure the wpt.fyi checks of a GitHub repo other than
+// wpt, e.g. a downstream browser fork of wpt; wpt itself has a built-in config.
+type CheckRepo struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	// RepoID is the GitHub ID of the repo, which PRs are matched against.
+	RepoID int64 `json:"repo_id"`
+	// AppID and InstallationID are the GitHub app which creates the checks,
+	// and its installation on the repo.
+	AppID          int64 `json:"app_id"`
+	InstallationID int64 `json:"installation_id"`
+	// Products are the specs of the products which are checked, or empty to
+	// check all of them.
+	Products []string `json:"products,omitempty"`
+	// MasterBranch is the branch whose runs PRs are compared against,
+	// defaulting to master.
+	MasterBranch string `json:"master_branch,omitempty"`
+}
+
+// IsWPT returns whether the repo is the main wpt repo.
+func (r CheckRepo) IsWPT() bool {
+	return r.Owner == WPTRepoOwner && r.Repo == WPTRepoName
+}
+
+// GetMasterBranch returns the branch whose runs PRs are compared against.
+func (r CheckRepo) GetMasterBranch() string {
+	if r.MasterBranch == "" {
+		return "master"
+	}
+
+	return r.MasterBranch
+}
+
+// GetProducts returns the products which are checked, defaulting to the
+// default products.
+func (r CheckRepo) GetProducts() (ProductSpecs, error) {
+	products, err := ParseProductSpecs(r.Products...)
+	if err != nil {
+		return nil, err
+	}
+
+	return products.OrDefault(), nil
+}
+
+// ChecksRun returns whether the run is of one of the products which are
+// checked.
+func (r CheckRepo) ChecksRun(run TestRun) bool {
+	if len(r.Products) == 0 {
+		return true
+	}
+	products, err := ParseProductSpecs(r.Products...)
+	if err != nil {
+		return false
+	}
+	for _, product := range products {
+		if product.Matches(run) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RepoLabel returns the label of the runs of the repo, which is empty for wpt.
+func (r CheckRepo) RepoLabel() string {
+	if r.IsWPT() {
+		return ""
+	}
+
+	return GetRepoLabel(r.Owner, r.Repo)
+}
+
+// MasterLabel returns the label of the runs of the master branch of the repo.
+func (r CheckRepo) MasterLabel() string {
+	if r.IsWPT() {
+		return MasterLabel
+	}
+
+	return RepoMasterLabel
+}
+
 // LabelsSet creates a set from the run's labels.
 func (r TestRun) LabelsSet() mapset.Set {
 	runLabels := mapset.NewSet()
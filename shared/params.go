@@ -625,6 +625,9 @@ func ParseTestRunFilterParams(v url.Values) (filter TestRunFilter, err error) {
 	if filter.View, err = ParseViewParam(v); err != nil {
 		return filter, err
 	}
+	if filter.IncludeInvalid, err = ParseBooleanParam(v, "include_invalid"); err != nil {
+		return filter, err
+	}
 	return filter, nil
 }
 
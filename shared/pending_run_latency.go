@@ -0,0 +1,64 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"sort"
+	"time"
+)
+
+// StageLatency is the aggregate time which pending runs spent in a stage
+// before moving on to the next one.
+type StageLatency struct {
+	Stage PendingTestRunStage `json:"stage"`
+	// Count is the number of runs which left the stage.
+	Count       int     `json:"count"`
+	MeanSeconds float64 `json:"mean_seconds"`
+	P50Seconds  float64 `json:"p50_seconds"`
+	P95Seconds  float64 `json:"p95_seconds"`
+	MaxSeconds  float64 `json:"max_seconds"`
+}
+
+// StageLatencies computes the latency of each stage from the transitions of
+// the given runs, ordered by stage. Only stages which runs have left are
+// included; the time spent in the current stage of a run is not known yet.
+func StageLatencies(runs []PendingTestRun) []StageLatency {
+	durations := make(map[PendingTestRunStage][]time.Duration)
+	for _, run := range runs {
+		for i := 1; i < len(run.Transitions); i++ {
+			prev := run.Transitions[i-1]
+			durations[prev.Stage] = append(durations[prev.Stage], run.Transitions[i].Time.Sub(prev.Time))
+		}
+	}
+
+	latencies := make([]StageLatency, 0, len(durations))
+	for stage, ds := range durations {
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		var total time.Duration
+		for _, d := range ds {
+			total += d
+		}
+		latencies = append(latencies, StageLatency{
+			Stage:       stage,
+			Count:       len(ds),
+			MeanSeconds: (total / time.Duration(len(ds))).Seconds(),
+			P50Seconds:  percentile(ds, 50).Seconds(),
+			P95Seconds:  percentile(ds, 95).Seconds(),
+			MaxSeconds:  ds[len(ds)-1].Seconds(),
+		})
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i].Stage < latencies[j].Stage })
+	return latencies
+}
+
+// percentile returns the p-th percentile of the sorted (non-empty) durations,
+// using the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
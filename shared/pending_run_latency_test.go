@@ -0,0 +1,60 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingTestRun_RecordTransition(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.FixedZone("PDT", -7*60*60))
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := PendingTestRun{Stage: StageCIRunning}
+	run.RecordTransition("azure", "", now)
+	run.Stage = StageInvalid
+	run.RecordTransition(SweeperActor, "oops", now.Add(time.Minute))
+	assert.Equal(t, []PendingTestRunTransition{
+		{Stage: StageCIRunning, Time: now.UTC(), Actor: "azure"},
+		{Stage: StageInvalid, Time: now.Add(time.Minute).UTC(), Actor: SweeperActor, Error: "oops"},
+	}, run.Transitions)
+}
+
+func TestStageLatencies(t *testing.T) {
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	run := func(minutes ...int) PendingTestRun {
+		// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+		var r PendingTestRun
+		stages := []PendingTestRunStage{StageCIRunning, StageWptFyiReceived, StageWptFyiProcessing, StageValid}
+		at := start
+		for i, m := range minutes {
+			at = at.Add(time.Duration(m) * time.Minute)
+			// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+			r.Transitions = append(r.Transitions, PendingTestRunTransition{Stage: stages[i], Time: at})
+		}
+		return r
+	}
+	runs := []PendingTestRun{
+		run(0, 10, 1, 2),
+		run(0, 20, 3, 4),
+		run(0, 30, 5),
+		// Still in CI.
+		run(0),
+	}
+
+	assert.Equal(t, []StageLatency{
+		{Stage: StageCIRunning, Count: 3, MeanSeconds: 1200, P50Seconds: 1200, P95Seconds: 1800, MaxSeconds: 1800},
+		{Stage: StageWptFyiReceived, Count: 3, MeanSeconds: 180, P50Seconds: 180, P95Seconds: 300, MaxSeconds: 300},
+		{Stage: StageWptFyiProcessing, Count: 2, MeanSeconds: 180, P50Seconds: 120, P95Seconds: 240, MaxSeconds: 240},
+	}, StageLatencies(runs))
+}
+
+func TestStageLatencies_empty(t *testing.T) {
+	assert.Empty(t, StageLatencies(nil))
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PendingRunTimeoutKind is the Datastore kind of PendingRunTimeout entities,
+// which are keyed by the name of their stage, e.g. "CI_RUNNING".
+const PendingRunTimeoutKind = "PendingRunTimeout"
+
+// PendingRunTimeout configures how long a PendingTestRun may stay in a
+// (non-terminal) stage before it is considered stuck, and timed out.
+type PendingRunTimeout struct {
+	Stage PendingTestRunStage `json:"stage" datastore:"-"`
+	// Minutes is the timeout of the stage; runs never time out of stages
+	// with no timeout.
+	Minutes int `json:"minutes"`
+	// Reschedules is the number of times the processing of a run stuck in
+	// the stage is re-scheduled before it is timed out. It only applies to
+	// the WPTFYI_RECEIVED and WPTFYI_PROCESSING stages.
+	Reschedules int `json:"reschedules"`
+}
+
+// Timeout returns the timeout of the stage.
+func (t PendingRunTimeout) Timeout() time.Duration {
+	return time.Duration(t.Minutes) * time.Minute
+}
+
+// PendingRunTimeouts are the timeouts of stages.
+type PendingRunTimeouts map[PendingTestRunStage]PendingRunTimeout
+
+// DefaultPendingRunTimeouts returns the timeouts of stages which aren't
+// configured in Datastore.
+func DefaultPendingRunTimeouts() PendingRunTimeouts {
+	timeouts := make(PendingRunTimeouts)
+	for stage, minutes := range map[PendingTestRunStage]int{
+		StageGitHubQueued:     24 * 60,
+		StageGitHubInProgress: 24 * 60,
+		StageCIRunning:        24 * 60,
+		StageCIFinished:       6 * 60,
+		StageGitHubSuccess:    6 * 60,
+		StageGitHubFailure:    6 * 60,
+		StageWptFyiReceived:   3 * 60,
+		StageWptFyiProcessing: 3 * 60,
+	} {
+		reschedules := 0
+		if stage.IsProcessing() {
+			reschedules = 1
+		}
+		timeouts[stage] = PendingRunTimeout{Stage: stage, Minutes: minutes, Reschedules: reschedules}
+	}
+	return timeouts
+}
+
+// LoadPendingRunTimeouts loads the timeouts configured in Datastore, falling
+// back to DefaultPendingRunTimeouts for other stages.
+func LoadPendingRunTimeouts(store Datastore) (PendingRunTimeouts, error) {
+	var configured []PendingRunTimeout
+	keys, err := store.GetAll(store.NewQuery(PendingRunTimeoutKind), &configured)
+	if err != nil {
+		return nil, err
+	}
+	timeouts := DefaultPendingRunTimeouts()
+	for i, key := range keys {
+		var stage PendingTestRunStage
+		if err := stage.UnmarshalJSON([]byte(strconv.Quote(key.StringID()))); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", PendingRunTimeoutKind, key.StringID(), err)
+		}
+		configured[i].Stage = stage
+		timeouts[stage] = configured[i]
+	}
+	return timeouts, nil
+}
+
+// IsStuck returns whether the run has stayed in its stage for longer than the
+// timeout of the stage.
+func (t PendingRunTimeouts) IsStuck(run PendingTestRun, now time.Time) bool {
+	timeout, ok := t[run.Stage]
+	return ok && !run.Stage.IsTerminal() && timeout.Minutes > 0 && now.Sub(run.Updated) > timeout.Timeout()
+}
+
+// CountStuck counts the stuck runs of each stage, by the name of the stage.
+func (t PendingRunTimeouts) CountStuck(runs []PendingTestRun, now time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, run := range runs {
+		if t.IsStuck(run, now) {
+			counts[run.Stage.String()]++
+		}
+	}
+	return counts
+}
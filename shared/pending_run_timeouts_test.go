@@ -0,0 +1,94 @@
+//go:build small
+
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package shared
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingRunTimeouts_IsStuck(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	timeouts := PendingRunTimeouts{
+		StageCIRunning:      {Stage: StageCIRunning, Minutes: 60},
+		StageWptFyiReceived: {Stage: StageWptFyiReceived, Minutes: 0},
+	}
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	for _, c := range []struct {
+		run   PendingTestRun
+		stuck bool
+	}{
+		{PendingTestRun{Stage: StageCIRunning, Updated: now.Add(-2 * time.Hour)}, true},
+		{PendingTestRun{Stage: StageCIRunning, Updated: now.Add(-30 * time.Minute)}, false},
+		// No timeout.
+		{PendingTestRun{Stage: StageWptFyiReceived, Updated: now.Add(-48 * time.Hour)}, false},
+		{PendingTestRun{Stage: StageGitHubQueued, Updated: now.Add(-48 * time.Hour)}, false},
+		// Terminal.
+		{PendingTestRun{Stage: StageValid, Updated: now.Add(-48 * time.Hour)}, false},
+	} {
+		assert.Equal(t, c.stuck, timeouts.IsStuck(c.run, now), c.run.Stage.String())
+	}
+}
+
+func TestPendingRunTimeouts_CountStuck(t *testing.T) {
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	runs := []PendingTestRun{
+		{Stage: StageCIRunning, Updated: now.Add(-48 * time.Hour)},
+		{Stage: StageCIRunning, Updated: now.Add(-25 * time.Hour)},
+		{Stage: StageCIRunning, Updated: now},
+		{Stage: StageWptFyiProcessing, Updated: now.Add(-4 * time.Hour)},
+	}
+	assert.Equal(t,
+		map[string]int{"CI_RUNNING": 2, "WPTFYI_PROCESSING": 1},
+		DefaultPendingRunTimeouts().CountStuck(runs, now))
+}
+
+func TestDefaultPendingRunTimeouts(t *testing.T) {
+	timeouts := DefaultPendingRunTimeouts()
+	for stage, timeout := range timeouts {
+		assert.False(t, stage.IsTerminal())
+		assert.Equal(t, stage, timeout.Stage)
+		assert.Greater(t, timeout.Minutes, 0)
+		if !stage.IsProcessing() {
+			assert.Zero(t, timeout.Reschedules)
+		}
+	}
+}
+
+func TestPendingTestRun_Transition(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := PendingTestRun{Stage: StageWptFyiProcessing}
+	assert.Nil(t, run.Transition(StageValid))
+	assert.NotNil(t, run.Transition(StageWptFyiProcessing))
+	assert.Equal(t, StageValid, run.Stage)
+
+	// Runs can move on from TIMED_OUT, e.g. if they finish late.
+	run.Stage = StageTimedOut
+	assert.Nil(t, run.Transition(StageWptFyiProcessing))
+	assert.Nil(t, run.Transition(StageTimedOut))
+	assert.Nil(t, run.Transition(StageValid))
+	assert.Equal(t, StageValid, run.Stage)
+	assert.NotNil(t, run.Transition(0))
+}
+
+func TestPendingTestRun_Delivered(t *testing.T) {
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run := PendingTestRun{Stage: StageTimedOut}
+	assert.False(t, run.Delivered())
+	// nolint:exhaustruct // TODO: Fix exhaustruct lint error
+	run.Deliveries = []CallbackDelivery{{Stage: StageTimedOut, Error: "timeout"}, {Stage: StageTimedOut}}
+	assert.True(t, run.Delivered())
+	assert.Equal(t, 2, run.DeliveryAttempts())
+
+	// Each terminal stage is delivered, e.g. when a timed out run finishes.
+	assert.Nil(t, run.Transition(StageValid))
+	assert.False(t, run.Delivered())
+	assert.Zero(t, run.DeliveryAttempts())
+}
@@ -59,6 +59,21 @@ func (mr *MockAppEngineAPIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockAppEngineAPI)(nil).Context))
 }
 
+// GetAPIToken mocks base method.
+func (m *MockAppEngineAPI) GetAPIToken(token string) (*shared.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIToken", token)
+	ret0, _ := ret[0].(*shared.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken.
+func (mr *MockAppEngineAPIMockRecorder) GetAPIToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockAppEngineAPI)(nil).GetAPIToken), token)
+}
+
 // GetGitHubClient mocks base method.
 func (m *MockAppEngineAPI) GetGitHubClient() (*github.Client, error) {
 	m.ctrl.T.Helper()
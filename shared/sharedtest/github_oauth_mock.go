@@ -199,3 +199,17 @@ func (mr *MockGitHubAccessControlMockRecorder) IsValidWPTMember() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValidWPTMember", reflect.TypeOf((*MockGitHubAccessControl)(nil).IsValidWPTMember))
 }
+
+// User mocks base method.
+func (m *MockGitHubAccessControl) User() *shared.User {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "User")
+	ret0, _ := ret[0].(*shared.User)
+	return ret0
+}
+
+// User indicates an expected call of User.
+func (mr *MockGitHubAccessControlMockRecorder) User() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "User", reflect.TypeOf((*MockGitHubAccessControl)(nil).User))
+}
@@ -58,6 +58,20 @@ func (mr *MockTestRunQueryMockRecorder) GetAlignedRunSHAs(products, labels, from
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlignedRunSHAs", reflect.TypeOf((*MockTestRunQuery)(nil).GetAlignedRunSHAs), products, labels, from, to, limit, offset)
 }
 
+// IncludeInvalid mocks base method.
+func (m *MockTestRunQuery) IncludeInvalid() shared.TestRunQuery {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncludeInvalid")
+	ret0, _ := ret[0].(shared.TestRunQuery)
+	return ret0
+}
+
+// IncludeInvalid indicates an expected call of IncludeInvalid.
+func (mr *MockTestRunQueryMockRecorder) IncludeInvalid() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncludeInvalid", reflect.TypeOf((*MockTestRunQuery)(nil).IncludeInvalid))
+}
+
 // LoadTestRunKeys mocks base method.
 func (m *MockTestRunQuery) LoadTestRunKeys(products []shared.ProductSpec, labels mapset.Set, revisions []string, from, to *time.Time, limit, offset *int) (shared.KeysByProduct, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +53,8 @@ type TestRunFilter struct {
 	Offset   *int         `json:"offset,omitempty"` // Used for paginating with MaxCount.
 	Products ProductSpecs `json:"products,omitempty"`
 	View     *string      `json:"view,omitempty"`
+	// IncludeInvalid includes runs which were marked invalid.
+	IncludeInvalid *bool `json:"include_invalid,omitempty"`
 }
 
 type testRunFilterNoCustomMarshalling TestRunFilter
@@ -196,6 +198,9 @@ func (filter TestRunFilter) ToQuery() (q url.Values) {
 	if filter.View != nil {
 		q.Set("view", *filter.View)
 	}
+	if filter.IncludeInvalid != nil {
+		q.Set("include_invalid", strconv.FormatBool(*filter.IncludeInvalid))
+	}
 	return q
 }
 
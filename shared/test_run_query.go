@@ -58,15 +58,43 @@ type TestRunQuery interface {
 		to *time.Time,
 		limit *int,
 		offset *int) (shas []string, keys map[string]KeysByProduct, err error)
+
+	// IncludeInvalid returns a TestRunQuery whose queries include invalid
+	// runs, which are otherwise excluded. Runs loaded by key are never
+	// excluded.
+	IncludeInvalid() TestRunQuery
 }
 
 type testRunQueryImpl struct {
-	store Datastore
+	store          Datastore
+	includeInvalid bool
 }
 
 // NewTestRunQuery creates a concrete TestRunQuery backed by a Datastore interface.
 func NewTestRunQuery(store Datastore) TestRunQuery {
-	return testRunQueryImpl{store}
+	return testRunQueryImpl{store: store}
+}
+
+func (t testRunQueryImpl) IncludeInvalid() TestRunQuery {
+	t.includeInvalid = true
+	return t
+}
+
+// loadInvalidRunIDs loads the IDs of all invalid runs, or returns an empty set
+// if invalid runs are included.
+func (t testRunQueryImpl) loadInvalidRunIDs() (mapset.Set, error) {
+	ids := mapset.NewSet()
+	if t.includeInvalid {
+		return ids, nil
+	}
+	keys, err := t.store.GetAll(t.store.NewQuery("TestRun").Filter("Invalid =", true).KeysOnly(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		ids.Add(key.IntID())
+	}
+	return ids, nil
 }
 
 func (t testRunQueryImpl) LoadTestRuns(
@@ -140,6 +168,10 @@ func (t testRunQueryImpl) LoadTestRunKeys(
 		}
 		log.Debugf("Found %d keys across %d revisions", globalIDFilter.Cardinality(), len(revisions))
 	}
+	invalidIDs, err := t.loadInvalidRunIDs()
+	if err != nil {
+		return nil, err
+	}
 
 	for i, product := range products {
 		var productIDFilter = merge(globalIDFilter, nil)
@@ -170,7 +202,7 @@ func (t testRunQueryImpl) LoadTestRunKeys(
 		// turn the query on its head (filter the entities).
 		var keys []Key
 		if productIDFilter != nil {
-			keys, err = clientSideFilter(t.store, product, productIDFilter, from, to, limit)
+			keys, err = clientSideFilter(t.store, product, productIDFilter.Difference(invalidIDs), from, to, limit)
 			if err != nil {
 				return nil, err
 			}
@@ -189,10 +221,27 @@ func (t testRunQueryImpl) LoadTestRunKeys(
 			if limit != nil && *limit < MaxCountMaxValue {
 				max = *limit
 			}
-			keys, err = t.store.GetAll(query.KeysOnly().Limit(max), nil)
+			// Invalid runs mustn't count towards the offset, so it's applied
+			// after excluding them rather than by Datastore.
+			skip := 0
+			if offset != nil && invalidIDs.Cardinality() > 0 {
+				query = query.Offset(0)
+				skip = *offset
+			}
+			// Load enough keys to make up for the invalid runs among them.
+			keys, err = t.store.GetAll(query.KeysOnly().Limit(skip+max+invalidIDs.Cardinality()), nil)
 			if err != nil {
 				return nil, err
 			}
+			keys = excludeKeys(keys, invalidIDs)
+			if len(keys) > skip {
+				keys = keys[skip:]
+			} else {
+				keys = nil
+			}
+			if len(keys) > max {
+				keys = keys[:max]
+			}
 			log.Debugf("Loaded %v results for %s", len(keys), product.String())
 		}
 
@@ -205,6 +254,20 @@ func (t testRunQueryImpl) LoadTestRunKeys(
 	return result, nil
 }
 
+// excludeKeys returns the keys whose IDs aren't in the given set.
+func excludeKeys(keys []Key, ids mapset.Set) []Key {
+	if ids.Cardinality() == 0 {
+		return keys
+	}
+	result := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		if !ids.Contains(key.IntID()) {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
 func clientSideFilter(
 	store Datastore,
 	product ProductSpec,
@@ -294,6 +357,8 @@ func (t testRunQueryImpl) GetAlignedRunSHAs(
 			break
 		} else if err != nil {
 			return nil, nil, err
+		} else if testRun.Invalid && !t.includeInvalid {
+			continue
 		} else {
 			for i := range products {
 				if products[i].Matches(testRun) {
@@ -512,3 +512,45 @@ func TestGetAlignedRunSHAs(t *testing.T) {
 	shas, _, _ = q.GetAlignedRunSHAs(shared.GetDefaultProducts(), nil, &from, nil, nil, nil)
 	assert.Equal(t, []string{"abcdef0123"}, shas)
 }
+
+func TestLoadTestRuns_Invalid(t *testing.T) {
+	ctx, done, err := sharedtest.NewAEContext(true)
+	assert.Nil(t, err)
+	defer done()
+	store := shared.NewAppEngineDatastore(ctx, false)
+
+	var keys []shared.Key
+	for i := 0; i < 3; i++ {
+		testRun := shared.TestRun{}
+		testRun.BrowserName = "chrome"
+		testRun.FullRevisionHash = strings.Repeat(strconv.Itoa(i), 40)
+		testRun.Revision = testRun.FullRevisionHash[:10]
+		testRun.TimeStart = time.Now().AddDate(0, 0, -i)
+		testRun.Invalid = i == 0
+		key, err := store.Put(store.NewIncompleteKey("TestRun"), &testRun)
+		assert.Nil(t, err)
+		keys = append(keys, key)
+	}
+
+	chrome, _ := shared.ParseProductSpec("chrome")
+	one := 1
+	loaded, err := store.TestRunQuery().LoadTestRuns(shared.ProductSpecs{chrome}, nil, nil, nil, nil, &one, nil)
+	assert.Nil(t, err)
+	allRuns := loaded.AllRuns()
+	assert.Len(t, allRuns, 1)
+	assert.Equal(t, keys[1].IntID(), allRuns[0].ID)
+
+	loaded, err = store.TestRunQuery().IncludeInvalid().LoadTestRuns(shared.ProductSpecs{chrome}, nil, nil, nil, nil, &one, nil)
+	assert.Nil(t, err)
+	allRuns = loaded.AllRuns()
+	assert.Len(t, allRuns, 1)
+	assert.Equal(t, keys[0].IntID(), allRuns[0].ID)
+	assert.True(t, allRuns[0].Invalid)
+
+	// Invalid runs don't count towards the offset.
+	loaded, err = store.TestRunQuery().LoadTestRuns(shared.ProductSpecs{chrome}, nil, nil, nil, nil, &one, &one)
+	assert.Nil(t, err)
+	allRuns = loaded.AllRuns()
+	assert.Len(t, allRuns, 1)
+	assert.Equal(t, keys[2].IntID(), allRuns[0].ID)
+}
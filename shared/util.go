@@ -39,10 +39,27 @@ const PRBaseLabel = "pr_base"
 // head of a PR (with the changes).
 const PRHeadLabel = "pr_head"
 
+// PRRetryLabel is the label for re-running just the regressed tests on the
+// head of a PR, as requested by the retry action of its wpt.fyi checks.
+const PRRetryLabel = "pr_retry"
+
+// RepoMasterLabel is the label for runs of the master branch of repos other
+// than wpt, e.g. downstream forks, which (unlike MasterLabel) don't show up
+// as results of wpt.
+const RepoMasterLabel = "repo_master"
+
+// DuplicateLabel is the label for runs which were created for the same
+// product, revision and labels as an existing run.
+const DuplicateLabel = "duplicate"
+
 // UserLabelPrefix is a prefix used to denote a label for a user's GitHub handle,
 // prefixed because usernames are essentially user input.
 const UserLabelPrefix = "user:"
 
+// RepoLabelPrefix is a prefix used to denote a label for the GitHub repo, other
+// than wpt, whose CI produced a run.
+const RepoLabelPrefix = "repo:"
+
 // WPTRepoOwner is the owner (username) for the GitHub wpt repo.
 const WPTRepoOwner = "web-platform-tests"
 
@@ -54,6 +71,12 @@ func GetUserLabel(username string) string {
 	return UserLabelPrefix + username
 }
 
+// GetRepoLabel returns the label of the runs of the given GitHub repo, e.g.
+// repo:owner/name.
+func GetRepoLabel(owner, repo string) string {
+	return RepoLabelPrefix + owner + "/" + repo
+}
+
 // ProductChannelToLabel maps known product-specific channel names
 // to the wpt.fyi model's equivalent.
 func ProductChannelToLabel(channel string) string {
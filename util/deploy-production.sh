@@ -203,7 +203,7 @@ else
 fi
 
 cd webapp/web
-gcloud app deploy ${QUIET:+--quiet} --project=wptdashboard index.yaml queue.yaml dispatch.yaml
+gcloud app deploy ${QUIET:+--quiet} --project=wptdashboard index.yaml queue.yaml dispatch.yaml cron.yaml
 cd ../..
 
 # Stop docker.
@@ -0,0 +1,58 @@
+// Copyright 2026 The WPT Dashboard Project. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// interop_config generates shared/interop.yml from the frontend's
+// webapp/static/interop-data.json. Run it with `go generate ./shared/`.
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/web-platform-tests/wpt.fyi/shared"
+)
+
+const header = `# Copyright 2026 The WPT Dashboard Project. All rights reserved.
+# Use of this source code is governed by a BSD-style license that can be
+# found in the LICENSE file.
+
+# Interop focus-area definitions, by year. This file drives the routing of the
+# /interop-20XX dashboards and is served as-is from /api/interop/config.
+#
+# DO NOT EDIT: it's generated from webapp/static/interop-data.json by
+# ` + "`go generate ./shared/`" + `.
+#
+# Bump ` + "`version`" + ` when making a backwards-incompatible change to the schema.
+`
+
+func main() {
+	if len(os.Args) != 3 {
+		log.Fatalf("Usage: %s interop-data.json interop.yml", os.Args[0])
+	}
+	data, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	config, err := shared.ConvertInteropData(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header)
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		log.Fatal(err)
+	}
+	if err := encoder.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(os.Args[2], out.Bytes(), 0o644); err != nil { // nolint:gosec // The file is checked in.
+		log.Fatal(err)
+	}
+}
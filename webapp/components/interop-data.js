@@ -1,6 +1,8 @@
 // This file should match the data in webapp/static/interop-data.json.
 // The JSON file is used by some Mozilla infrastructure, so the file should
 // not be deleted and should match the data in this file.
+// The server's interop config (shared/interop.yml) is generated from the JSON
+// file with `go generate ./shared/`, so regenerate it after any change.
 export const interopData = {
   'valid_years': ['2021', '2022', '2023', '2024', '2025', '2026'],
   'valid_mobile_years': ['2024', '2025', '2026'],
@@ -45,6 +45,7 @@ Object.defineProperty(wpt, 'ClientSideFeatures', {
 Object.defineProperty(wpt, 'ServerSideFeatures', {
   get: function() {
     return [
+      'checksMentionOwners',
       'failChecksOnRegression',
       'ignoreHarnessInTotal',
     ];
@@ -220,6 +221,11 @@ class WPTEnvironmentFlagsEditor extends FlagsEditorClass(/*environmentFlags*/ tr
         Set the wpt.fyi GitHub status check to action_required if regressions are found.
       </paper-checkbox>
     </paper-item>
+    <paper-item>
+      <paper-checkbox id="checksMentionOwners" checked="[[checksMentionOwners]]" on-change="handleChange">
+        @-mention the suggested reviewers (from META.yml) of regressed tests in the wpt.fyi GitHub status check.
+      </paper-checkbox>
+    </paper-item>
 `;
   }
 
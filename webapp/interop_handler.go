@@ -17,23 +17,24 @@ type interopData struct {
 	Year     string
 }
 
-// Set of years that are valid for Interop 20XX.
-var validYears = map[string]bool{"2021": true, "2022": true, "2023": true, "2024": true, "2025": true, "2026": true}
-var validMobileYears = map[string]bool{"2024": true, "2025": true, "2026": true}
-
-// Year that any invalid year will redirect to.
-// TODO(danielrsmith): Change this redirect for next year's interop page.
-const defaultRedirectYear = "2026"
-
 // interopHandler handles GET requests to /interop-20XX and /compat20XX
 func interopHandler(w http.ResponseWriter, r *http.Request) {
 	name := mux.Vars(r)["name"]
 	year := mux.Vars(r)["year"]
 
+	config, err := shared.GetInteropConfig()
+	if err != nil {
+		shared.GetLogger(r.Context()).Errorf("Failed to load interop config: %s", err.Error())
+		http.Error(w, "Failed to load interop config", http.StatusInternalServerError)
+
+		return
+	}
+
 	// /compat20XX redirects to /interop-20XX
 	needsRedirect := name == "compat"
-	if _, ok := validYears[year]; !ok {
-		year = defaultRedirectYear
+	if !config.IsValidYear(year) {
+		// Any invalid year redirects to the configured default year.
+		year = config.DefaultYear
 		needsRedirect = true
 	}
 
@@ -43,9 +44,8 @@ func interopHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	_, isValidMobileYear := validMobileYears[year]
-	if isMobileView != nil && !isValidMobileYear {
-		year = defaultRedirectYear
+	if isMobileView != nil && !config.IsValidMobileYear(year) {
+		year = config.DefaultYear
 		needsRedirect = true
 	}
 
@@ -98,10 +98,10 @@ func TestInteropHandler_compatRedirect(t *testing.T) {
 
 func TestInteropHandler_success(t *testing.T) {
 	// A typical "/interop-20XX" path with a valid year should not redirect.
-	req := httptest.NewRequest("GET", "/interop-"+defaultRedirectYear, strings.NewReader("{}"))
+	req := httptest.NewRequest("GET", "/interop-2026", strings.NewReader("{}"))
 	req = mux.SetURLVars(req, map[string]string{
 		"name": "interop",
-		"year": defaultRedirectYear,
+		"year": "2026",
 	})
 
 	w := httptest.NewRecorder()
@@ -113,10 +113,10 @@ func TestInteropHandler_success(t *testing.T) {
 func TestInteropHandler_mobileSuccess(t *testing.T) {
 	// A typical "/interop-20XX" path with a valid mobile year should not redirect.
 	req := httptest.NewRequest(
-		"GET", "/interop-"+defaultRedirectYear+"?mobile-view", strings.NewReader("{}"))
+		"GET", "/interop-2026?mobile-view", strings.NewReader("{}"))
 	req = mux.SetURLVars(req, map[string]string{
 		"name":       "interop",
-		"year":       defaultRedirectYear,
+		"year":       "2026",
 		"mobileView": "true",
 	})
 
@@ -6,13 +6,18 @@ import (
 	"github.com/samthor/nicehttp"
 
 	"github.com/web-platform-tests/wpt.fyi/api"
+	"github.com/web-platform-tests/wpt.fyi/api/alerts"
 	"github.com/web-platform-tests/wpt.fyi/api/azure"
 	"github.com/web-platform-tests/wpt.fyi/api/checks"
+	"github.com/web-platform-tests/wpt.fyi/api/ci"
+	"github.com/web-platform-tests/wpt.fyi/api/generic"
 	"github.com/web-platform-tests/wpt.fyi/api/ghactions"
+	"github.com/web-platform-tests/wpt.fyi/api/gitlab"
 	"github.com/web-platform-tests/wpt.fyi/api/query"
 	"github.com/web-platform-tests/wpt.fyi/api/receiver"
 	"github.com/web-platform-tests/wpt.fyi/api/screenshot"
 	"github.com/web-platform-tests/wpt.fyi/api/taskcluster"
+	"github.com/web-platform-tests/wpt.fyi/api/webhooks"
 	"github.com/web-platform-tests/wpt.fyi/shared"
 	"github.com/web-platform-tests/wpt.fyi/webapp"
 )
@@ -27,11 +32,16 @@ func init() {
 	checks.RegisterRoutes()
 
 	// The rest of /api/:
+	alerts.RegisterRoutes()
 	api.RegisterRoutes()
+	ci.RegisterRoutes()
+	generic.RegisterRoutes()
+	gitlab.RegisterRoutes()
 	query.RegisterRoutes()
 	receiver.RegisterRoutes()
 	screenshot.RegisterRoutes()
 	taskcluster.RegisterRoutes()
+	webhooks.RegisterRoutes()
 
 	// The actual Web App:
 
@@ -62,10 +62,20 @@ func TestApiRunBound(t *testing.T) {
 	assertHandlerIs(t, "/api/runs/123", "api-test-run")
 }
 
+func TestApiRunUpdateBound(t *testing.T) {
+	assertHandlerIs(t, "/api/runs/123/invalidate", "api-test-run-update")
+	assertHandlerIs(t, "/api/runs/123/restore", "api-test-run-update")
+	assertHandlerIs(t, "/api/runs/123/labels", "api-test-run-update")
+	assertHandlerIs(t, "/api/runs/123/audit", "api-test-run-audit")
+}
+
 func TestApiStatusBound(t *testing.T) {
 	assertHandlerIs(t, "/api/status", "api-pending-test-runs")
 	assertHandlerIs(t, "/api/status/pending", "api-pending-test-runs")
 	assertHandlerIs(t, "/api/status/invalid", "api-pending-test-runs")
+	assertHandlerIs(t, "/api/status/timed_out", "api-pending-test-runs")
+	assertHandlerIs(t, "/api/status/latency", "api-pending-test-run-latency")
+	assertHandlerIs(t, "/api/status/sweep", "api-pending-test-runs-sweep")
 	assertHandlerIs(t, "/api/status/123", "api-pending-test-run-update")
 	assertHandlerIsDefault(t, "/api/status/notavalidfilter")
 }
@@ -88,6 +98,40 @@ func TestApiResultsUploadBoundHSTS(t *testing.T) {
 	assertNoCORS(t, "/api/results/upload")
 }
 
+func TestApiResultsUploadSessionsBound(t *testing.T) {
+	assertHandlerIs(t, "/api/results/upload/sessions", "api-results-upload-sessions")
+	assertHandlerIs(t, "/api/results/upload/sessions/abc-123", "api-results-upload-session")
+	assertHandlerIs(t, "/api/results/upload/sessions/abc-123/finalize", "api-results-upload-session-finalize")
+	assertNoCORS(t, "/api/results/upload/sessions")
+}
+
+func TestApiResultsMergeBound(t *testing.T) {
+	assertHandlerIs(t, "/api/results/merge", "api-results-merge")
+	assertNoCORS(t, "/api/results/merge")
+}
+
+func TestApiResultsNotifyBound(t *testing.T) {
+	assertHandlerIs(t, "/api/results/notify", "api-results-notify")
+	assertNoCORS(t, "/api/results/notify")
+}
+
+func TestApiResultsQuotasBound(t *testing.T) {
+	assertHandlerIs(t, "/api/results/quotas", "api-results-quotas")
+}
+
+func TestApiResultsTokensBound(t *testing.T) {
+	assertHandlerIs(t, "/api/results/tokens", "api-results-tokens")
+	assertHandlerIs(t, "/api/results/tokens/123", "api-results-token")
+}
+
+func TestApiTaskclusterTasksClassifyBound(t *testing.T) {
+	assertHandlerIs(t, "/api/taskcluster/tasks/classify", "api-taskcluster-tasks-classify")
+}
+
+func TestApiCINotificationsBound(t *testing.T) {
+	assertHandlerIs(t, "/api/ci/notifications", "api-ci-notifications")
+}
+
 func TestApiResultsCreateBoundHSTS(t *testing.T) {
 	assertHandlerIs(t, "/api/results/create", "api-results-create")
 	assertHSTS(t, "/api/results/create")
@@ -164,6 +208,25 @@ func TestApiBSFBound(t *testing.T) {
 	assertHSTS(t, "/api/bsf")
 }
 
+func TestApiInteropConfigBound(t *testing.T) {
+	assertHandlerIs(t, "/api/interop/config", "api-interop-config")
+	assertHSTS(t, "/api/interop/config")
+}
+
+func TestApiAlertsBound(t *testing.T) {
+	assertHandlerIs(t, "/api/alerts/subscriptions", "api-alerts-subscriptions")
+	assertHandlerIs(t, "/api/alerts/subscriptions/123", "api-alerts-subscription")
+	assertHandlerIs(t, "/api/alerts/evaluate", "api-alerts-evaluate")
+}
+
+func TestApiWebhooksDeliverBound(t *testing.T) {
+	assertHandlerIs(t, "/api/webhooks/deliver", "api-webhooks-deliver")
+}
+
+func TestApiPRBound(t *testing.T) {
+	assertHandlerIs(t, "/api/pr/123", "api-pr")
+}
+
 func TestApiPendingMetadataBound(t *testing.T) {
 	assertHandlerIs(t, "/api/metadata/pending", "api-pending-metadata")
 	assertHSTS(t, "/api/metadata/pending")